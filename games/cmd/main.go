@@ -1,14 +1,20 @@
 package main
 
 import (
-	//"context"
+	"context"
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humago"
 	padapters "github.com/rpatton4/mesbg-league/games/internal/primary"
+	"github.com/rpatton4/mesbg-league/games/internal/roundscenarios"
 	sadapters "github.com/rpatton4/mesbg-league/games/internal/secondary"
+	participantsgateway "github.com/rpatton4/mesbg-league/participants/pkg/gateway"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	roundsgateway "github.com/rpatton4/mesbg-league/rounds/pkg/gateway"
+	scenariosgateway "github.com/rpatton4/mesbg-league/scenarios/pkg/gateway"
 	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 )
 
 var port = "8081"
@@ -18,18 +24,63 @@ func main() {
 	slog.SetDefault(slog.New(logHandler))
 
 	slog.Info("Starting the Games service on port " + port)
-	repo := sadapters.NewMemoryRepository()
-	ctrl := padapters.NewTxnController(repo)
+	repo, err := sadapters.NewDefaultRepository()
+	if err != nil {
+		slog.Error("Failed to set up the games repository", "error", err.Error())
+		panic(err)
+	}
+
+	// Events are sent to the webhooks of the services reacting to games, GAMES_EVENT_WEBHOOKS is a comma separated
+	// list of their URLs
+	bus := events.NewWebhookBus(nil)
+	for _, url := range strings.Split(os.Getenv("GAMES_EVENT_WEBHOOKS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			bus.AddWebhook(url)
+		}
+	}
+
+	// The events for game changes are recorded in the repository's outbox, the relay delivers them from there and
+	// keeps those it gives up on as dead letters, which administrators can replay through /events/dead-letters/replay
+	relay := padapters.NewRelay(repo, bus, events.NewMemoryDeadLetterStore(), padapters.DefaultRelayConfig)
+	go relay.Run(context.Background())
+
+	// Results are checked against the victory points of the scenario played in each game's round
+	roundsAddr := os.Getenv("ROUNDS_SERVICE_ADDR")
+	if roundsAddr == "" {
+		roundsAddr = "http://localhost:8085"
+	}
+	scenariosAddr := os.Getenv("SCENARIOS_SERVICE_ADDR")
+	if scenariosAddr == "" {
+		scenariosAddr = "http://localhost:8086"
+	}
+	limits := roundscenarios.New(roundsgateway.New(roundsAddr), scenariosgateway.New(scenariosAddr))
+
+	// Results are only taken for games played with army lists fitting the points limit of the game's round
+	participantsAddr := os.Getenv("PARTICIPANTS_SERVICE_ADDR")
+	if participantsAddr == "" {
+		participantsAddr = "http://localhost:8083"
+	}
+	lists := sadapters.NewParticipantArmyLists(participantsgateway.New(participantsAddr))
+
+	ctrl := padapters.NewTxnController(repo, limits, lists)
 	handler := padapters.NewHumaHandler(ctrl)
 
 	router := http.NewServeMux()
 
 	api := humago.New(router, huma.DefaultConfig("Games Service", "1.0.0"))
 
-	huma.Get(api, "/games/{id}", handler.GetByID)
-	huma.Post(api, "/games", handler.Post)
-	huma.Put(api, "/games/{id}", handler.Put)
-	huma.Delete(api, "/games/{id}", handler.Delete)
+	padapters.RegisterRoutes(api, handler)
+
+	// Purging games, overriding their lifecycle, resolving disputes and looking after the events are kept for the
+	// administrators, GAMES_ADMINS is a comma separated list of the actors who are
+	admins := []string{}
+	for _, admin := range strings.Split(os.Getenv("GAMES_ADMINS"), ",") {
+		if admin = strings.TrimSpace(admin); admin != "" {
+			admins = append(admins, admin)
+		}
+	}
+	padapters.RegisterAdminRoutes(api, handler, admins)
+	padapters.RegisterEventRoutes(api, padapters.NewEventsHandler(relay), admins)
 
 	if err := http.ListenAndServe(":"+port, router); err != nil {
 		slog.Error("Failed to start HTTP server", "error", err.Error())
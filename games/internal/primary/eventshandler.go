@@ -0,0 +1,83 @@
+package primary
+
+import (
+	"context"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	"log/slog"
+)
+
+// EventsHandler defines the HTTP handler (adapter) for looking after the events published by the Games service,
+// which are relayed from the outbox of the repository and kept as dead letters when they cannot be
+type EventsHandler struct {
+	relay *Relay
+}
+
+// OutboxStatusResponse defines the output for the OutboxStatus operation.
+type OutboxStatusResponse struct {
+	Body *RelayStatus
+}
+
+// DeadLettersResponse defines the output for the DeadLetters operation.
+type DeadLettersResponse struct {
+	Body struct {
+		// DeadLetters holds every event the relay gave up on, oldest first
+		DeadLetters []events.DeadLetter `json:"deadLetters" doc:"The events which could not be delivered, oldest first"`
+	}
+}
+
+// ReplayResponse defines the output for the Replay operation.
+type ReplayResponse struct {
+	Body struct {
+		// Replayed is the number of dead letters delivered by the replay
+		Replayed int `json:"replayed" example:"3" doc:"The number of dead letters delivered by the replay"`
+
+		// Remaining is the number of dead letters which still could not be delivered
+		Remaining int `json:"remaining" example:"0" doc:"The number of dead letters which still could not be delivered"`
+	}
+}
+
+// NewEventsHandler creates a new instance of the HTTP handler for the events relayed by the given relay.
+func NewEventsHandler(relay *Relay) *EventsHandler {
+	return &EventsHandler{relay: relay}
+}
+
+// OutboxStatus reports how far behind the outbox relay is, and which entries are stuck
+func (h *EventsHandler) OutboxStatus(ctx context.Context, _ *struct{}) (*OutboxStatusResponse, error) {
+	s, err := h.relay.Status(ctx)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("error while reading the outbox: " + err.Error())
+	}
+	return &OutboxStatusResponse{Body: s}, nil
+}
+
+// DeadLetters lists the events the relay gave up on after StuckAfter attempts
+func (h *EventsHandler) DeadLetters(ctx context.Context, _ *struct{}) (*DeadLettersResponse, error) {
+	dls, err := h.relay.DeadLetters().List(ctx)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("error while reading the dead letters: " + err.Error())
+	}
+
+	res := &DeadLettersResponse{}
+	res.Body.DeadLetters = dls
+	return res, nil
+}
+
+// Replay delivers every dead letter again, those which fail again are kept for a later replay
+func (h *EventsHandler) Replay(ctx context.Context, _ *struct{}) (*ReplayResponse, error) {
+	n, err := h.relay.Replay(ctx)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("error while replaying the dead letters: " + err.Error())
+	}
+
+	dls, err := h.relay.DeadLetters().List(ctx)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("error while reading the dead letters: " + err.Error())
+	}
+
+	slog.Info("Replayed dead letters", "replayed", n, "remaining", len(dls))
+	res := &ReplayResponse{}
+	res.Body.Replayed = n
+	res.Body.Remaining = len(dls)
+	return res, nil
+}
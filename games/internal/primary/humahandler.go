@@ -6,8 +6,15 @@ import (
 	"github.com/danielgtaylor/huma/v2"
 	games "github.com/rpatton4/mesbg-league/games/pkg"
 	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
 	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
 	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // HumaHandler defines the HTTP handler (adapter) for Games operations received via HTTP(S).
@@ -33,6 +40,9 @@ type GetByIDRequest struct {
 
 // GetByIDResponse defines the output for the GetByID operation.
 type GetByIDResponse struct {
+	// ETag holds the version of the game, to be sent back in If-Match when the game is updated or deleted
+	ETag string `header:"ETag" doc:"The version of the game, send it back in If-Match to update or delete the game"`
+
 	// Body holds the game with the requested ID, Huma will marshall this to JSON for the HTTP response
 	Body model.Game
 }
@@ -44,6 +54,9 @@ type PostRequest struct {
 }
 
 type PostResponse struct {
+	// ETag holds the version of the new game
+	ETag string `header:"ETag" doc:"The version of the game, send it back in If-Match to update or delete the game"`
+
 	// Body holds the newly created game, including its assigned ID, Huma will marshall this to JSON for the HTTP response
 	Body model.Game
 }
@@ -53,11 +66,17 @@ type PutRequest struct {
 	// that the path is set up in the form /games/{id} in the Huma API definition
 	ID games.GameID `path:"id" example:"1234" doc:"The unique identifier for the game to update"`
 
+	// IfMatch is the ETag of the game the update is based on, the update is rejected if the game has changed since
+	IfMatch string `header:"If-Match" doc:"The ETag of the game the update is based on, required. The update is rejected with 412 if the game has changed since"`
+
 	// Body holds the Game model to be used to update the game with the ID from the path
 	Body *model.Game
 }
 
 type PutResponse struct {
+	// ETag holds the new version of the game
+	ETag string `header:"ETag" doc:"The new version of the game"`
+
 	// Body holds the updated game, Huma will marshall this to JSON for the HTTP response
 	Body model.Game
 }
@@ -66,6 +85,134 @@ type DeleteRequest struct {
 	// ID is the unique identifier for the game to delete, and it will be taken from the path with the assumption
 	// that the path is set up in the form /games/{id} in the Huma API definition
 	ID games.GameID `path:"id" example:"1234" doc:"The unique identifier for the game to delete"`
+
+	// IfMatch is the ETag of the game the delete is based on, the delete is rejected if the game has changed since
+	IfMatch string `header:"If-Match" doc:"The ETag of the game being deleted, required. The delete is rejected with 412 if the game has changed since"`
+}
+
+// ListRequest defines the input for the List operation, all of the filters are optional and are taken from the query
+type ListRequest struct {
+	// RoundID limits the results to games in the given round
+	RoundID string `query:"roundId" example:"9876" doc:"Only return games which are part of this round"`
+
+	// SideID limits the results to games where the given player is on either side
+	SideID string `query:"sideId" example:"5678" doc:"Only return games where this player is on either side"`
+
+	// Status limits the results to games in any of the given states, can be repeated or comma separated
+	Status []int `query:"status" example:"[2]" doc:"Only return games in any of these states, see the GameState values"`
+
+	// Cursor is the value of nextCursor from a previous page, used to continue the listing
+	Cursor string `query:"cursor" doc:"The nextCursor value from the previous page, omit to start at the first page"`
+
+	// Limit is the maximum number of games to return in the page
+	Limit int `query:"limit" default:"50" minimum:"1" maximum:"200" doc:"The maximum number of games to return in one page"`
+
+	// IncludeDeleted adds deleted games which have not been purged yet to the results
+	IncludeDeleted bool `query:"includeDeleted" doc:"Also return deleted games which have not been purged yet"`
+}
+
+// ListResponse defines the output for the List operation.
+type ListResponse struct {
+	// Body holds the page of games and the cursor for the next page, Huma will marshall this to JSON for the HTTP response
+	Body model.GameList
+}
+
+// ActionRequest defines the input for the lifecycle actions which need nothing beyond the game ID, such as start
+type ActionRequest struct {
+	// ID is the unique identifier for the game to act on, and it will be taken from the path with the assumption
+	// that the path is set up in the form /games/{id}/<action> in the Huma API definition
+	ID games.GameID `path:"id" example:"1234" doc:"The unique identifier for the game to act on"`
+}
+
+// ConcedeRequest defines the input for the Concede operation.
+type ConcedeRequest struct {
+	// ID is the unique identifier for the game being conceded, taken from the path
+	ID games.GameID `path:"id" example:"1234" doc:"The unique identifier for the game being conceded"`
+
+	// Body identifies which side is conceding
+	Body struct {
+		ConcedingSideID players.PlayerID `json:"concedingSideId" example:"5678" doc:"The unique identifier of the side which is conceding"`
+	}
+}
+
+// OverrideRequest defines the input for the administrative Override operation.
+type OverrideRequest struct {
+	// ID is the unique identifier for the game to override, taken from the path
+	ID games.GameID `path:"id" example:"1234" doc:"The unique identifier for the game whose state is being overridden"`
+
+	// Body holds the state to force the game into, and why
+	Body struct {
+		Status games.GameState `json:"status" example:"1" doc:"The state to put the game into, regardless of the normal lifecycle"`
+		Reason string          `json:"reason" minLength:"1" example:"Result entered against the wrong game" doc:"Why the lifecycle is being overridden, this is logged"`
+	}
+}
+
+// PurgeRequest defines the input for the administrative Purge operation.
+type PurgeRequest struct {
+	// OlderThan is how long ago a game must have been deleted to be purged, as a Go duration such as 720h
+	OlderThan string `query:"olderThan" default:"720h" example:"168h" doc:"How long ago a game must have been deleted to be purged, as a duration such as 720h"`
+}
+
+// PurgeResponse defines the output for the Purge operation.
+type PurgeResponse struct {
+	// Body holds how many games were purged
+	Body struct {
+		Purged int `json:"purged" example:"3" doc:"The number of deleted games removed for good"`
+	}
+}
+
+// ReportsResponse defines the output for the Reports operation.
+type ReportsResponse struct {
+	// ETag holds the version of the game the reports were read from
+	ETag string `header:"ETag" doc:"The version of the game"`
+
+	// Body holds the reports of the game along with where they have left it
+	Body struct {
+		Status     games.GameState      `json:"status" example:"6" doc:"The current state of the game"`
+		Reports    []model.ResultReport `json:"reports" doc:"The result reported by each side so far"`
+		Resolution *model.Resolution    `json:"resolution,omitempty" doc:"How an organizer settled the game, if its reports did not agree"`
+	}
+}
+
+// SubmitReportRequest defines the input for the SubmitReport operation.
+type SubmitReportRequest struct {
+	// ID is the unique identifier for the game being reported, taken from the path
+	ID games.GameID `path:"id" example:"1234" doc:"The unique identifier for the game being reported"`
+
+	// SideID is the side whose report this is, taken from the path
+	SideID players.PlayerID `path:"sideId" example:"5678" doc:"The unique identifier of the side submitting the report"`
+
+	// Body holds the result as the side saw it
+	Body *model.GameResult
+}
+
+// ResolveRequest defines the input for the organizer's Resolve operation.
+type ResolveRequest struct {
+	// ID is the unique identifier for the disputed game, taken from the path
+	ID games.GameID `path:"id" example:"1234" doc:"The unique identifier for the disputed game"`
+
+	// Body holds the result decided by the organizer and why, the organizer is the actor making the request
+	Body struct {
+		Reason string           `json:"reason" minLength:"1" example:"Both players agreed the objective was held at the end" doc:"Why the organizer settled on the result"`
+		Result model.GameResult `json:"result" doc:"The result the game is completed with"`
+	}
+}
+
+// HistoryResponse defines the output for the History operation.
+type HistoryResponse struct {
+	// Body holds the changes made to the game, oldest first
+	Body struct {
+		Entries []model.HistoryEntry `json:"entries" doc:"Every change made to the game, oldest first"`
+	}
+}
+
+// ActionResponse defines the output for all of the lifecycle actions.
+type ActionResponse struct {
+	// ETag holds the new version of the game
+	ETag string `header:"ETag" doc:"The new version of the game"`
+
+	// Body holds the game after the action, Huma will marshall this to JSON for the HTTP response
+	Body model.Game
 }
 
 //</editor-fold>
@@ -78,6 +225,7 @@ func NewHumaHandler(c SingleController) *HumaHandler {
 // GetByID queries the controller for the game with the ID taken from the path, returns it if found
 // 404 is returned if no such game exists
 // 400 is returned if the game ID is invalid
+// 500 is returned if the game cannot be retrieved for any other reason
 func (h *HumaHandler) GetByID(ctx context.Context, req *GetByIDRequest) (*GetByIDResponse, error) {
 	slog.Debug("GetByID called", "gameID", req.ID)
 
@@ -93,7 +241,13 @@ func (h *HumaHandler) GetByID(ctx context.Context, req *GetByIDRequest) (*GetByI
 		return nil, huma.Error400BadRequest("Invalid game ID")
 	}
 
+	if err != nil {
+		slog.Error("Unable to retrieve the game", "gameID", req.ID, "error", err)
+		return nil, huma.Error500InternalServerError("Error while retrieving the game: " + err.Error())
+	}
+
 	return &GetByIDResponse{
+		ETag: etag(g.Version),
 		Body: *g,
 	}, nil
 }
@@ -116,15 +270,26 @@ func (h *HumaHandler) Post(ctx context.Context, req *PostRequest) (*PostResponse
 	}
 	slog.Debug("Created game", "game", g)
 	return &PostResponse{
+		ETag: etag(g.Version),
 		Body: *g,
 	}, nil
 }
 
 // Put reads the game JSON from the HTTP call and sends it on to the controller to fully update the game
-// with the given ID from the path.
+// with the given ID from the path. The version the update is based on is taken from the If-Match header.
+// 409 is returned if the game's lifecycle does not allow the change of status
+// 412 is returned if the game has changed since the version in If-Match
+// 428 is returned if there is no If-Match header
 // 500 is returned if the game cannot be created for any reason
 func (h *HumaHandler) Put(ctx context.Context, req *PutRequest) (*PutResponse, error) {
 	slog.Debug("Put called", "PutRequest Body", req.Body)
+	version, err := versionFromIfMatch(req.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		req.Body.Version = version
+	}
 	g, err := h.ctrl.Replace(ctx, req.Body)
 
 	if err != nil {
@@ -137,28 +302,281 @@ func (h *HumaHandler) Put(ctx context.Context, req *PutRequest) (*PutResponse, e
 			return nil, huma.Error400BadRequest("client sent invalid game when requesting game update: " + err.Error())
 		} else if errors.Is(err, svcerrors.ErrNotFound) {
 			return nil, huma.Error400BadRequest("client sent a game with an ID which can't be found, '" + string(req.Body.ID) + "', when requesting game update: " + err.Error())
+		} else if errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+			return nil, huma.Error409Conflict("client sent a game status which the game cannot move to: " + err.Error())
+		} else if errors.Is(err, svcerrors.ErrVersionConflict) {
+			return nil, huma.Error412PreconditionFailed("the game has changed since it was read, fetch it again before updating: " + err.Error())
 		} else {
 			return nil, huma.Error500InternalServerError("error while updating the game: " + err.Error())
 		}
 	}
 	slog.Debug("Updated game", "game", g)
 	return &PutResponse{
+		ETag: etag(g.Version),
 		Body: *g,
 	}, nil
 }
 
-// Delete deletes the game with the given ID from the path.
+// Delete deletes the game with the given ID from the path, as long as it has not changed since the version in the
+// If-Match header.
+// 412 is returned if the game has changed since the version in If-Match
+// 428 is returned if there is no If-Match header
 func (h *HumaHandler) Delete(ctx context.Context, req *DeleteRequest) (*struct{}, error) {
 	slog.Debug("Delete called", "gameID", req.ID)
 
-	_, err := h.ctrl.DeleteByID(ctx, req.ID)
+	version, err := versionFromIfMatch(req.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	_, err = h.ctrl.DeleteByID(ctx, req.ID, version)
 
 	if err != nil {
 		slog.Error("Controller error for game", "gameID", req.ID, "error", err)
 		if errors.Is(err, svcerrors.ErrNotFound) || errors.Is(err, svcerrors.ErrInvalidID) {
 			return nil, huma.Error404NotFound("No such game exists")
+		} else if errors.Is(err, svcerrors.ErrVersionConflict) {
+			return nil, huma.Error412PreconditionFailed("the game has changed since it was read: " + err.Error())
 		}
 		return nil, huma.Error500InternalServerError("Error while deleting the game: " + err.Error())
 	}
 	return nil, nil
 }
+
+// Restore brings back the deleted game with the ID from the path, as it was before it was deleted
+// 404 is returned if there is no deleted game with the ID, including once it has been purged
+func (h *HumaHandler) Restore(ctx context.Context, req *ActionRequest) (*ActionResponse, error) {
+	slog.Debug("Restore called", "gameID", req.ID)
+	g, err := h.ctrl.Restore(ctx, req.ID)
+	return actionResponse("restore", req.ID, g, err)
+}
+
+// Purge removes the games deleted longer ago than the age in the query string for good. This is meant for league
+// administrators, once purged a game cannot be restored.
+// 400 is returned if the age is not a valid duration
+func (h *HumaHandler) Purge(ctx context.Context, req *PurgeRequest) (*PurgeResponse, error) {
+	slog.Debug("Purge called", "olderThan", req.OlderThan)
+
+	olderThan, err := time.ParseDuration(req.OlderThan)
+	if err != nil {
+		return nil, huma.Error400BadRequest("the olderThan query parameter is not a duration: " + req.OlderThan)
+	}
+
+	n, err := h.ctrl.Purge(ctx, olderThan)
+	if err != nil {
+		slog.Error("Unable to purge deleted games", "func", "Purge", "error", err)
+		if errors.Is(err, svcerrors.ErrInvalidQuery) {
+			return nil, huma.Error400BadRequest("client sent an invalid age when purging games: " + err.Error())
+		}
+		return nil, huma.Error500InternalServerError("error while purging games: " + err.Error())
+	}
+
+	res := &PurgeResponse{}
+	res.Body.Purged = n
+	return res, nil
+}
+
+// List queries the controller for a page of games matching the filters from the query string
+// 400 is returned if the filters or cursor are invalid
+func (h *HumaHandler) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	slog.Debug("List called", "roundID", req.RoundID, "sideID", req.SideID, "status", req.Status, "cursor", req.Cursor)
+
+	q := model.GameQuery{
+		RoundID:        rounds.RoundID(req.RoundID),
+		SideID:         players.PlayerID(req.SideID),
+		IncludeDeleted: req.IncludeDeleted,
+		Cursor:         req.Cursor,
+		Limit:          req.Limit,
+	}
+	for _, s := range req.Status {
+		q.Statuses = append(q.Statuses, games.GameState(s))
+	}
+
+	l, err := h.ctrl.List(ctx, q)
+	if err != nil {
+		slog.Error("Unable to list games", "func", "List", "error", err)
+		if errors.Is(err, svcerrors.ErrInvalidQuery) {
+			return nil, huma.Error400BadRequest("client sent an invalid query when listing games: " + err.Error())
+		}
+		return nil, huma.Error500InternalServerError("error while listing games: " + err.Error())
+	}
+
+	return &ListResponse{
+		Body: *l,
+	}, nil
+}
+
+// Start moves the game with the ID from the path into play
+// 404 is returned if no such game exists
+// 409 is returned if the game's lifecycle does not allow it to be started
+func (h *HumaHandler) Start(ctx context.Context, req *ActionRequest) (*ActionResponse, error) {
+	slog.Debug("Start called", "gameID", req.ID)
+	g, err := h.ctrl.Start(ctx, req.ID)
+	return actionResponse("start", req.ID, g, err)
+}
+
+// Complete marks the play of the game with the ID from the path as finished, leaving it waiting for each side to
+// report the result
+// 404 is returned if no such game exists
+// 409 is returned if the game's lifecycle does not allow it to be completed
+func (h *HumaHandler) Complete(ctx context.Context, req *ActionRequest) (*ActionResponse, error) {
+	slog.Debug("Complete called", "gameID", req.ID)
+	g, err := h.ctrl.Complete(ctx, req.ID)
+	return actionResponse("complete", req.ID, g, err)
+}
+
+// Concede marks the game with the ID from the path as conceded by the side given in the body
+// 400 is returned if the conceding side is not in the game
+// 404 is returned if no such game exists
+// 409 is returned if the game's lifecycle does not allow it to be conceded
+func (h *HumaHandler) Concede(ctx context.Context, req *ConcedeRequest) (*ActionResponse, error) {
+	slog.Debug("Concede called", "gameID", req.ID, "concedingSideID", req.Body.ConcedingSideID)
+	g, err := h.ctrl.Concede(ctx, req.ID, req.Body.ConcedingSideID)
+	return actionResponse("concede", req.ID, g, err)
+}
+
+// Cancel marks the game with the ID from the path as cancelled
+// 404 is returned if no such game exists
+// 409 is returned if the game's lifecycle does not allow it to be cancelled
+func (h *HumaHandler) Cancel(ctx context.Context, req *ActionRequest) (*ActionResponse, error) {
+	slog.Debug("Cancel called", "gameID", req.ID)
+	g, err := h.ctrl.Cancel(ctx, req.ID)
+	return actionResponse("cancel", req.ID, g, err)
+}
+
+// Override forces the game with the ID from the path into the state from the body, ignoring the usual lifecycle.
+// This is meant for league administrators correcting mistakes, and the reason given is logged.
+// 400 is returned if the state is unknown or no reason is given
+// 404 is returned if no such game exists
+func (h *HumaHandler) Override(ctx context.Context, req *OverrideRequest) (*ActionResponse, error) {
+	slog.Debug("Override called", "gameID", req.ID, "status", req.Body.Status, "reason", req.Body.Reason)
+	g, err := h.ctrl.OverrideState(ctx, req.ID, req.Body.Status, req.Body.Reason)
+	return actionResponse("override", req.ID, g, err)
+}
+
+// Reports returns the result reports of the game with the ID from the path, along with its state and any resolution
+// 404 is returned if no such game exists
+func (h *HumaHandler) Reports(ctx context.Context, req *GetByIDRequest) (*ReportsResponse, error) {
+	slog.Debug("Reports called", "gameID", req.ID)
+
+	g, err := h.ctrl.GetByID(ctx, req.ID)
+	if err != nil {
+		slog.Error("Unable to read the reports of the game", "gameID", req.ID, "error", err)
+		if errors.Is(err, svcerrors.ErrNotFound) || errors.Is(err, svcerrors.ErrInvalidID) {
+			return nil, huma.Error404NotFound("No such game exists")
+		}
+		return nil, huma.Error500InternalServerError("error while reading the reports of the game: " + err.Error())
+	}
+
+	res := &ReportsResponse{ETag: etag(g.Version)}
+	res.Body.Status = g.Status
+	res.Body.Reports = g.Reports
+	res.Body.Resolution = g.Resolution
+	if res.Body.Reports == nil {
+		res.Body.Reports = []model.ResultReport{}
+	}
+	return res, nil
+}
+
+// SubmitReport records the result from the body as reported by the side from the path. The game is completed once
+// both sides have reported the same result, and disputed if they report different results. Only the player of the
+// side, the actor from the auth.ActorHeader of the request, can report for it.
+// 400 is returned if the result is missing or the side is not in the game
+// 403 is returned if the actor is not the player of the side
+// 404 is returned if no such game exists
+// 409 is returned if the game no longer takes reports
+func (h *HumaHandler) SubmitReport(ctx context.Context, req *SubmitReportRequest) (*ActionResponse, error) {
+	slog.Debug("SubmitReport called", "gameID", req.ID, "sideID", req.SideID, "actor", auth.Actor(ctx), "result", req.Body)
+	if actor := auth.Actor(ctx); actor == "" || players.PlayerID(actor) != req.SideID {
+		return nil, huma.Error403Forbidden("only the player of side '" + string(req.SideID) + "' can report its result, identified by the " + auth.ActorHeader + " header")
+	}
+	var r *model.ResultReport
+	if req.Body != nil {
+		r = &model.ResultReport{ReporterID: req.SideID, Result: *req.Body}
+	}
+	g, err := h.ctrl.SubmitReport(ctx, req.ID, r)
+	return actionResponse("report", req.ID, g, err)
+}
+
+// Resolve completes the disputed game with the ID from the path with the result decided by an organizer, recording
+// who resolved it and why. The organizer is the actor from the auth.ActorHeader of the request, RegisterAdminRoutes
+// only lets administrators through.
+// 400 is returned if the organizer or reason is missing
+// 404 is returned if no such game exists
+// 409 is returned if the game is not disputed
+func (h *HumaHandler) Resolve(ctx context.Context, req *ResolveRequest) (*ActionResponse, error) {
+	organizer := auth.Actor(ctx)
+	slog.Debug("Resolve called", "gameID", req.ID, "resolvedBy", organizer, "reason", req.Body.Reason)
+	if organizer == "" {
+		return nil, huma.Error400BadRequest("the " + auth.ActorHeader + " header naming the organizer resolving the dispute is required")
+	}
+
+	res := &model.Resolution{ResolvedBy: players.PlayerID(organizer), Reason: req.Body.Reason}
+	g, err := h.ctrl.ResolveDispute(ctx, req.ID, res, &req.Body.Result)
+	return actionResponse("resolve", req.ID, g, err)
+}
+
+// History returns the timeline of changes made to the game with the ID from the path, each with who made it, why,
+// and the previous and new values of the fields it changed
+// 404 is returned if there is no history for the game
+func (h *HumaHandler) History(ctx context.Context, req *GetByIDRequest) (*HistoryResponse, error) {
+	slog.Debug("History called", "gameID", req.ID)
+
+	entries, err := h.ctrl.History(ctx, req.ID)
+	if err != nil {
+		slog.Error("Unable to read the history of the game", "gameID", req.ID, "error", err)
+		if errors.Is(err, svcerrors.ErrNotFound) || errors.Is(err, svcerrors.ErrInvalidID) {
+			return nil, huma.Error404NotFound("No history exists for the game")
+		}
+		return nil, huma.Error500InternalServerError("error while reading the history of the game: " + err.Error())
+	}
+
+	res := &HistoryResponse{}
+	res.Body.Entries = entries
+	return res, nil
+}
+
+// actionResponse maps the outcome of a lifecycle action from the controller to the HTTP response, so that every
+// action reports errors with the same status codes
+func actionResponse(action string, id games.GameID, g *model.Game, err error) (*ActionResponse, error) {
+	if err != nil {
+		slog.Error("Unable to "+action+" the game", "gameID", id, "error", err)
+		if errors.Is(err, svcerrors.ErrNotFound) {
+			return nil, huma.Error404NotFound("No such game exists")
+		} else if errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+			return nil, huma.Error409Conflict("the game cannot " + action + " from its current state: " + err.Error())
+		} else if errors.Is(err, svcerrors.ErrVersionConflict) {
+			return nil, huma.Error409Conflict("the game was changed by someone else while trying to " + action + " it, try again: " + err.Error())
+		} else if errors.Is(err, svcerrors.ErrInvalidID) || errors.Is(err, svcerrors.ErrModelMissing) || errors.Is(err, svcerrors.ErrModelInvalid) {
+			return nil, huma.Error400BadRequest("client sent an invalid request to " + action + " the game: " + err.Error())
+		}
+		return nil, huma.Error500InternalServerError("error while trying to " + action + " the game: " + err.Error())
+	}
+
+	slog.Debug("Game "+action+" succeeded", "game", g)
+	return &ActionResponse{
+		ETag: etag(g.Version),
+		Body: *g,
+	}, nil
+}
+
+// etag formats the version of a game as the value of an ETag header
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// versionFromIfMatch reads the version of the game from an If-Match header holding an ETag from etag. The header
+// is required, though "*" can be sent to update or delete whatever the version, which gives version zero.
+func versionFromIfMatch(ifMatch string) (int64, error) {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" {
+		return 0, huma.NewError(http.StatusPreconditionRequired, "the If-Match header is required, send the ETag from when the game was read")
+	} else if ifMatch == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`), 10, 64)
+	if err != nil || version < 1 {
+		return 0, huma.Error400BadRequest("the If-Match header does not hold an ETag for a game: " + ifMatch)
+	}
+	return version, nil
+}
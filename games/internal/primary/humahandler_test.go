@@ -8,11 +8,14 @@ import (
 	mock_primary "github.com/rpatton4/mesbg-league/games/internal/primary/mocks"
 	games "github.com/rpatton4/mesbg-league/games/pkg"
 	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
 	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
 	players "github.com/rpatton4/mesbg-league/players/pkg"
 	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
 	"go.uber.org/mock/gomock"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestHumaHandlerMockedPut(t *testing.T) {
@@ -66,7 +69,7 @@ func TestHumaHandlerMockedPut(t *testing.T) {
 	mockController.EXPECT().Replace(gomock.Any(), &updatedGameMissingID).Return(nil, fmt.Errorf("the game data sent with update is missing a game ID. Source: %w", svcerrors.ErrInvalidID)) // trying to update with a missing ID
 
 	// Test for valid game update
-	res, err := handler.Put(context.Background(), &PutRequest{Body: &validGame})
+	res, err := handler.Put(context.Background(), &PutRequest{IfMatch: "*", Body: &validGame})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -75,7 +78,7 @@ func TestHumaHandlerMockedPut(t *testing.T) {
 	}
 
 	// Test for invalid game when trying to update
-	_, err = handler.Put(context.Background(), &PutRequest{Body: &invalidGame})
+	_, err = handler.Put(context.Background(), &PutRequest{IfMatch: "*", Body: &invalidGame})
 	if err == nil {
 		t.Fatalf("expected error for invalid game, got nil")
 	} else if errors.As(err, &statusError) && statusError.GetStatus() != 400 {
@@ -83,7 +86,7 @@ func TestHumaHandlerMockedPut(t *testing.T) {
 	}
 
 	// Test for game which can't be found when trying to update
-	_, err = handler.Put(context.Background(), &PutRequest{Body: &notFoundGame})
+	_, err = handler.Put(context.Background(), &PutRequest{IfMatch: "*", Body: &notFoundGame})
 	if err == nil {
 		t.Fatalf("expected error for unfound game, got nil")
 	} else if errors.As(err, &statusError) && statusError.GetStatus() != 400 {
@@ -91,7 +94,7 @@ func TestHumaHandlerMockedPut(t *testing.T) {
 	}
 
 	// Test for missing game when trying to update
-	_, err = handler.Put(context.Background(), &PutRequest{Body: nil})
+	_, err = handler.Put(context.Background(), &PutRequest{IfMatch: "*", Body: nil})
 	if err == nil {
 		t.Fatalf("expected error for empty game, got nil")
 	} else if errors.As(err, &statusError) && statusError.GetStatus() != 400 {
@@ -99,7 +102,7 @@ func TestHumaHandlerMockedPut(t *testing.T) {
 	}
 
 	// Test for missing game when trying to update
-	_, err = handler.Put(context.Background(), &PutRequest{Body: &updatedGameMissingID})
+	_, err = handler.Put(context.Background(), &PutRequest{IfMatch: "*", Body: &updatedGameMissingID})
 	if err == nil {
 		t.Fatalf("expected error for game without ID, got nil")
 	} else if errors.As(err, &statusError) && statusError.GetStatus() != 400 {
@@ -171,17 +174,17 @@ func TestHumaHandlerMockedDelete(t *testing.T) {
 	var statusError huma.StatusError
 
 	// Prepare the mock
-	mockController.EXPECT().DeleteByID(gomock.Any(), games.GameID("1")).Return(true, nil).Times(1)
-	mockController.EXPECT().DeleteByID(gomock.Any(), games.GameID("999")).Return(false, svcerrors.ErrNotFound).Times(1) // not found
+	mockController.EXPECT().DeleteByID(gomock.Any(), games.GameID("1"), int64(4)).Return(true, nil).Times(1)
+	mockController.EXPECT().DeleteByID(gomock.Any(), games.GameID("999"), int64(0)).Return(false, svcerrors.ErrNotFound).Times(1) // not found
 
 	// Test for valid game deletion
-	_, err := handler.Delete(context.Background(), &DeleteRequest{ID: games.GameID("1")})
+	_, err := handler.Delete(context.Background(), &DeleteRequest{ID: games.GameID("1"), IfMatch: `"4"`})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
 	// Test for game which can't be found when trying to delete
-	_, err = handler.Delete(context.Background(), &DeleteRequest{ID: games.GameID("999")})
+	_, err = handler.Delete(context.Background(), &DeleteRequest{ID: games.GameID("999"), IfMatch: "*"})
 	if err == nil {
 		t.Fatalf("expected error for unfound game, got nil")
 	}
@@ -204,9 +207,10 @@ func TestHumaHandlerMockedGetByID(t *testing.T) {
 	}
 
 	// Prepare the mock
-	mockController.EXPECT().GetByID(gomock.Any(), games.GameID("1")).Return(&game1, nil).Times(1)                  // valid, exists
-	mockController.EXPECT().GetByID(gomock.Any(), games.GameID("999")).Return(nil, svcerrors.ErrNotFound).Times(1) // valid, not found
-	mockController.EXPECT().GetByID(gomock.Any(), games.GameID("")).Return(nil, svcerrors.ErrInvalidID).Times(1)   // invalid
+	mockController.EXPECT().GetByID(gomock.Any(), games.GameID("1")).Return(&game1, nil).Times(1)                     // valid, exists
+	mockController.EXPECT().GetByID(gomock.Any(), games.GameID("999")).Return(nil, svcerrors.ErrNotFound).Times(1)    // valid, not found
+	mockController.EXPECT().GetByID(gomock.Any(), games.GameID("")).Return(nil, svcerrors.ErrInvalidID).Times(1)      // invalid
+	mockController.EXPECT().GetByID(gomock.Any(), games.GameID("5")).Return(nil, errors.New("disk on fire")).Times(1) // failure
 
 	// Test valid ID
 	res, err := handler.GetByID(context.Background(), &GetByIDRequest{ID: games.GameID("1")})
@@ -235,4 +239,255 @@ func TestHumaHandlerMockedGetByID(t *testing.T) {
 	} else if errors.As(err, &statusError) && statusError.GetStatus() != 400 {
 		t.Fatalf("expected 400 for empty game ID, got %v", err)
 	}
+
+	// Test a failure other than a missing or invalid ID
+	_, err = handler.GetByID(context.Background(), &GetByIDRequest{ID: games.GameID("5")})
+	if !errors.As(err, &statusError) || statusError.GetStatus() != 500 {
+		t.Fatalf("expected 500 when the game cannot be retrieved, got %v", err)
+	}
+}
+
+func TestHumaHandlerMockedList(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+	var statusError huma.StatusError
+
+	game1 := model.Game{
+		ID:      games.GameID("1"),
+		Side1ID: players.PlayerID("8"),
+		Side2ID: players.PlayerID("9"),
+		RoundID: rounds.RoundID("7"),
+		Status:  games.GameStatePlayCompleted,
+	}
+
+	validQuery := model.GameQuery{
+		RoundID:  rounds.RoundID("7"),
+		SideID:   players.PlayerID("8"),
+		Statuses: []games.GameState{games.GameStatePlayCompleted},
+		Limit:    10,
+	}
+
+	// Prepare the mock
+	mockController.EXPECT().List(gomock.Any(), validQuery).Return(&model.GameList{Games: []model.Game{game1}, NextCursor: "abc"}, nil).Times(1)
+	mockController.EXPECT().List(gomock.Any(), model.GameQuery{Cursor: "bad", Limit: 10}).Return(nil, fmt.Errorf("bad cursor. Source: %w", svcerrors.ErrInvalidQuery)).Times(1)
+
+	// Test valid query, the filters should be passed through to the controller
+	res, err := handler.List(context.Background(), &ListRequest{RoundID: "7", SideID: "8", Status: []int{int(games.GameStatePlayCompleted)}, Limit: 10})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(res.Body.Games) != 1 || res.Body.Games[0].ID != game1.ID {
+		t.Fatalf("expected game ID '%s' in the results, got %v", game1.ID, res.Body.Games)
+	}
+	if res.Body.NextCursor != "abc" {
+		t.Fatalf("expected the next cursor to be passed through, got '%s'", res.Body.NextCursor)
+	}
+
+	// Test an invalid cursor
+	_, err = handler.List(context.Background(), &ListRequest{Cursor: "bad", Limit: 10})
+	if err == nil {
+		t.Fatalf("expected error for invalid cursor, got nil")
+	} else if !errors.As(err, &statusError) || statusError.GetStatus() != 400 {
+		t.Fatalf("expected 400 for invalid cursor, got %v", err)
+	}
+}
+
+func TestHumaHandlerMockedLifecycleActions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+	var statusError huma.StatusError
+
+	startedGame := model.Game{
+		ID:      games.GameID("1"),
+		Side1ID: players.PlayerID("8"),
+		Side2ID: players.PlayerID("9"),
+		Status:  games.GameStateInProgress,
+	}
+
+	// Prepare the mock
+	mockController.EXPECT().Start(gomock.Any(), games.GameID("1")).Return(&startedGame, nil).Times(1)
+	mockController.EXPECT().Start(gomock.Any(), games.GameID("2")).Return(nil, fmt.Errorf("game '2' cannot move from PlayCompleted to InProgress. Source: %w", svcerrors.ErrIllegalStateTransition)).Times(1)
+	mockController.EXPECT().Cancel(gomock.Any(), games.GameID("999")).Return(nil, svcerrors.ErrNotFound).Times(1)
+	mockController.EXPECT().Concede(gomock.Any(), games.GameID("1"), players.PlayerID("x")).Return(nil, fmt.Errorf("not a side. Source: %w", svcerrors.ErrModelInvalid)).Times(1)
+	mockController.EXPECT().Replace(gomock.Any(), &startedGame).Return(nil, fmt.Errorf("no reopening. Source: %w", svcerrors.ErrIllegalStateTransition)).Times(1)
+
+	// Test a valid action
+	res, err := handler.Start(context.Background(), &ActionRequest{ID: games.GameID("1")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Body.Status != games.GameStateInProgress {
+		t.Fatalf("expected game to be in progress, got %s", res.Body.Status)
+	}
+
+	// Test an action the lifecycle does not allow
+	_, err = handler.Start(context.Background(), &ActionRequest{ID: games.GameID("2")})
+	if !errors.As(err, &statusError) || statusError.GetStatus() != 409 {
+		t.Fatalf("expected 409 for illegal transition, got %v", err)
+	}
+
+	// Test an action on a game which can't be found
+	_, err = handler.Cancel(context.Background(), &ActionRequest{ID: games.GameID("999")})
+	if !errors.As(err, &statusError) || statusError.GetStatus() != 404 {
+		t.Fatalf("expected 404 for unfound game, got %v", err)
+	}
+
+	// Test conceding as someone who is not in the game
+	req := &ConcedeRequest{ID: games.GameID("1")}
+	req.Body.ConcedingSideID = players.PlayerID("x")
+	_, err = handler.Concede(context.Background(), req)
+	if !errors.As(err, &statusError) || statusError.GetStatus() != 400 {
+		t.Fatalf("expected 400 for invalid conceding side, got %v", err)
+	}
+
+	// Test a full update which breaks the lifecycle
+	_, err = handler.Put(context.Background(), &PutRequest{ID: startedGame.ID, IfMatch: "*", Body: &startedGame})
+	if !errors.As(err, &statusError) || statusError.GetStatus() != 409 {
+		t.Fatalf("expected 409 for illegal transition on update, got %v", err)
+	}
+}
+
+func TestHumaHandlerMockedVersions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	stored := model.Game{ID: "1", Side1ID: "8", Side2ID: "9", Status: games.GameStateInProgress, Version: 3}
+	mockController.EXPECT().GetByID(gomock.Any(), games.GameID("1")).Return(&stored, nil).Times(1)
+	mockController.EXPECT().Replace(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, g *model.Game) (*model.Game, error) {
+		if g.Version != 3 {
+			return nil, fmt.Errorf("game '1' is at version 3, not %d. Source: %w", g.Version, svcerrors.ErrVersionConflict)
+		}
+		updated := *g
+		updated.Version = 4
+		return &updated, nil
+	}).Times(2)
+	mockController.EXPECT().DeleteByID(gomock.Any(), games.GameID("1"), int64(3)).Return(false, svcerrors.ErrVersionConflict).Times(1)
+
+	res, err := handler.GetByID(context.Background(), &GetByIDRequest{ID: "1"})
+	if err != nil || res.ETag != `"3"` {
+		t.Fatalf("expected the version as the ETag, got %v, %v", res, err)
+	}
+
+	// The version comes from If-Match whatever the body says
+	put, err := handler.Put(context.Background(), &PutRequest{ID: "1", IfMatch: res.ETag, Body: &model.Game{ID: "1", Side1ID: "8", Side2ID: "9", Status: games.GameStateInProgress, Version: 1}})
+	if err != nil || put.ETag != `"4"` {
+		t.Fatalf("expected the update to succeed with the new version as the ETag, got %v, %v", put, err)
+	}
+	_, err = handler.Put(context.Background(), &PutRequest{ID: "1", IfMatch: `"2"`, Body: &model.Game{ID: "1", Side1ID: "8", Side2ID: "9", Status: games.GameStateInProgress}})
+	assertStatus(t, err, http.StatusPreconditionFailed)
+	_, err = handler.Delete(context.Background(), &DeleteRequest{ID: "1", IfMatch: `W/"3"`})
+	assertStatus(t, err, http.StatusPreconditionFailed)
+
+	// If-Match is required, and has to hold a version
+	_, err = handler.Put(context.Background(), &PutRequest{ID: "1", Body: &stored})
+	assertStatus(t, err, http.StatusPreconditionRequired)
+	_, err = handler.Delete(context.Background(), &DeleteRequest{ID: "1"})
+	assertStatus(t, err, http.StatusPreconditionRequired)
+	_, err = handler.Delete(context.Background(), &DeleteRequest{ID: "1", IfMatch: `"yesterday"`})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestHumaHandlerMockedReports(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	result := model.GameResult{Side1TotalVictoryPoints: 12, Side2TotalVictoryPoints: 4}
+	awaiting := model.Game{ID: "1", Side1ID: "8", Side2ID: "9", Status: games.GameStateAwaitingConfirmation, Version: 2,
+		Reports: []model.ResultReport{{ReporterID: "8", Result: result}}}
+	resolution := &model.Resolution{ResolvedBy: "4", Reason: "photo of the table"}
+	mockController.EXPECT().GetByID(gomock.Any(), games.GameID("1")).Return(&awaiting, nil).Times(1)
+	mockController.EXPECT().SubmitReport(gomock.Any(), games.GameID("1"), &model.ResultReport{ReporterID: "8", Result: result}).Return(&awaiting, nil).Times(1)
+	mockController.EXPECT().SubmitReport(gomock.Any(), games.GameID("1"), &model.ResultReport{ReporterID: "x", Result: result}).Return(nil, fmt.Errorf("not a side. Source: %w", svcerrors.ErrModelInvalid)).Times(1)
+	mockController.EXPECT().SubmitReport(gomock.Any(), games.GameID("1"), nil).Return(nil, svcerrors.ErrModelMissing).Times(1)
+	mockController.EXPECT().ResolveDispute(gomock.Any(), games.GameID("1"), resolution, &result).Return(nil, fmt.Errorf("not disputed. Source: %w", svcerrors.ErrIllegalStateTransition)).Times(1)
+
+	reports, err := handler.Reports(context.Background(), &GetByIDRequest{ID: "1"})
+	if err != nil || reports.Body.Status != games.GameStateAwaitingConfirmation || len(reports.Body.Reports) != 1 || reports.ETag != `"2"` {
+		t.Fatalf("expected the reports of the game, got %v, %v", reports, err)
+	}
+
+	asSide := func(side string) context.Context {
+		return auth.WithActor(context.Background(), side)
+	}
+	res, err := handler.SubmitReport(asSide("8"), &SubmitReportRequest{ID: "1", SideID: "8", Body: &result})
+	if err != nil || res.Body.Status != games.GameStateAwaitingConfirmation {
+		t.Fatalf("expected the report to be accepted, got %v, %v", res, err)
+	}
+	_, err = handler.SubmitReport(asSide("x"), &SubmitReportRequest{ID: "1", SideID: "x", Body: &result})
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = handler.SubmitReport(asSide("8"), &SubmitReportRequest{ID: "1", SideID: "8"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// Nobody can report for a side other than their own, so one player cannot file and confirm both reports
+	_, err = handler.SubmitReport(asSide("9"), &SubmitReportRequest{ID: "1", SideID: "8", Body: &result})
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = handler.SubmitReport(context.Background(), &SubmitReportRequest{ID: "1", SideID: "8", Body: &result})
+	assertStatus(t, err, http.StatusForbidden)
+
+	req := &ResolveRequest{ID: "1"}
+	req.Body.Reason = resolution.Reason
+	req.Body.Result = result
+	_, err = handler.Resolve(context.Background(), req)
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = handler.Resolve(auth.WithActor(context.Background(), string(resolution.ResolvedBy)), req)
+	assertStatus(t, err, http.StatusConflict)
+}
+
+func TestHumaHandlerMockedHistory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	entries := []model.HistoryEntry{
+		{GameID: "1", Version: 1, Action: model.HistoryActionCreated},
+		{GameID: "1", Version: 2, Action: model.HistoryActionUpdated, Actor: "8", Reason: "typo", Changes: []model.FieldChange{{Field: "side1TotalVictoryPoints", From: []byte("10"), To: []byte("12")}}},
+	}
+	mockController.EXPECT().History(gomock.Any(), games.GameID("1")).Return(entries, nil).Times(1)
+	mockController.EXPECT().History(gomock.Any(), games.GameID("999")).Return(nil, svcerrors.ErrNotFound).Times(1)
+
+	res, err := handler.History(context.Background(), &GetByIDRequest{ID: "1"})
+	if err != nil || len(res.Body.Entries) != 2 || res.Body.Entries[1].Reason != "typo" {
+		t.Fatalf("expected the history of the game, got %v, %v", res, err)
+	}
+	_, err = handler.History(context.Background(), &GetByIDRequest{ID: "999"})
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestHumaHandlerMockedRestoreAndPurge(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	mockController.EXPECT().Restore(gomock.Any(), games.GameID("1")).Return(&model.Game{ID: "1", Side1ID: "8", Side2ID: "9", Version: 3}, nil).Times(1)
+	mockController.EXPECT().Restore(gomock.Any(), games.GameID("999")).Return(nil, svcerrors.ErrNotFound).Times(1)
+	mockController.EXPECT().Purge(gomock.Any(), 168*time.Hour).Return(2, nil).Times(1)
+
+	res, err := handler.Restore(context.Background(), &ActionRequest{ID: "1"})
+	if err != nil || res.ETag != `"3"` {
+		t.Fatalf("expected the restored game, got %v, %v", res, err)
+	}
+	_, err = handler.Restore(context.Background(), &ActionRequest{ID: "999"})
+	assertStatus(t, err, http.StatusNotFound)
+
+	purged, err := handler.Purge(context.Background(), &PurgeRequest{OlderThan: "168h"})
+	if err != nil || purged.Body.Purged != 2 {
+		t.Fatalf("expected 2 games to be purged, got %v, %v", purged, err)
+	}
+	_, err = handler.Purge(context.Background(), &PurgeRequest{OlderThan: "a week"})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+// assertStatus checks that the error from a handler is a Huma error with the given HTTP status
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var statusError huma.StatusError
+	if err == nil {
+		t.Errorf("expected an error with status %d, got nil", status)
+	} else if !errors.As(err, &statusError) || statusError.GetStatus() != status {
+		t.Errorf("expected an error with status %d, got %v", status, err)
+	}
 }
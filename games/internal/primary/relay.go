@@ -0,0 +1,242 @@
+package primary
+
+import (
+	"context"
+	"github.com/rpatton4/mesbg-league/games/internal/secondary"
+	"github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// RelayConfig holds the settings of a Relay
+type RelayConfig struct {
+	// Interval is how often the outbox is checked for entries to relay
+	Interval time.Duration
+
+	// Backoff is the wait before retrying an entry which could not be relayed, doubling with each failed attempt up
+	// to MaxBackoff
+	Backoff time.Duration
+
+	// MaxBackoff is the longest wait between attempts at relaying an entry
+	MaxBackoff time.Duration
+
+	// StuckAfter is the number of failed attempts after which an entry is given up on and moved to the dead-letter
+	// store, so it no longer holds back the later entries of its game
+	StuckAfter int
+}
+
+// relayTarget is the target the dead letters of the relay are recorded against, the subscribers are behind it
+const relayTarget = "games-outbox-relay"
+
+// DefaultRelayConfig is used for the settings which are not given when creating a Relay
+var DefaultRelayConfig = RelayConfig{
+	Interval:   250 * time.Millisecond,
+	Backoff:    500 * time.Millisecond,
+	MaxBackoff: time.Minute,
+	StuckAfter: 5,
+}
+
+// RelayStatus describes how far behind the relay is
+type RelayStatus struct {
+	// Pending is the number of outbox entries waiting to be relayed
+	Pending int `json:"pending" example:"2" doc:"The number of outbox entries waiting to be relayed"`
+
+	// LagSeconds is the age of the oldest pending entry, zero when the outbox is empty
+	LagSeconds float64 `json:"lagSeconds" example:"0.4" doc:"The age in seconds of the oldest pending entry, zero when the outbox is empty"`
+
+	// Relayed is the number of entries relayed since the service started
+	Relayed int64 `json:"relayed" example:"120" doc:"The number of entries relayed since the service started"`
+
+	// LastRelayedAt is when an entry was last relayed
+	LastRelayedAt time.Time `json:"lastRelayedAt,omitzero" doc:"When an entry was last relayed"`
+
+	// Retrying holds the entries which have failed and are waiting to be tried again, oldest first
+	Retrying []secondary.OutboxEntry `json:"retrying" doc:"The entries which have failed to be relayed and are waiting to be tried again, oldest first"`
+
+	// DeadLetters is the number of entries given up on after StuckAfter attempts, waiting to be replayed
+	DeadLetters int `json:"deadLetters" example:"0" doc:"The number of entries given up on, waiting in the dead-letter store to be replayed"`
+}
+
+// Relay drains the outbox of the games repository, delivering each entry to the subscribers. The entries of one
+// game are delivered in the order they were recorded: an entry which cannot be delivered holds back the later
+// entries of its game, but not those of other games, until it has failed StuckAfter times. It is then moved to the
+// dead-letter store to be replayed, letting the rest of its game through. Delivery is at-least-once, an entry is only
+// removed from the outbox after it has been delivered or dead-lettered.
+type Relay struct {
+	outbox      secondary.Outbox
+	target      events.Deliverer
+	deadLetters events.DeadLetterStore
+	cfg         RelayConfig
+
+	mu            sync.Mutex
+	relayed       int64
+	lastRelayedAt time.Time
+}
+
+// NewRelay creates a relay delivering the entries of the outbox to the target, keeping those it gives up on in the
+// dead-letter store, or an in-memory one if it is nil. Any settings missing from cfg are taken from
+// DefaultRelayConfig.
+func NewRelay(outbox secondary.Outbox, target events.Deliverer, deadLetters events.DeadLetterStore, cfg RelayConfig) *Relay {
+	if deadLetters == nil {
+		deadLetters = events.NewMemoryDeadLetterStore()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultRelayConfig.Interval
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultRelayConfig.Backoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultRelayConfig.MaxBackoff
+	}
+	if cfg.StuckAfter <= 0 {
+		cfg.StuckAfter = DefaultRelayConfig.StuckAfter
+	}
+	return &Relay{outbox: outbox, target: target, deadLetters: deadLetters, cfg: cfg}
+}
+
+// Run drains the outbox every Interval until the context ends, it is meant to be started in its own goroutine
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Drain(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Unable to drain the games outbox", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain makes one pass over the outbox, delivering every entry which is due, and returns the number delivered.
+// Only failing to read or update the outbox is returned as an error, an entry failing to be delivered is recorded
+// against the entry and retried on a later pass, or dead-lettered once it has failed StuckAfter times.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	entries, err := r.outbox.PendingOutbox(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	held := map[pkg.GameID]bool{}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		if held[e.GameID] || !r.due(e, time.Now()) {
+			// Later entries of the same game must wait for this one
+			held[e.GameID] = true
+			continue
+		}
+
+		if err := r.target.Deliver(ctx, e.Envelope); err != nil {
+			slog.Warn("Unable to relay game event", "seq", e.Seq, "gameID", e.GameID, "type", e.Envelope.Type, "attempts", e.Attempts+1, "error", err)
+			if e.Attempts+1 >= r.cfg.StuckAfter {
+				if err := r.deadLetter(ctx, e, err); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			held[e.GameID] = true
+			if err := r.outbox.MarkFailed(ctx, e.Seq, err.Error(), time.Now().UTC()); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if err := r.outbox.MarkRelayed(ctx, e.Seq); err != nil {
+			return delivered, err
+		}
+		delivered++
+		r.mu.Lock()
+		r.relayed++
+		r.lastRelayedAt = time.Now().UTC()
+		r.mu.Unlock()
+	}
+	return delivered, nil
+}
+
+// Status reports how far behind the relay is, along with the entries which appear to be stuck
+func (r *Relay) Status(ctx context.Context) (*RelayStatus, error) {
+	entries, err := r.outbox.PendingOutbox(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dls, err := r.deadLetters.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	s := &RelayStatus{Pending: len(entries), Relayed: r.relayed, LastRelayedAt: r.lastRelayedAt, Retrying: []secondary.OutboxEntry{}, DeadLetters: len(dls)}
+	r.mu.Unlock()
+
+	if len(entries) > 0 {
+		s.LagSeconds = time.Since(entries[0].Envelope.OccurredAt).Seconds()
+	}
+	for _, e := range entries {
+		if e.Attempts > 0 {
+			s.Retrying = append(s.Retrying, e)
+		}
+	}
+	return s, nil
+}
+
+// DeadLetters returns the store holding the entries the relay gave up on
+func (r *Relay) DeadLetters() events.DeadLetterStore {
+	return r.deadLetters
+}
+
+// Replay delivers every dead letter again, removing those delivered this time, and returns the number replayed
+// successfully. The entries of a game are replayed in the order they were recorded, though after any later entries of
+// the game which were relayed while they were dead letters.
+func (r *Relay) Replay(ctx context.Context) (int, error) {
+	dls, err := r.deadLetters.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, d := range dls {
+		if err := r.target.Deliver(ctx, d.Envelope); err != nil {
+			slog.Warn("Unable to replay game event", "eventID", d.Envelope.ID, "type", d.Envelope.Type, "error", err)
+			continue
+		}
+		if err := r.deadLetters.Remove(ctx, d.Key()); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// deadLetter moves the entry which failed with the given error from the outbox to the dead-letter store
+func (r *Relay) deadLetter(ctx context.Context, e secondary.OutboxEntry, failure error) error {
+	d := events.DeadLetter{Envelope: e.Envelope, Target: relayTarget, Attempts: e.Attempts + 1, Error: failure.Error(), FailedAt: time.Now().UTC()}
+	if err := r.deadLetters.Add(ctx, d); err != nil {
+		return err
+	}
+	slog.Error("Gave up relaying game event, it is in the dead letters", "seq", e.Seq, "gameID", e.GameID, "type", e.Envelope.Type, "attempts", d.Attempts)
+	return r.outbox.MarkRelayed(ctx, e.Seq)
+}
+
+// due returns true if the entry has not failed yet, or its backoff since the last failed attempt has passed
+func (r *Relay) due(e secondary.OutboxEntry, now time.Time) bool {
+	if e.Attempts == 0 {
+		return true
+	}
+
+	wait := r.cfg.MaxBackoff
+	if e.Attempts <= 30 {
+		wait = min(r.cfg.Backoff<<(e.Attempts-1), r.cfg.MaxBackoff)
+	}
+	return !now.Before(e.LastAttemptAt.Add(wait))
+}
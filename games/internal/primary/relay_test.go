@@ -0,0 +1,181 @@
+package primary
+
+import (
+	"context"
+	"errors"
+	"github.com/rpatton4/mesbg-league/games/internal/secondary"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRelayDeliversGameEventsInOrder(t *testing.T) {
+	repo := secondary.NewMemoryRepository()
+	ctrl := NewTxnController(repo, nil, nil)
+	target := &recordingDeliverer{}
+	relay := NewRelay(repo, target, nil, RelayConfig{})
+
+	g := createFakeGame()
+	g.Status = games.GameStateNotStarted
+	g, err := ctrl.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = ctrl.Start(context.Background(), g.ID); err != nil {
+		t.Fatalf("Expected no error starting the game, got %v", err)
+	}
+	if _, err = ctrl.SubmitReport(context.Background(), g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: model.GameResult{Side1TotalVictoryPoints: 3}}); err != nil {
+		t.Fatalf("Expected no error reporting the game, got %v", err)
+	}
+	if _, err = ctrl.SubmitReport(context.Background(), g.ID, &model.ResultReport{ReporterID: g.Side2ID, Result: model.GameResult{Side1TotalVictoryPoints: 3}}); err != nil {
+		t.Fatalf("Expected no error confirming the report, got %v", err)
+	}
+	if _, err = ctrl.DeleteByID(context.Background(), g.ID, 0); err != nil {
+		t.Fatalf("Expected no error deleting the game, got %v", err)
+	}
+	// A failed write records nothing
+	_, _ = ctrl.Create(context.Background(), &model.Game{})
+
+	if n, err := relay.Drain(context.Background()); n != 5 || err != nil {
+		t.Fatalf("Expected 5 entries relayed, got %d, %v", n, err)
+	}
+	want := []events.Type{events.TypeGameCreated, events.TypeGameUpdated, events.TypeGameUpdated, events.TypeGameCompleted, events.TypeGameDeleted}
+	if got := target.types(); !slices.Equal(got, want) {
+		t.Fatalf("Expected events %v, got %v", want, got)
+	}
+	completed, err := events.Decode[events.GameCompleted](target.delivered[3])
+	if err != nil || completed.Game.Side1TotalVictoryPoints != 3 {
+		t.Errorf("Expected the completed event to carry the result, got %+v, %v", completed, err)
+	}
+	deleted, err := events.Decode[events.GameDeleted](target.delivered[4])
+	if err != nil || deleted.GameID != g.ID || deleted.RoundID != g.RoundID {
+		t.Errorf("Expected the deleted event to identify the game and its round, got %+v, %v", deleted, err)
+	}
+
+	if pending, _ := repo.PendingOutbox(context.Background()); len(pending) != 0 {
+		t.Errorf("Expected the outbox to be empty once relayed, got %+v", pending)
+	}
+}
+
+func TestRelayHoldsBackOnlyTheFailingGame(t *testing.T) {
+	repo := secondary.NewMemoryRepository()
+	ctrl := NewTxnController(repo, nil, nil)
+	relay := NewRelay(repo, nil, nil, RelayConfig{Backoff: time.Hour, StuckAfter: 2})
+
+	g1, _ := ctrl.Create(context.Background(), createFakeGame())
+	g2, _ := ctrl.Create(context.Background(), createFakeGame())
+	if _, err := ctrl.DeleteByID(context.Background(), g1.ID, 0); err != nil {
+		t.Fatalf("Expected no error deleting the game, got %v", err)
+	}
+
+	// Creating the first game fails to be relayed, so its deletion has to wait behind it
+	target := &recordingDeliverer{failFor: events.TypeGameCreated, failGame: g1.ID}
+	relay.target = target
+	if n, err := relay.Drain(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Expected only the second game to be relayed, got %d, %v", n, err)
+	}
+	if len(target.delivered) != 1 || !target.isAbout(target.delivered[0], g2.ID) {
+		t.Errorf("Expected only the creation of the second game, got %v", target.types())
+	}
+
+	status, err := relay.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if status.Pending != 2 || status.Relayed != 1 || status.LagSeconds <= 0 {
+		t.Errorf("Expected 2 entries pending with some lag after 1 relayed, got %+v", status)
+	}
+	if len(status.Retrying) != 1 || status.Retrying[0].GameID != g1.ID || status.Retrying[0].Attempts != 1 || status.Retrying[0].LastError == "" {
+		t.Errorf("Expected the creation of the first game to be retrying, got %+v", status.Retrying)
+	}
+
+	// Still backing off, so nothing is attempted even once the subscriber recovers
+	target.failFor = ""
+	if n, _ := relay.Drain(context.Background()); n != 0 {
+		t.Errorf("Expected nothing to be relayed during the backoff, got %d", n)
+	}
+	relay.cfg.Backoff = time.Nanosecond
+	if n, _ := relay.Drain(context.Background()); n != 2 {
+		t.Errorf("Expected both entries of the first game to be relayed after the backoff, got %d", n)
+	}
+	if got := target.types()[1:]; !slices.Equal(got, []events.Type{events.TypeGameCreated, events.TypeGameDeleted}) {
+		t.Errorf("Expected the first game to be created before it was deleted, got %v", got)
+	}
+}
+
+func TestRelayDeadLettersAfterStuckAfterAttempts(t *testing.T) {
+	repo := secondary.NewMemoryRepository()
+	ctrl := NewTxnController(repo, nil, nil)
+	target := &recordingDeliverer{failFor: events.TypeGameCreated}
+	relay := NewRelay(repo, target, nil, RelayConfig{Backoff: time.Nanosecond, StuckAfter: 2})
+
+	g, _ := ctrl.Create(context.Background(), createFakeGame())
+	target.failGame = g.ID
+	if _, err := ctrl.DeleteByID(context.Background(), g.ID, 0); err != nil {
+		t.Fatalf("Expected no error deleting the game, got %v", err)
+	}
+
+	// The first failure holds the game back, the second gives up on the entry and lets the deletion through
+	if n, err := relay.Drain(context.Background()); n != 0 || err != nil {
+		t.Fatalf("Expected nothing relayed after the first failure, got %d, %v", n, err)
+	}
+	time.Sleep(time.Millisecond)
+	if n, err := relay.Drain(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Expected the deletion relayed once the creation was given up on, got %d, %v", n, err)
+	}
+	dls, err := relay.DeadLetters().List(context.Background())
+	if err != nil || len(dls) != 1 || dls[0].Envelope.Type != events.TypeGameCreated || dls[0].Attempts != 2 || dls[0].Error == "" {
+		t.Fatalf("Expected the creation in the dead letters after 2 attempts, got %+v, %v", dls, err)
+	}
+	if status, _ := relay.Status(context.Background()); status.Pending != 0 || status.DeadLetters != 1 {
+		t.Errorf("Expected an empty outbox and one dead letter, got %+v", status)
+	}
+
+	// Replaying fails while the subscriber is down, and delivers the entry once it is back
+	if n, err := relay.Replay(context.Background()); n != 0 || err != nil {
+		t.Errorf("Expected nothing replayed while the subscriber is down, got %d, %v", n, err)
+	}
+	target.failFor = ""
+	if n, err := relay.Replay(context.Background()); n != 1 || err != nil {
+		t.Errorf("Expected the dead letter to be replayed, got %d, %v", n, err)
+	}
+	if dls, _ = relay.DeadLetters().List(context.Background()); len(dls) != 0 {
+		t.Errorf("Expected no dead letters once replayed, got %+v", dls)
+	}
+	if got := target.types(); !slices.Equal(got, []events.Type{events.TypeGameDeleted, events.TypeGameCreated}) {
+		t.Errorf("Expected the deletion and then the replayed creation, got %v", got)
+	}
+}
+
+// recordingDeliverer is an events.Deliverer keeping every envelope delivered to it, failing those of the given
+// type for the given game
+type recordingDeliverer struct {
+	delivered []events.Envelope
+	failFor   events.Type
+	failGame  games.GameID
+}
+
+func (d *recordingDeliverer) Deliver(_ context.Context, env events.Envelope) error {
+	if env.Type == d.failFor && d.isAbout(env, d.failGame) {
+		return errors.New("subscriber is down")
+	}
+	d.delivered = append(d.delivered, env)
+	return nil
+}
+
+func (d *recordingDeliverer) types() []events.Type {
+	ts := []events.Type{}
+	for _, env := range d.delivered {
+		ts = append(ts, env.Type)
+	}
+	return ts
+}
+
+// isAbout returns true if the envelope holds a GameCreated event for the game with the given ID
+func (d *recordingDeliverer) isAbout(env events.Envelope, id games.GameID) bool {
+	e, err := events.Decode[events.GameCreated](env)
+	return err == nil && e.Game.ID == id
+}
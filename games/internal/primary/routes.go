@@ -0,0 +1,64 @@
+package primary
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rpatton4/mesbg-league/games/internal/secondary"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
+	"strings"
+)
+
+// ChangeReasonHeader is the header a caller can give the reason for a change in, it is recorded in the history of
+// the game
+const ChangeReasonHeader = "X-Change-Reason"
+
+// RegisterRoutes registers every Games operation of the handler with the given Huma API, so that the service and
+// anything embedding it (such as tests) expose exactly the same routes. The middleware recording who made each
+// change and why is registered along with them.
+func RegisterRoutes(api huma.API, handler *HumaHandler) {
+	api.UseMiddleware(auth.ActorMiddleware, changeReasonMiddleware)
+
+	huma.Get(api, "/games", handler.List)
+	huma.Get(api, "/games/{id}", handler.GetByID)
+	huma.Post(api, "/games", handler.Post)
+	huma.Put(api, "/games/{id}", handler.Put)
+	huma.Delete(api, "/games/{id}", handler.Delete)
+	huma.Post(api, "/games/{id}/restore", handler.Restore)
+	huma.Post(api, "/games/{id}/start", handler.Start)
+	huma.Post(api, "/games/{id}/complete", handler.Complete)
+	huma.Post(api, "/games/{id}/concede", handler.Concede)
+	huma.Post(api, "/games/{id}/cancel", handler.Cancel)
+	huma.Get(api, "/games/{id}/reports", handler.Reports)
+	huma.Put(api, "/games/{id}/reports/{sideId}", handler.SubmitReport)
+	huma.Get(api, "/games/{id}/history", handler.History)
+}
+
+// RegisterAdminRoutes registers the Games operations only administrators may use, such as purging deleted games for
+// good, overriding the lifecycle and resolving disputed results, refusing them to any other actor. RegisterRoutes
+// has to be called first, so the actor of each request is known.
+func RegisterAdminRoutes(api huma.API, handler *HumaHandler, admins []string) {
+	admin := huma.NewGroup(api)
+	admin.UseMiddleware(auth.RequireAdmin(api, admins))
+
+	huma.Post(admin, "/games/purge", handler.Purge)
+	huma.Post(admin, "/games/{id}/override", handler.Override)
+	huma.Post(admin, "/games/{id}/resolve", handler.Resolve)
+}
+
+// changeReasonMiddleware puts the reason from the ChangeReasonHeader of each request into its context
+func changeReasonMiddleware(ctx huma.Context, next func(huma.Context)) {
+	if reason := strings.TrimSpace(ctx.Header(ChangeReasonHeader)); reason != "" {
+		ctx = huma.WithContext(ctx, secondary.WithChangeReason(ctx.Context(), reason))
+	}
+	next(ctx)
+}
+
+// RegisterEventRoutes registers the operations for looking after the events the service publishes, which only
+// administrators may use. RegisterRoutes has to be called first, so the actor of each request is known.
+func RegisterEventRoutes(api huma.API, handler *EventsHandler, admins []string) {
+	admin := huma.NewGroup(api)
+	admin.UseMiddleware(auth.RequireAdmin(api, admins))
+
+	huma.Get(admin, "/events/outbox", handler.OutboxStatus)
+	huma.Get(admin, "/events/dead-letters", handler.DeadLetters)
+	huma.Post(admin, "/events/dead-letters/replay", handler.Replay)
+}
@@ -0,0 +1,104 @@
+package primary
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	"github.com/rpatton4/mesbg-league/games/internal/secondary"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminRoutesNeedAnAdministrator(t *testing.T) {
+	router := http.NewServeMux()
+	api := humago.New(router, huma.DefaultConfig("Games Service", "1.0.0"))
+	handler := NewHumaHandler(NewTxnController(secondary.NewMemoryRepository(), nil, nil))
+	RegisterRoutes(api, handler)
+	RegisterAdminRoutes(api, handler, []string{"organizer"})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	purge := func(actor string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/games/purge?olderThan=1h", nil)
+		if actor != "" {
+			req.Header.Set(auth.ActorHeader, actor)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := purge(""); status != http.StatusForbidden {
+		t.Errorf("Expected a purge without an actor to be forbidden, got %d", status)
+	}
+	if status := purge("player"); status != http.StatusForbidden {
+		t.Errorf("Expected a purge by a player to be forbidden, got %d", status)
+	}
+	if status := purge("organizer"); status != http.StatusOK {
+		t.Errorf("Expected a purge by an administrator to succeed, got %d", status)
+	}
+
+	// Neither side of a dispute can resolve it, only an organizer
+	resolve := func(actor string) int {
+		body := strings.NewReader(`{"reason":"photo of the table","result":{"side1TotalVictoryPoints":6,"side2TotalVictoryPoints":4}}`)
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/games/1/resolve", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(auth.ActorHeader, actor)
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if status := resolve("player"); status != http.StatusForbidden {
+		t.Errorf("Expected a resolution by a player to be forbidden, got %d", status)
+	}
+	if status := resolve("organizer"); status != http.StatusNotFound {
+		t.Errorf("Expected a resolution by an administrator to reach the unknown game, got %d", status)
+	}
+}
+
+func TestEventRoutesNeedAnAdministrator(t *testing.T) {
+	router := http.NewServeMux()
+	api := humago.New(router, huma.DefaultConfig("Games Service", "1.0.0"))
+	repo := secondary.NewMemoryRepository()
+	RegisterRoutes(api, NewHumaHandler(NewTxnController(repo, nil, nil)))
+	RegisterEventRoutes(api, NewEventsHandler(NewRelay(repo, nil, nil, RelayConfig{})), []string{"organizer"})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	call := func(method, path, actor string) int {
+		req, _ := http.NewRequest(method, srv.URL+path, nil)
+		if actor != "" {
+			req.Header.Set(auth.ActorHeader, actor)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, "/events/outbox"},
+		{http.MethodGet, "/events/dead-letters"},
+		{http.MethodPost, "/events/dead-letters/replay"},
+	} {
+		if status := call(route.method, route.path, ""); status != http.StatusForbidden {
+			t.Errorf("Expected %s %s without an actor to be forbidden, got %d", route.method, route.path, status)
+		}
+		if status := call(route.method, route.path, "player"); status != http.StatusForbidden {
+			t.Errorf("Expected %s %s by a player to be forbidden, got %d", route.method, route.path, status)
+		}
+		if status := call(route.method, route.path, "organizer"); status != http.StatusOK {
+			t.Errorf("Expected %s %s by an administrator to succeed, got %d", route.method, route.path, status)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/rpatton4/mesbg-league/games/internal/secondary"
 	games "github.com/rpatton4/mesbg-league/games/pkg"
 	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"time"
 )
 
 //go:generate mockgen --destination ./mocks/controller.go github.com/rpatton4/mesbg-league/games/internal/primary SingleController
@@ -18,12 +20,64 @@ type SingleController interface {
 	Create(ctx context.Context, g *model.Game) (*model.Game, error)
 
 	// Replace updates an existing game in the repository with the provided game.
-	// A generic error is returned if the game to replaced is not present in the data store.
+	// A generic error is returned if the game to replaced is not present in the data store, a
+	// svcerrors.ErrIllegalStateTransition if the game's lifecycle does not allow the change of status, and a
+	// svcerrors.ErrVersionConflict if the game has a Version and the stored game has changed since that version.
 	Replace(ctx context.Context, g *model.Game) (*model.Game, error)
 
-	// DeleteByID removes the game with the given id from the repository. Returns true if the game was found and
-	// deleted, false otherwise. This is an idempotent operation.
-	DeleteByID(ctx context.Context, id games.GameID) (bool, error)
+	// DeleteByID removes the game with the given id from the repository, leaving a tombstone so that it can be
+	// restored until it is purged. Returns true if the game was found and deleted, false otherwise. A
+	// svcerrors.ErrVersionConflict is returned if the version is not zero and the game has changed since that version.
+	DeleteByID(ctx context.Context, id games.GameID, version int64) (bool, error)
+
+	// Restore brings back the deleted game with the given id as it was before it was deleted. A
+	// svcerrors.ErrNotFound is returned if there is no deleted game with the id, including once it has been purged.
+	Restore(ctx context.Context, id games.GameID) (*model.Game, error)
+
+	// Purge removes every game deleted more than olderThan ago for good, returning how many were removed. A
+	// svcerrors.ErrInvalidQuery is returned if the age is negative.
+	Purge(ctx context.Context, olderThan time.Duration) (int, error)
+
+	// List returns one page of the games matching the filters in the query, in a stable order suitable for paging
+	// with the returned cursor. A svcerrors.ErrInvalidQuery is returned if the query cannot be used.
+	List(ctx context.Context, q model.GameQuery) (*model.GameList, error)
+
+	// History returns every change made to the game with the given id, oldest first, including any made before it
+	// was deleted. A svcerrors.ErrNotFound is returned if there is no history for the id.
+	History(ctx context.Context, id games.GameID) ([]model.HistoryEntry, error)
+
+	// Start moves the game with the given id into play. A svcerrors.ErrIllegalStateTransition is returned if the
+	// game's current state does not allow it to be started.
+	Start(ctx context.Context, id games.GameID) (*model.Game, error)
+
+	// Complete marks the play of the game with the given id as finished, leaving it waiting for the result report of
+	// each side. A svcerrors.ErrIllegalStateTransition is returned if the game's current state does not allow it to
+	// be completed.
+	Complete(ctx context.Context, id games.GameID) (*model.Game, error)
+
+	// Concede marks the game with the given id as conceded by the given side. A svcerrors.ErrModelInvalid is
+	// returned if the conceding player is not one of the sides, and svcerrors.ErrIllegalStateTransition if the
+	// game's current state does not allow it to be conceded.
+	Concede(ctx context.Context, id games.GameID, by players.PlayerID) (*model.Game, error)
+
+	// Cancel marks the game with the given id as cancelled. A svcerrors.ErrIllegalStateTransition is returned if
+	// the game's current state does not allow it to be cancelled.
+	Cancel(ctx context.Context, id games.GameID) (*model.Game, error)
+
+	// OverrideState is the administrative escape hatch for the lifecycle, moving the game with the given id to any
+	// known state regardless of the usual transition rules. A reason must be given, it is recorded in the logs.
+	OverrideState(ctx context.Context, id games.GameID, to games.GameState, reason string) (*model.Game, error)
+
+	// SubmitReport records the result of the game with the given id as reported by one of its sides, replacing any
+	// earlier report from the same side, and then reconciles the reports. A svcerrors.ErrModelInvalid is returned if
+	// the reporter is not one of the sides, and svcerrors.ErrIllegalStateTransition if the game's current state does
+	// not take reports, such as once it is completed or disputed.
+	SubmitReport(ctx context.Context, id games.GameID, r *model.ResultReport) (*model.Game, error)
+
+	// ResolveDispute completes the disputed game with the given id with the result decided by an organizer,
+	// recording who resolved it and why. A svcerrors.ErrModelInvalid is returned if the organizer or reason is
+	// missing, and svcerrors.ErrIllegalStateTransition if the game is not disputed.
+	ResolveDispute(ctx context.Context, id games.GameID, res *model.Resolution, r *model.GameResult) (*model.Game, error)
 }
 
 // NewDefaultSingleController creates an instance of the default single controller implementation. This default is controlled
@@ -3,52 +3,371 @@ package primary
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/rpatton4/mesbg-league/games/internal/secondary"
 	"github.com/rpatton4/mesbg-league/games/pkg"
 	"github.com/rpatton4/mesbg-league/games/pkg/model"
 	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 )
 
-// TxnController implements the single controller for game operations.
+// reportAttempts is how many times submitting a result report is tried when the game is changed by someone else at
+// the same time, such as the other side reporting in the same moment
+const reportAttempts = 3
+
+// TxnController implements the single controller for game operations. The events for the changes it makes are
+// recorded in the outbox by the repository, in the same write, and published from there by a Relay.
 type TxnController struct {
-	repo secondary.Repository
+	repo   secondary.Repository
+	limits secondary.VictoryPointLimits
+	lists  secondary.ArmyListLimits
 }
 
 // NewTxnController creates a new instance of the games controller for transactional behavior in the sense of realtime
-// operations on a game, versus batch
-func NewTxnController(r secondary.Repository) *TxnController {
-	return &TxnController{repo: r}
+// operations on a game, versus batch. The limits are used to reject results scoring more victory points than the
+// scenario of the game's round allows, and the lists to reject results for games played with an army list over the
+// points limit of the round. Each check is skipped if what it uses is nil.
+func NewTxnController(r secondary.Repository, limits secondary.VictoryPointLimits, lists secondary.ArmyListLimits) *TxnController {
+	return &TxnController{repo: r, limits: limits, lists: lists}
 }
 
 // GetByID returns the game with the given id, or a svcerrors.ErrNotFound if no game with that id exists
 func (c *TxnController) GetByID(ctx context.Context, id pkg.GameID) (*model.Game, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
 	return c.repo.GetByID(ctx, id)
 }
 
 // Create persists a new game instance to the repository and returns the game with an assigned ID.
 // A generic error is returned if the game to created is missing, while specific validation errors are
-// passed along from the repository if the game is invalid in some way.
+// passed along from the repository if the game is invalid in some way. A svcerrors.ErrModelInvalid is returned if
+// the game scores more victory points than its scenario allows.
 func (c *TxnController) Create(ctx context.Context, g *model.Game) (*model.Game, error) {
 	if g == nil {
-		return nil, errors.New("the game to be created cannot be nil")
+		return nil, fmt.Errorf("the game to be created cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	} else if err := c.checkVictoryPoints(ctx, g); err != nil {
+		return nil, err
 	}
 	return c.repo.Create(ctx, g)
 }
 
 // Replace updates an existing game in the repository with the provided game.
-// A generic error is returned if the game to replaced is not present in the data store.
+// A generic error is returned if the game to replaced is not present in the data store, a
+// svcerrors.ErrIllegalStateTransition if the game's lifecycle does not allow the change of status or the scores of a
+// game past play are changed, a svcerrors.ErrVersionConflict if the stored game has changed since the game's Version,
+// and a svcerrors.ErrModelInvalid if the game scores more victory points than its scenario allows. A game without a
+// Version replaces the version the lifecycle was checked against, so a change made in between is still a conflict.
+// The result reports and any resolution are kept as they are stored, whatever was sent, as the scores of a game past
+// play are only ever set by them.
 func (c *TxnController) Replace(ctx context.Context, g *model.Game) (*model.Game, error) {
 	if g == nil {
-		return nil, errors.New("the game to be replaced cannot be nil")
+		return nil, fmt.Errorf("the game to be replaced cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	}
+
+	// Missing IDs and unknown games are reported by the repository, only the lifecycle is checked here
+	if g.ID != "" {
+		if current, err := c.repo.GetByID(ctx, g.ID); err == nil && current != nil {
+			if !current.Status.CanTransitionTo(g.Status) {
+				return nil, fmt.Errorf("game '%s' cannot move from %s to %s. Source: %w", g.ID, current.Status, g.Status, svcerrors.ErrIllegalStateTransition)
+			}
+			if (pastPlay(current.Status) || pastPlay(g.Status)) && !reflect.DeepEqual(g.Result(), current.Result()) {
+				return nil, fmt.Errorf("the scores of game '%s' can only be set by the result reports of its sides once play is over. Source: %w", g.ID, svcerrors.ErrIllegalStateTransition)
+			}
+			// The reports and their resolution are only changed by reporting and resolving, never by a replace
+			next := *g
+			next.Reports, next.Resolution = current.Reports, current.Resolution
+			if next.Version == 0 {
+				next.Version = current.Version
+			}
+			g = &next
+		}
+	}
+	if err := c.checkVictoryPoints(ctx, g); err != nil {
+		return nil, err
 	}
 	return c.repo.Replace(ctx, g)
 }
 
-// DeleteByID removes the game with the given id from the repository. Returns true if the game was found and
-// deleted, false otherwise. This is an idempotent operation.
-func (c *TxnController) DeleteByID(ctx context.Context, id pkg.GameID) (bool, error) {
+// DeleteByID removes the game with the given id from the repository, leaving a tombstone so that it can be restored
+// until it is purged. Returns true if the game was found and deleted, false otherwise. A
+// svcerrors.ErrVersionConflict is returned if the version is not zero and the game has changed since that version.
+func (c *TxnController) DeleteByID(ctx context.Context, id pkg.GameID, version int64) (bool, error) {
 	if id == "" {
 		return false, svcerrors.ErrInvalidID
 	}
-	return c.repo.DeleteByID(ctx, id)
+	return c.repo.DeleteByID(ctx, id, version)
+}
+
+// Restore brings back the deleted game with the given id as it was before it was deleted. A svcerrors.ErrNotFound
+// is returned if there is no deleted game with the id, including once it has been purged.
+func (c *TxnController) Restore(ctx context.Context, id pkg.GameID) (*model.Game, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+	return c.repo.Restore(ctx, id)
+}
+
+// Purge removes every game deleted more than olderThan ago for good, returning how many were removed. A
+// svcerrors.ErrInvalidQuery is returned if the age is negative.
+func (c *TxnController) Purge(ctx context.Context, olderThan time.Duration) (int, error) {
+	if olderThan < 0 {
+		return 0, fmt.Errorf("the age of the games to purge cannot be negative, got %s. Source: %w", olderThan, svcerrors.ErrInvalidQuery)
+	}
+
+	n, err := c.repo.Purge(ctx, time.Now().UTC().Add(-olderThan))
+	if err == nil {
+		slog.Info("Purged deleted games", "count", n, "olderThan", olderThan.String())
+	}
+	return n, err
+}
+
+// List returns one page of the games matching the filters in the query, in a stable order suitable for paging
+// with the returned cursor. A svcerrors.ErrInvalidQuery is returned if the query cannot be used.
+func (c *TxnController) List(ctx context.Context, q model.GameQuery) (*model.GameList, error) {
+	if q.Limit < 0 {
+		return nil, fmt.Errorf("the page limit cannot be negative, got %d. Source: %w", q.Limit, svcerrors.ErrInvalidQuery)
+	}
+	return c.repo.List(ctx, q)
+}
+
+// History returns every change made to the game with the given id, oldest first, including any made before it was
+// deleted. A svcerrors.ErrNotFound is returned if there is no history for the id.
+func (c *TxnController) History(ctx context.Context, id pkg.GameID) ([]model.HistoryEntry, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+	return c.repo.History(ctx, id)
+}
+
+// Start moves the game with the given id into play. A svcerrors.ErrIllegalStateTransition is returned if the
+// game's current state does not allow it to be started.
+func (c *TxnController) Start(ctx context.Context, id pkg.GameID) (*model.Game, error) {
+	return c.transition(ctx, id, pkg.GameStateInProgress, pkg.GameState.CanTransitionTo, nil)
+}
+
+// Complete marks the play of the game with the given id as finished, leaving it waiting for the result report of
+// each side. The game is only given its result once the reports agree, see SubmitReport. A
+// svcerrors.ErrIllegalStateTransition is returned if the game's current state does not allow it to be completed.
+func (c *TxnController) Complete(ctx context.Context, id pkg.GameID) (*model.Game, error) {
+	return c.transition(ctx, id, pkg.GameStateAwaitingConfirmation, pkg.GameState.CanTransitionTo, nil)
+}
+
+// Concede marks the game with the given id as conceded by the given side. A svcerrors.ErrModelInvalid is
+// returned if the conceding player is not one of the sides, and svcerrors.ErrIllegalStateTransition if the
+// game's current state does not allow it to be conceded.
+func (c *TxnController) Concede(ctx context.Context, id pkg.GameID, by players.PlayerID) (*model.Game, error) {
+	return c.transition(ctx, id, pkg.GameStateConceded, pkg.GameState.CanTransitionTo, func(_ pkg.GameState, g *model.Game) error {
+		if by == "" || (by != g.Side1ID && by != g.Side2ID) {
+			return fmt.Errorf("player '%s' is not a side in game '%s' and cannot concede it. Source: %w", by, id, svcerrors.ErrModelInvalid)
+		}
+		g.ConcedingSideID = by
+		return nil
+	})
+}
+
+// Cancel marks the game with the given id as cancelled. A svcerrors.ErrIllegalStateTransition is returned if
+// the game's current state does not allow it to be cancelled.
+func (c *TxnController) Cancel(ctx context.Context, id pkg.GameID) (*model.Game, error) {
+	return c.transition(ctx, id, pkg.GameStateCancelled, pkg.GameState.CanTransitionTo, nil)
+}
+
+// OverrideState is the administrative escape hatch for the lifecycle, moving the game with the given id to any
+// known state regardless of the usual transition rules. A reason must be given, it is recorded in the logs.
+func (c *TxnController) OverrideState(ctx context.Context, id pkg.GameID, to pkg.GameState, reason string) (*model.Game, error) {
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required to override the state of game '%s'. Source: %w", id, svcerrors.ErrModelInvalid)
+	}
+	if !to.IsValid() {
+		return nil, fmt.Errorf("cannot override game '%s' to unknown state %s. Source: %w", id, to, svcerrors.ErrModelInvalid)
+	}
+
+	g, err := c.transition(secondary.WithChangeReason(ctx, reason), id, to, nil, nil)
+	if err == nil {
+		slog.Warn("Game state overridden", "gameID", id, "status", to.String(), "reason", reason)
+	}
+	return g, err
+}
+
+// SubmitReport records the result of the game with the given id as reported by one of its sides, replacing any
+// earlier report from the same side, and then reconciles the reports. A svcerrors.ErrModelInvalid is returned if
+// the reporter is not one of the sides, the result scores more victory points than the scenario allows or a side's
+// army list is over the points limit of the game's round, and svcerrors.ErrIllegalStateTransition if the game's
+// current state does not take reports, such as once it is completed or disputed.
+func (c *TxnController) SubmitReport(ctx context.Context, id pkg.GameID, r *model.ResultReport) (*model.Game, error) {
+	if r == nil {
+		return nil, fmt.Errorf("the result report for game '%s' is missing. Source: %w", id, svcerrors.ErrModelMissing)
+	}
+
+	report := *r
+	report.SubmittedAt = time.Now().UTC()
+	apply := func(_ pkg.GameState, g *model.Game) error {
+		if report.ReporterID == "" || (report.ReporterID != g.Side1ID && report.ReporterID != g.Side2ID) {
+			return fmt.Errorf("player '%s' is not a side in game '%s' and cannot report its result. Source: %w", report.ReporterID, id, svcerrors.ErrModelInvalid)
+		} else if err := c.checkArmyLists(ctx, g); err != nil {
+			return err
+		}
+
+		reports := []model.ResultReport{report}
+		for _, other := range g.Reports {
+			if other.ReporterID != report.ReporterID {
+				reports = append(reports, other)
+			}
+		}
+		g.Reports = reports
+		reconcile(g)
+		return nil
+	}
+
+	// Both sides reporting at the same moment makes one of the writes fail on the version, reading the game again
+	// picks up the other report so the retry reconciles both
+	for attempt := 1; ; attempt++ {
+		g, err := c.transition(ctx, id, pkg.GameStateAwaitingConfirmation, pkg.GameState.CanSettleTo, apply)
+		if !errors.Is(err, svcerrors.ErrVersionConflict) || attempt == reportAttempts {
+			return g, err
+		}
+	}
+}
+
+// ResolveDispute completes the disputed game with the given id with the result decided by an organizer, recording
+// who resolved it and why. A svcerrors.ErrModelInvalid is returned if the organizer or reason is missing or the
+// result scores more victory points than the scenario allows, and
+// svcerrors.ErrIllegalStateTransition if the game is not disputed.
+func (c *TxnController) ResolveDispute(ctx context.Context, id pkg.GameID, res *model.Resolution, r *model.GameResult) (*model.Game, error) {
+	if res == nil || r == nil {
+		return nil, fmt.Errorf("the resolution and result for game '%s' are required. Source: %w", id, svcerrors.ErrModelMissing)
+	} else if res.ResolvedBy == "" || res.Reason == "" {
+		return nil, fmt.Errorf("the organizer resolving game '%s' and their reason are required. Source: %w", id, svcerrors.ErrModelInvalid)
+	}
+
+	resolution := *res
+	resolution.ResolvedAt = time.Now().UTC()
+	g, err := c.transition(secondary.WithChangeReason(ctx, resolution.Reason), id, pkg.GameStatePlayCompleted, pkg.GameState.CanSettleTo, func(from pkg.GameState, g *model.Game) error {
+		if from != pkg.GameStateDisputed {
+			return fmt.Errorf("game '%s' is %s, only a disputed game can be resolved. Source: %w", id, from, svcerrors.ErrIllegalStateTransition)
+		}
+		r.ApplyTo(g)
+		g.Resolution = &resolution
+		return nil
+	})
+	if err == nil {
+		slog.Info("Game dispute resolved", "gameID", id, "resolvedBy", resolution.ResolvedBy, "reason", resolution.Reason)
+	}
+	return g, err
+}
+
+// pastPlay returns true if a game in the given state has finished being played, one way or another, so that its
+// scores are settled by its result reports rather than set directly
+func pastPlay(s pkg.GameState) bool {
+	return s != pkg.GameStateNotStarted && s != pkg.GameStateInProgress
+}
+
+// reconcile moves a game to the state called for by its result reports: waiting for the other side while only one
+// side has reported, completed with the agreed result once both reports agree, and disputed if they do not
+func reconcile(g *model.Game) {
+	r1, r2 := g.ReportBy(g.Side1ID), g.ReportBy(g.Side2ID)
+	switch {
+	case r1 == nil || r2 == nil:
+		g.Status = pkg.GameStateAwaitingConfirmation
+	case r1.Agrees(r2):
+		result := model.AgreedResult(r1, r2)
+		result.ApplyTo(g)
+		g.Status = pkg.GameStatePlayCompleted
+	default:
+		g.Status = pkg.GameStateDisputed
+	}
+}
+
+// transition moves the stored game with the given id to a new state, applying any other changes from the apply
+// function to a copy of the game before it is written back. The apply function is given the state the game is moving
+// from, and may move the game on to a further state which the rules also have to allow. The rules are usually
+// pkg.GameState.CanTransitionTo, pkg.GameState.CanSettleTo for changes made by the result reports, and nil skips
+// them for an override. The results the apply function leaves on the game are checked against the victory points its
+// scenario allows.
+func (c *TxnController) transition(ctx context.Context, id pkg.GameID, to pkg.GameState, allowed func(from, to pkg.GameState) bool, apply func(from pkg.GameState, g *model.Game) error) (*model.Game, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+
+	current, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if current == nil {
+		return nil, fmt.Errorf("the game with the given ID '%s' is not found. Source: %w", id, svcerrors.ErrNotFound)
+	}
+
+	if allowed != nil && !allowed(current.Status, to) {
+		return nil, fmt.Errorf("game '%s' cannot move from %s to %s. Source: %w", id, current.Status, to, svcerrors.ErrIllegalStateTransition)
+	}
+
+	next := *current
+	next.Status = to
+	if to != pkg.GameStateConceded {
+		next.ConcedingSideID = ""
+	}
+	if apply != nil {
+		if err := apply(current.Status, &next); err != nil {
+			return nil, err
+		}
+		if allowed != nil && next.Status != to && !allowed(current.Status, next.Status) {
+			return nil, fmt.Errorf("game '%s' cannot move from %s to %s. Source: %w", id, current.Status, next.Status, svcerrors.ErrIllegalStateTransition)
+		}
+		if err := c.checkVictoryPoints(ctx, &next); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.repo.Replace(ctx, &next)
+}
+
+// checkVictoryPoints makes sure neither side of the game, nor any of its result reports, scores more victory points
+// than the scenario of the game's round allows. The limit is only looked up when there are victory points to check.
+func (c *TxnController) checkVictoryPoints(ctx context.Context, g *model.Game) error {
+	if c.limits == nil || g.RoundID == "" {
+		return nil
+	}
+
+	scored := []int{g.Side1TotalVictoryPoints, g.Side2TotalVictoryPoints}
+	for _, r := range g.Reports {
+		scored = append(scored, r.Result.Side1TotalVictoryPoints, r.Result.Side2TotalVictoryPoints)
+	}
+	if !slices.ContainsFunc(scored, func(vp int) bool { return vp != 0 }) {
+		return nil
+	}
+
+	most, scenario, err := c.limits.MaxVictoryPoints(ctx, g)
+	if err != nil {
+		return fmt.Errorf("unable to check the victory points of game '%s': %w", g.ID, err)
+	} else if most == 0 {
+		return nil
+	}
+	for _, vp := range scored {
+		if vp > most {
+			return fmt.Errorf("game '%s' has a side scoring %d victory points but %s allows at most %d. Source: %w",
+				g.ID, vp, scenario, most, svcerrors.ErrModelInvalid)
+		}
+	}
+	return nil
+}
+
+// checkArmyLists makes sure the army list each side of the game uses fits the points limit of the game's round, so a
+// result cannot be recorded for a game played with a list over the limit. A side using the list registered for every
+// round, rather than one for the game's round, is held to the same limit.
+func (c *TxnController) checkArmyLists(ctx context.Context, g *model.Game) error {
+	if c.lists == nil || g.RoundID == "" {
+		return nil
+	}
+
+	over, err := c.lists.OverLimit(ctx, g)
+	if err != nil {
+		return fmt.Errorf("unable to check the army lists of game '%s': %w", g.ID, err)
+	} else if len(over) > 0 {
+		return fmt.Errorf("game '%s' cannot take a result as %s. Source: %w", g.ID, strings.Join(over, ", "), svcerrors.ErrModelInvalid)
+	}
+	return nil
 }
@@ -1,9 +1,12 @@
 package primary
 
 import (
+	"context"
+	"errors"
 	"github.com/rpatton4/mesbg-league/games/internal/secondary"
 	games "github.com/rpatton4/mesbg-league/games/pkg"
 	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
 	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
 	"testing"
 )
@@ -55,6 +58,7 @@ func TestTxnControllerReplaceGame(t *testing.T) {
 	g := createFakeGame()
 	g.Side1TotalVictoryPoints = originalScore
 	g.RoundID = originalRoundID
+	g.Status = games.GameStateInProgress
 
 	g, err := ctrl.Create(nil, g)
 	if err != nil {
@@ -90,6 +94,51 @@ func TestTxnControllerReplaceGame(t *testing.T) {
 	}
 }
 
+func TestTxnControllerReplaceWithoutVersion(t *testing.T) {
+	repo := &racingRepository{Repository: secondary.NewMemoryRepository()}
+	ctrl := NewTxnController(repo, nil, nil)
+	g := createFakeGame()
+	g.Status = games.GameStateInProgress
+	g, err := ctrl.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Someone else changes the game between the lifecycle being checked and the game being written
+	repo.race = func() {
+		other := *g
+		other.Side2TotalVictoryPoints = 20
+		if _, err := repo.Repository.Replace(context.Background(), &other); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	update := *g
+	update.Version = 0
+	update.Side1TotalVictoryPoints = 12
+	if _, err = ctrl.Replace(context.Background(), &update); !errors.Is(err, svcerrors.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict when the game changed after it was read, got %v", err)
+	}
+
+	if _, err = ctrl.Replace(context.Background(), &update); err != nil {
+		t.Errorf("Expected the game to be replaced whatever its version when nothing changes it in between, got %v", err)
+	}
+}
+
+// racingRepository runs race, once, after the next game is read, standing in for a change made at the same time
+type racingRepository struct {
+	secondary.Repository
+	race func()
+}
+
+func (r *racingRepository) GetByID(ctx context.Context, id games.GameID) (*model.Game, error) {
+	g, err := r.Repository.GetByID(ctx, id)
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+	return g, err
+}
+
 func TestTxnControllerDeleteGame(t *testing.T) {
 	ctrl := createController()
 	// Create the game to delete
@@ -102,7 +151,7 @@ func TestTxnControllerDeleteGame(t *testing.T) {
 		t.Errorf("Expected game ID to be assigned, got 0")
 	}
 
-	success, err := ctrl.DeleteByID(nil, g.ID)
+	success, err := ctrl.DeleteByID(nil, g.ID, 0)
 
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
@@ -113,7 +162,7 @@ func TestTxnControllerDeleteGame(t *testing.T) {
 	}
 
 	// now delete it again, it should fail
-	success, err = ctrl.DeleteByID(nil, g.ID)
+	success, err = ctrl.DeleteByID(nil, g.ID, 0)
 	if err == nil {
 		t.Fatalf("Expected error while deleting non-existent game, but it succeeded")
 	}
@@ -123,6 +172,366 @@ func TestTxnControllerDeleteGame(t *testing.T) {
 	}
 }
 
+func TestTxnControllerLifecycle(t *testing.T) {
+	ctrl := createController()
+	g := createFakeGame()
+	g.Status = games.GameStateNotStarted
+	g.Side1TotalVictoryPoints = 0
+	g.Side2TotalVictoryPoints = 0
+
+	g, err := ctrl.Create(nil, g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	g, err = ctrl.Start(nil, g.ID)
+	if err != nil {
+		t.Fatalf("Expected no error starting the game, got %v", err)
+	}
+	if g.Status != games.GameStateInProgress {
+		t.Errorf("Expected game to be in progress, got %s", g.Status)
+	}
+
+	// A game in progress cannot be given a bye
+	reopen := *g
+	reopen.Status = games.GameStateBye
+	if _, err = ctrl.Replace(nil, &reopen); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error, got %v", err)
+	}
+
+	// Neither a replace nor completing play gives the game a result, only the reports of both sides do
+	completed := *g
+	completed.Status = games.GameStatePlayCompleted
+	if _, err = ctrl.Replace(nil, &completed); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error replacing a game in progress as completed, got %v", err)
+	}
+	g, err = ctrl.Complete(nil, g.ID)
+	if err != nil {
+		t.Fatalf("Expected no error completing the game, got %v", err)
+	}
+	if g.Status != games.GameStateAwaitingConfirmation {
+		t.Errorf("Expected completed play to await the reports, got %s", g.Status)
+	}
+	g, err = settle(ctrl, g, model.GameResult{Side1TotalVictoryPoints: 12, Side2TotalVictoryPoints: 4, Side1KilledGeneral: true})
+	if err != nil {
+		t.Fatalf("Expected no error reporting the game, got %v", err)
+	}
+	if g.Status != games.GameStatePlayCompleted || g.Side1TotalVictoryPoints != 12 || !g.Side1KilledGeneral {
+		t.Errorf("Expected completed game with the result applied, got %+v", g)
+	}
+
+	// The scores of a completed game cannot be rewritten, though its other details can be corrected
+	rescored := *g
+	rescored.Side2TotalVictoryPoints = 6
+	if _, err = ctrl.Replace(nil, &rescored); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error changing the scores of a completed game, got %v", err)
+	}
+	rescored = *g
+	rescored.RoundID = "790"
+	if g, err = ctrl.Replace(nil, &rescored); err != nil || g.RoundID != "790" {
+		t.Errorf("Expected the round of a completed game to be corrected, got %+v, %v", g, err)
+	}
+
+	// Completed games cannot be reopened, through the actions or a full replace
+	if _, err = ctrl.Start(nil, g.ID); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error restarting a completed game, got %v", err)
+	}
+	reopen = *g
+	reopen.Status = games.GameStateNotStarted
+	if _, err = ctrl.Replace(nil, &reopen); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error replacing a completed game as not started, got %v", err)
+	}
+
+	// ...unless an administrator explicitly overrides it, which needs a reason
+	if _, err = ctrl.OverrideState(nil, g.ID, games.GameStateInProgress, ""); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error overriding without a reason, got %v", err)
+	}
+	g, err = ctrl.OverrideState(nil, g.ID, games.GameStateInProgress, "result entered against the wrong game")
+	if err != nil {
+		t.Fatalf("Expected no error overriding the state, got %v", err)
+	}
+	if g.Status != games.GameStateInProgress {
+		t.Errorf("Expected overridden game to be in progress, got %s", g.Status)
+	}
+
+	// Only a side in the game can concede it
+	if _, err = ctrl.Concede(nil, g.ID, "not-a-side"); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error conceding as a non-participant, got %v", err)
+	}
+	g, err = ctrl.Concede(nil, g.ID, g.Side2ID)
+	if err != nil {
+		t.Fatalf("Expected no error conceding the game, got %v", err)
+	}
+	if g.Status != games.GameStateConceded || g.ConcedingSideID != g.Side2ID {
+		t.Errorf("Expected game conceded by side 2, got %+v", g)
+	}
+
+	if _, err = ctrl.Cancel(nil, g.ID); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error cancelling a conceded game, got %v", err)
+	}
+	if _, err = ctrl.Cancel(nil, "does-not-exist"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error cancelling an unknown game, got %v", err)
+	}
+}
+
+func TestTxnControllerReports(t *testing.T) {
+	ctrl := createController()
+	newGame := func() *model.Game {
+		g := createFakeGame()
+		g.Status = games.GameStateInProgress
+		g, err := ctrl.Create(nil, g)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return g
+	}
+	result := model.GameResult{Side1TotalVictoryPoints: 12, Side2TotalVictoryPoints: 4, Side1KilledGeneral: true}
+
+	// Matching reports complete the game with the agreed result
+	g := newGame()
+	if _, err := ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: "not-a-side", Result: result}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error reporting as a non-participant, got %v", err)
+	}
+	g, err := ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: result})
+	if err != nil {
+		t.Fatalf("Expected no error submitting the first report, got %v", err)
+	}
+	if g.Status != games.GameStateAwaitingConfirmation || len(g.Reports) != 1 || g.Reports[0].SubmittedAt.IsZero() {
+		t.Errorf("Expected the game to await confirmation with one timestamped report, got %+v", g)
+	}
+	g, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side2ID, Result: result})
+	if err != nil {
+		t.Fatalf("Expected no error submitting the second report, got %v", err)
+	}
+	if g.Status != games.GameStatePlayCompleted || g.Side1TotalVictoryPoints != 12 || g.Side2KilledGeneral {
+		t.Errorf("Expected the game completed with the reported result, got %+v", g)
+	}
+	if _, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: result}); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error reporting on a completed game, got %v", err)
+	}
+
+	// A side can correct its report until the other side has reported
+	g = newGame()
+	wrong := result
+	wrong.Side1TotalVictoryPoints = 10
+	if _, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: wrong}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: result}); err != nil {
+		t.Fatalf("Expected no error correcting the report, got %v", err)
+	}
+	g, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side2ID, Result: result})
+	if err != nil || g.Status != games.GameStatePlayCompleted || len(g.Reports) != 2 {
+		t.Errorf("Expected the corrected report to agree and complete the game, got %+v, %v", g, err)
+	}
+
+	// Reports which disagree dispute the game until an organizer resolves it
+	g = newGame()
+	if _, err = ctrl.ResolveDispute(nil, g.ID, &model.Resolution{ResolvedBy: "1", Reason: "early"}, &result); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error resolving a game which is not disputed, got %v", err)
+	}
+	if _, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: result}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	g, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side2ID, Result: wrong})
+	if err != nil {
+		t.Fatalf("Expected no error submitting a conflicting report, got %v", err)
+	}
+	if g.Status != games.GameStateDisputed {
+		t.Errorf("Expected the game to be disputed, got %s", g.Status)
+	}
+	if _, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side2ID, Result: result}); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error reporting on a disputed game, got %v", err)
+	}
+	// Only resolving the dispute completes it, and a replace cannot change the reports
+	if _, err = ctrl.Complete(nil, g.ID); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error completing a disputed game, got %v", err)
+	}
+	replaced := *g
+	replaced.Status = games.GameStatePlayCompleted
+	if _, err = ctrl.Replace(nil, &replaced); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error replacing a disputed game as completed, got %v", err)
+	}
+	replaced.Status, replaced.Reports = games.GameStateDisputed, nil
+	replaced.Resolution = &model.Resolution{ResolvedBy: "2", Reason: "sent by a client"}
+	if stored, err := ctrl.Replace(nil, &replaced); err != nil || len(stored.Reports) != 2 || stored.Resolution != nil {
+		t.Errorf("Expected a replace to keep the stored reports and no resolution, got %+v, %v", stored, err)
+	}
+	if _, err = ctrl.ResolveDispute(nil, g.ID, &model.Resolution{ResolvedBy: "1"}, &result); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error resolving without a reason, got %v", err)
+	}
+	g, err = ctrl.ResolveDispute(nil, g.ID, &model.Resolution{ResolvedBy: "1", Reason: "photo of the table"}, &wrong)
+	if err != nil {
+		t.Fatalf("Expected no error resolving the dispute, got %v", err)
+	}
+	if g.Status != games.GameStatePlayCompleted || g.Side1TotalVictoryPoints != 10 || g.Resolution == nil ||
+		g.Resolution.ResolvedBy != "1" || g.Resolution.Reason != "photo of the table" || g.Resolution.ResolvedAt.IsZero() {
+		t.Errorf("Expected the game completed with the organizer's result and resolution, got %+v", g)
+	}
+}
+
+func TestTxnControllerResultBreakdowns(t *testing.T) {
+	ctrl := createController()
+	newGame := func() *model.Game {
+		g := createFakeGame()
+		g.Status = games.GameStateInProgress
+		g, err := ctrl.Create(nil, g)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return g
+	}
+	breakdown := func() *model.SideBreakdown {
+		return &model.SideBreakdown{VictoryPoints: map[string]int{"objectives": 9, "heroes": 3}, HeroesSlain: []string{"Gothmog"}, ModelsRemoved: 11}
+	}
+	result := func() *model.GameResult {
+		return &model.GameResult{Side1TotalVictoryPoints: 12, Side2TotalVictoryPoints: 4, Side1Breakdown: breakdown(),
+			Side2Breakdown: &model.SideBreakdown{ModelsRemoved: 5, Broken: true}}
+	}
+
+	g := newGame()
+	bad := result()
+	bad.Side1Breakdown.VictoryPoints["objectives"] = 8
+	if _, err := ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: *bad}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error for a breakdown which does not add up to the total, got %v", err)
+	}
+	bad = result()
+	bad.Side2Breakdown.Broken = false
+	bad.Side2Breakdown.Quartered = true
+	if _, err := ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: *bad}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error for a side quartered but not broken, got %v", err)
+	}
+	g, err := settle(ctrl, g, *result())
+	if err != nil {
+		t.Fatalf("Expected no error completing the game with a breakdown, got %v", err)
+	}
+	if g.Side1Breakdown == nil || g.Side1Breakdown.ModelsRemoved != 11 || g.Side2Breakdown == nil || !g.Side2Breakdown.Broken {
+		t.Errorf("Expected the breakdowns to be kept on the game, got %+v", g)
+	}
+
+	// Reports agree on the totals alone, each side's breakdown comes from its own report
+	g = newGame()
+	if _, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: *result()}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	fewer := result()
+	fewer.Side1Breakdown.ModelsRemoved = 10
+	fewer.Side2Breakdown.Quartered = true
+	if g, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side2ID, Result: *fewer}); err != nil || g.Status != games.GameStatePlayCompleted {
+		t.Fatalf("Expected reports with the same totals to complete the game, got %+v, %v", g, err)
+	}
+	if g.Side1Breakdown.ModelsRemoved != 11 || !g.Side2Breakdown.Quartered {
+		t.Errorf("Expected each side's breakdown from its own report, got %+v and %+v", g.Side1Breakdown, g.Side2Breakdown)
+	}
+
+	// A side which gave no breakdown of its own game takes the other side's account of it
+	g = newGame()
+	noBreakdowns := result()
+	noBreakdowns.Side2Breakdown = nil
+	if _, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: *result()}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	noBreakdowns.Side1Breakdown = nil
+	if g, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side2ID, Result: *noBreakdowns}); err != nil || g.Status != games.GameStatePlayCompleted {
+		t.Fatalf("Expected the reports to complete the game, got %+v, %v", g, err)
+	}
+	if g.Side1Breakdown == nil || g.Side2Breakdown == nil || !g.Side2Breakdown.Broken {
+		t.Errorf("Expected the second side's breakdown from the first side's report, got %+v", g.Side2Breakdown)
+	}
+
+	// Reports which disagree on the totals still dispute the game
+	g = newGame()
+	if _, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: *result()}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	other := result()
+	other.Side2KilledGeneral = true
+	if g, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side2ID, Result: *other}); err != nil || g.Status != games.GameStateDisputed {
+		t.Errorf("Expected reports disagreeing on the generals killed to dispute the game, got %+v, %v", g, err)
+	}
+}
+
+// stubLimits is a VictoryPointLimits allowing the same maximum in every game, in a scenario called Test
+type stubLimits int
+
+func (s stubLimits) MaxVictoryPoints(_ context.Context, _ *model.Game) (int, string, error) {
+	return int(s), "Test", nil
+}
+
+func TestTxnControllerChecksVictoryPoints(t *testing.T) {
+	ctrl := NewTxnController(secondary.NewMemoryRepository(), stubLimits(12), nil)
+
+	tooMany := createFakeGame()
+	tooMany.Side2TotalVictoryPoints = 13
+	if _, err := ctrl.Create(nil, tooMany); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error creating a game over the limit, got %v", err)
+	}
+
+	g := createFakeGame()
+	g.Status = games.GameStateInProgress
+	g.Side1TotalVictoryPoints, g.Side2TotalVictoryPoints = 0, 0
+	g, err := ctrl.Create(nil, g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	replaced := *g
+	replaced.Side1TotalVictoryPoints = 14
+	if _, err = ctrl.Replace(nil, &replaced); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error replacing a game over the limit, got %v", err)
+	}
+	if _, err = ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: model.GameResult{Side2TotalVictoryPoints: 20}}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error reporting a result over the limit, got %v", err)
+	}
+	if _, err = settle(ctrl, g, model.GameResult{Side1TotalVictoryPoints: 12, Side2TotalVictoryPoints: 4}); err != nil {
+		t.Fatalf("Expected no error completing a game within the limit, got %v", err)
+	}
+}
+
+// stubArmyLists is an ArmyListLimits finding the army lists of the games with the given IDs over the limit
+type stubArmyLists map[games.GameID]bool
+
+func (s stubArmyLists) OverLimit(_ context.Context, g *model.Game) ([]string, error) {
+	if s[g.ID] {
+		return []string{"the list of side 1 costs 172 points, over the limit of 150"}, nil
+	}
+	return nil, nil
+}
+
+func TestTxnControllerChecksArmyLists(t *testing.T) {
+	lists := stubArmyLists{}
+	ctrl := NewTxnController(secondary.NewMemoryRepository(), nil, lists)
+	newGame := func() *model.Game {
+		g := createFakeGame()
+		g.Status = games.GameStateInProgress
+		g, err := ctrl.Create(nil, g)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		lists[g.ID] = true
+		return g
+	}
+
+	g := newGame()
+	if _, err := ctrl.SubmitReport(nil, g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: model.GameResult{Side1TotalVictoryPoints: 12}}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error reporting a game played with a list over the limit, got %v", err)
+	}
+
+	// Once the lists fit the result is taken
+	lists[g.ID] = false
+	if g, err := settle(ctrl, g, model.GameResult{Side1TotalVictoryPoints: 12}); err != nil || g.Status != games.GameStatePlayCompleted {
+		t.Errorf("Expected the game to be completed once the lists fit, got %+v, %v", g, err)
+	}
+}
+
+// settle completes the game by both of its sides reporting the same result
+func settle(ctrl *TxnController, g *model.Game, r model.GameResult) (*model.Game, error) {
+	if _, err := ctrl.SubmitReport(context.Background(), g.ID, &model.ResultReport{ReporterID: g.Side1ID, Result: r}); err != nil {
+		return nil, err
+	}
+	return ctrl.SubmitReport(context.Background(), g.ID, &model.ResultReport{ReporterID: g.Side2ID, Result: r})
+}
+
 func createFakeGame() *model.Game {
 	return &model.Game{
 		Side1ID:                 "123",
@@ -138,5 +547,5 @@ func createFakeGame() *model.Game {
 
 func createController() *TxnController {
 	repo := secondary.NewMemoryRepository()
-	return NewTxnController(repo)
+	return NewTxnController(repo, nil, nil)
 }
@@ -0,0 +1,66 @@
+// Package roundscenarios holds the adapter for the VictoryPointLimits port, which finds the limit for a game by
+// looking up the scenario of the game's round. It is kept apart from the other secondary adapters because the
+// rounds gateway it uses depends on the games service in turn.
+package roundscenarios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	roundsmodel "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	scenarios "github.com/rpatton4/mesbg-league/scenarios/pkg"
+	scenariosmodel "github.com/rpatton4/mesbg-league/scenarios/pkg/model"
+	"log/slog"
+)
+
+// roundSource is the part of the rounds gateway needed to find the scenario of a game's round
+type roundSource interface {
+	GetByID(ctx context.Context, id rounds.RoundID) (*roundsmodel.Round, error)
+}
+
+// scenarioSource is the part of the scenarios gateway needed to find the victory points of a scenario
+type scenarioSource interface {
+	GetByID(ctx context.Context, id scenarios.ScenarioID) (*scenariosmodel.Scenario, error)
+}
+
+// Limits is the VictoryPointLimits adapter which reads the limit from the scenario of the game's round
+type Limits struct {
+	rounds    roundSource
+	scenarios scenarioSource
+}
+
+// New creates the adapter, reading rounds and scenarios through the given gateways
+func New(rounds roundSource, scenarios scenarioSource) *Limits {
+	return &Limits{rounds: rounds, scenarios: scenarios}
+}
+
+// MaxVictoryPoints returns the most victory points one side can score in the scenario of the game's round, along
+// with the name of the scenario. Zero is returned when the game has no round, the round is unknown or it has no
+// scenario, as there is nothing to check the game against.
+func (l *Limits) MaxVictoryPoints(ctx context.Context, g *model.Game) (int, string, error) {
+	if g == nil || g.RoundID == "" {
+		return 0, "", nil
+	}
+
+	r, err := l.rounds.GetByID(ctx, g.RoundID)
+	if errors.Is(err, svcerrors.ErrNotFound) {
+		slog.Warn("Game refers to an unknown round, its result is not checked", "gameID", g.ID, "roundID", g.RoundID)
+		return 0, "", nil
+	} else if err != nil {
+		return 0, "", fmt.Errorf("unable to read round '%s': %w", g.RoundID, err)
+	} else if r.ScenarioID == "" {
+		return 0, "", nil
+	}
+
+	s, err := l.scenarios.GetByID(ctx, r.ScenarioID)
+	if errors.Is(err, svcerrors.ErrNotFound) {
+		slog.Warn("Round refers to an unknown scenario, its games are not checked", "roundID", r.ID, "scenarioID", r.ScenarioID)
+		return 0, "", nil
+	} else if err != nil {
+		return 0, "", fmt.Errorf("unable to read scenario '%s': %w", r.ScenarioID, err)
+	}
+	return s.MaxVictoryPoints, s.Name, nil
+}
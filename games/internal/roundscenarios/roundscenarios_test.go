@@ -0,0 +1,53 @@
+package roundscenarios
+
+import (
+	"context"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	roundsmodel "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	scenariosgateway "github.com/rpatton4/mesbg-league/scenarios/pkg/gateway"
+	"testing"
+)
+
+// stubRounds is a roundSource holding the given rounds by ID
+type stubRounds map[rounds.RoundID]*roundsmodel.Round
+
+func (s stubRounds) GetByID(_ context.Context, id rounds.RoundID) (*roundsmodel.Round, error) {
+	if r, ok := s[id]; ok {
+		return r, nil
+	}
+	return nil, svcerrors.ErrNotFound
+}
+
+func TestLimitsFromRoundScenario(t *testing.T) {
+	scenarios, err := scenariosgateway.NewDefaultInProcessGateway()
+	if err != nil {
+		t.Fatalf("Unable to load the scenarios: %v", err)
+	}
+	l := New(stubRounds{
+		"1": {ID: "1", ScenarioID: "domination"},
+		"2": {ID: "2"},
+		"3": {ID: "3", ScenarioID: "no-such-scenario"},
+	}, scenarios)
+
+	cases := []struct {
+		roundID  rounds.RoundID
+		most     int
+		scenario string
+	}{
+		{"1", 15, "Domination"},
+		{"2", 0, ""},
+		{"3", 0, ""},
+		{"999", 0, ""},
+		{"", 0, ""},
+	}
+	for _, c := range cases {
+		most, scenario, err := l.MaxVictoryPoints(context.Background(), &model.Game{RoundID: c.roundID})
+		if err != nil {
+			t.Errorf("Expected no error for round '%s', got %v", c.roundID, err)
+		} else if most != c.most || scenario != c.scenario {
+			t.Errorf("Expected a limit of %d from '%s' for round '%s', got %d from '%s'", c.most, c.scenario, c.roundID, most, scenario)
+		}
+	}
+}
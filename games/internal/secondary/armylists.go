@@ -0,0 +1,48 @@
+package secondary
+
+import (
+	"context"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	participants "github.com/rpatton4/mesbg-league/participants/pkg/model"
+)
+
+// ArmyListLimits defines the port for checking the army lists used in a game against the points limit of the game's
+// round, the lists being registered with the participants service and the limit set on the round by other services.
+type ArmyListLimits interface {
+	// OverLimit returns a description of each side of the game whose army list costs more than the points limit of
+	// the game's round. Nothing is returned when both lists fit or nothing limits them, such as when the round has
+	// no limit.
+	OverLimit(ctx context.Context, g *model.Game) ([]string, error)
+}
+
+// armyListSource is the part of the participants gateway needed to read the army lists of a game
+type armyListSource interface {
+	ArmyListsForGame(ctx context.Context, id pkg.GameID) (*participants.GameArmyLists, error)
+}
+
+// ParticipantArmyLists is the ArmyListLimits adapter which reads the army lists of a game from the participants
+// service, which checks each list against the points limit of the game's round as it reads it
+type ParticipantArmyLists struct {
+	participants armyListSource
+}
+
+// NewParticipantArmyLists creates the adapter, reading the army lists through the given participants gateway
+func NewParticipantArmyLists(participants armyListSource) *ParticipantArmyLists {
+	return &ParticipantArmyLists{participants: participants}
+}
+
+// OverLimit returns a description of each side of the game whose army list costs more than the points limit of the
+// game's round. A game without a round is not limited.
+func (a *ParticipantArmyLists) OverLimit(ctx context.Context, g *model.Game) ([]string, error) {
+	if g == nil || g.RoundID == "" {
+		return nil, nil
+	}
+
+	lists, err := a.participants.ArmyListsForGame(ctx, g.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the army lists of game '%s': %w", g.ID, err)
+	}
+	return lists.OverLimit, nil
+}
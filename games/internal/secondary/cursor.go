@@ -0,0 +1,40 @@
+package secondary
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+)
+
+// encodeCursor turns the ID of the last game in a page into the opaque cursor handed back to clients
+func encodeCursor(lastID pkg.GameID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastID))
+}
+
+// decodeCursor reverses encodeCursor, returning the ID of the last game on the previous page. An empty cursor
+// decodes to an empty ID, meaning start from the beginning.
+func decodeCursor(cursor string) (pkg.GameID, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(b) == 0 {
+		return "", fmt.Errorf("the cursor '%s' is malformed. Source: %w", cursor, svcerrors.ErrInvalidQuery)
+	}
+	return pkg.GameID(b), nil
+}
+
+// compareGameIDs gives the stable sort order used for game listings. IDs are generated from a counter, so shorter
+// IDs sort first and IDs of equal length sort lexically, which is numeric order for the generated IDs.
+func compareGameIDs(a, b pkg.GameID) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
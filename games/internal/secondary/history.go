@@ -0,0 +1,72 @@
+package secondary
+
+import (
+	"context"
+	"github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
+	"time"
+)
+
+// History defines the port for reading the history which every repository adapter keeps alongside the games. An
+// entry is added in the same write as every change to a game and is never changed afterwards.
+type History interface {
+	// History returns every entry recorded for the game with the given ID, oldest first. The history outlives the
+	// game, so it is still returned after the game is deleted. If nothing has been recorded for the ID it returns
+	// svcerrors.ErrNotFound.
+	History(ctx context.Context, id pkg.GameID) ([]model.HistoryEntry, error)
+}
+
+// changeReasonKey is the context key for the reason given for a write
+type changeReasonKey struct{}
+
+// WithChangeReason returns a copy of the context carrying why a write is being made, which is recorded in the
+// history of the game along with the actor from auth.Actor
+func WithChangeReason(ctx context.Context, reason string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, changeReasonKey{}, reason)
+}
+
+// changeReason returns the reason given for a write in the context, if any
+func changeReason(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	reason, _ := ctx.Value(changeReasonKey{}).(string)
+	return reason
+}
+
+// newHistoryEntry builds the history entry for the write which changed the game from before to after, before is
+// nil when the game was created and after is nil when it was purged
+func newHistoryEntry(ctx context.Context, before, after *model.Game) (model.HistoryEntry, error) {
+	changes, err := model.DiffGames(before, after)
+	if err != nil {
+		return model.HistoryEntry{}, err
+	}
+
+	e := model.HistoryEntry{
+		Action:  model.HistoryActionUpdated,
+		At:      time.Now().UTC(),
+		Actor:   auth.Actor(ctx),
+		Reason:  changeReason(ctx),
+		Changes: changes,
+	}
+	if after != nil {
+		e.GameID, e.Version = after.ID, after.Version
+	} else {
+		e.GameID, e.Version = before.ID, before.Version
+	}
+	switch {
+	case before == nil:
+		e.Action = model.HistoryActionCreated
+	case after == nil:
+		e.Action = model.HistoryActionPurged
+	case after.IsDeleted() && !before.IsDeleted():
+		e.Action = model.HistoryActionDeleted
+	case before.IsDeleted() && !after.IsDeleted():
+		e.Action = model.HistoryActionRestored
+	}
+	return e, nil
+}
@@ -2,14 +2,14 @@ package secondary
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/rpatton4/mesbg-league/games/pkg"
 	"github.com/rpatton4/mesbg-league/games/pkg/model"
 	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"slices"
 	"strconv"
-	"strings"
 	"sync"
+	"time"
 )
 
 var gameCounter = 1
@@ -18,21 +18,28 @@ var gameCounter = 1
 type MemoryRepository struct {
 	sync.RWMutex
 	data map[pkg.GameID]*model.Game
+
+	// outbox is written under the same lock as data, which is what makes recording the event atomic with the write
+	outbox    []OutboxEntry
+	outboxSeq int64
+
+	// history is kept per game and written under the same lock as data, like the outbox
+	history map[pkg.GameID][]model.HistoryEntry
 }
 
 // NewMemoryRepository creates a new instance of the in-memory game repository.
 func NewMemoryRepository() *MemoryRepository {
-	return &MemoryRepository{data: map[pkg.GameID]*model.Game{}}
+	return &MemoryRepository{data: map[pkg.GameID]*model.Game{}, history: map[pkg.GameID][]model.HistoryEntry{}}
 }
 
 // GetByID retrieves a game by ID from the in-memory repository, if no game with the given
-// ID exists, it returns ErrNotFound.
+// ID exists, or it has been deleted, it returns ErrNotFound.
 func (r *MemoryRepository) GetByID(_ context.Context, id pkg.GameID) (*model.Game, error) {
 	r.RLock()
 	defer r.RUnlock()
 
 	g, exists := r.data[id]
-	if !exists {
+	if !exists || g.IsDeleted() {
 		return nil, svcerrors.ErrNotFound
 	}
 
@@ -40,21 +47,20 @@ func (r *MemoryRepository) GetByID(_ context.Context, id pkg.GameID) (*model.Gam
 }
 
 // Create persists a new game instance to the in-memory repository and returns the game with an assigned ID.
-func (r *MemoryRepository) Create(_ context.Context, g *model.Game) (*model.Game, error) {
+func (r *MemoryRepository) Create(ctx context.Context, g *model.Game) (*model.Game, error) {
 	r.Lock()
 	defer r.Unlock()
 
-	if v, f, err := g.IsValid(); !v || err != nil {
-		if !v || errors.Is(err, svcerrors.ErrModelInvalid) {
-			return nil, fmt.Errorf("game %w "+strings.Join(f, "; "), svcerrors.ErrModelInvalid)
-		} else if err != nil {
-			return nil, err
-		}
-
-		return nil, fmt.Errorf("game %w: "+strings.Join(f, "; "), err)
+	if err := validateGame(g); err != nil {
+		return nil, err
 	}
 
 	g.ID = pkg.GameID(strconv.Itoa(gameCounter))
+	g.Version = 1
+	g.DeletedAt = time.Time{}
+	if err := r.record(ctx, nil, g); err != nil {
+		return nil, err
+	}
 	r.data[g.ID] = g
 	gameCounter++
 
@@ -63,37 +69,204 @@ func (r *MemoryRepository) Create(_ context.Context, g *model.Game) (*model.Game
 
 // Replace completely replaces an existing game instance with the provided one, using the ID from the provided game
 // to find which game to replace. This cannot be used to create a new Game, and it is an idempotent operation.
-// If the game is missing or invalid, this returns the appropriate svcerror
-func (r *MemoryRepository) Replace(_ context.Context, g *model.Game) (*model.Game, error) {
+// If the game is missing, invalid or based on an older version, this returns the appropriate svcerror
+func (r *MemoryRepository) Replace(ctx context.Context, g *model.Game) (*model.Game, error) {
 	r.Lock()
 	defer r.Unlock()
 
-	if v, m, err := g.IsValid(); !v || err != nil {
-		if !v || errors.Is(err, svcerrors.ErrModelInvalid) {
-			return nil, fmt.Errorf("game %w: "+strings.Join(m, "; "), err)
-		} else if err != nil {
-			return nil, err
-		}
+	if err := validateGame(g); err != nil {
+		return nil, err
 	} else if g.ID == "" {
 		return nil, fmt.Errorf("the game data sent with update is missing a game ID. Source: %w", svcerrors.ErrInvalidID)
-	} else if r.data[g.ID] == nil {
+	}
+
+	stored := r.data[g.ID]
+	if stored == nil || stored.IsDeleted() {
 		return nil, fmt.Errorf("the game with the given ID '%s' is not found. Source: %w", g.ID, svcerrors.ErrNotFound)
+	} else if err := checkVersion(g.ID, stored.Version, g.Version); err != nil {
+		return nil, err
 	}
 
+	given := g.Version
+	g.Version = stored.Version + 1
+	g.DeletedAt = time.Time{}
+	if err := r.record(ctx, stored, g); err != nil {
+		g.Version = given
+		return nil, err
+	}
 	r.data[g.ID] = g
 	return g, nil
 }
 
-// DeleteByID deletes an existing game instance in the in-memory repository. Returns true if the game was found and
-// deleted, false otherwise. This is an idempotent operation.
-func (r *MemoryRepository) DeleteByID(_ context.Context, id pkg.GameID) (bool, error) {
+// DeleteByID deletes an existing game instance in the in-memory repository by replacing it with its tombstone.
+// Returns true if the game was found and deleted, false otherwise. A version other than zero must be the stored one.
+func (r *MemoryRepository) DeleteByID(ctx context.Context, id pkg.GameID, version int64) (bool, error) {
 	r.Lock()
 	defer r.Unlock()
 
-	if r.data[id] != nil {
-		r.data[id] = nil
-		return true, nil
+	stored := r.data[id]
+	if stored == nil || stored.IsDeleted() {
+		return false, svcerrors.ErrNotFound
+	} else if err := checkVersion(id, stored.Version, version); err != nil {
+		return false, err
+	}
+
+	tombstone := *stored
+	tombstone.Version++
+	tombstone.DeletedAt = time.Now().UTC()
+	if err := r.record(ctx, stored, &tombstone); err != nil {
+		return false, err
+	}
+	r.data[id] = &tombstone
+	return true, nil
+}
+
+// Restore removes the tombstone of a deleted game, returning the game as it was before it was deleted
+func (r *MemoryRepository) Restore(ctx context.Context, id pkg.GameID) (*model.Game, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	stored := r.data[id]
+	if stored == nil || !stored.IsDeleted() {
+		return nil, fmt.Errorf("there is no deleted game with the ID '%s'. Source: %w", id, svcerrors.ErrNotFound)
+	}
+
+	restored := *stored
+	restored.Version++
+	restored.DeletedAt = time.Time{}
+	if err := r.record(ctx, stored, &restored); err != nil {
+		return nil, err
+	}
+	r.data[id] = &restored
+	return &restored, nil
+}
+
+// Purge removes every game deleted before the given time for good, returning how many were removed. The history of
+// each game records it being purged, and a GamePurged event is added to the outbox for it.
+func (r *MemoryRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	purged := 0
+	for id, g := range r.data {
+		if g.IsDeleted() && g.DeletedAt.Before(deletedBefore) {
+			if err := r.record(ctx, g, nil); err != nil {
+				return purged, err
+			}
+			delete(r.data, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// List returns one page of the games matching the query, ordered by ascending ID. Deleted games are skipped unless
+// the query includes them.
+func (r *MemoryRepository) List(_ context.Context, q model.GameQuery) (*model.GameList, error) {
+	after, err := decodeCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	matches := []*model.Game{}
+	for id, g := range r.data {
+		if (after != "" && compareGameIDs(id, after) <= 0) || !q.Matches(g) {
+			continue
+		}
+		matches = append(matches, g)
 	}
+	slices.SortFunc(matches, func(a, b *model.Game) int {
+		return compareGameIDs(a.ID, b.ID)
+	})
 
-	return false, svcerrors.ErrNotFound
+	limit := q.PageLimit()
+	result := &model.GameList{Games: []model.Game{}}
+	for i, g := range matches {
+		if i == limit {
+			result.NextCursor = encodeCursor(result.Games[limit-1].ID)
+			break
+		}
+		result.Games = append(result.Games, *g)
+	}
+
+	return result, nil
+}
+
+// PendingOutbox returns every outbox entry not yet relayed, in Seq order
+func (r *MemoryRepository) PendingOutbox(_ context.Context) ([]OutboxEntry, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	return slices.Clone(r.outbox), nil
+}
+
+// MarkRelayed removes the outbox entry with the given Seq once it has been delivered
+func (r *MemoryRepository) MarkRelayed(_ context.Context, seq int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	i, err := r.outboxIndex(seq)
+	if err != nil {
+		return err
+	}
+	r.outbox = slices.Delete(r.outbox, i, i+1)
+	return nil
+}
+
+// MarkFailed records a failed attempt at relaying the outbox entry with the given Seq
+func (r *MemoryRepository) MarkFailed(_ context.Context, seq int64, reason string, at time.Time) error {
+	r.Lock()
+	defer r.Unlock()
+
+	i, err := r.outboxIndex(seq)
+	if err != nil {
+		return err
+	}
+	r.outbox[i].Attempts++
+	r.outbox[i].LastError = reason
+	r.outbox[i].LastAttemptAt = at
+	return nil
+}
+
+// History returns every entry recorded for the game with the given ID, oldest first
+func (r *MemoryRepository) History(_ context.Context, id pkg.GameID) ([]model.HistoryEntry, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	entries, ok := r.history[id]
+	if !ok {
+		return nil, fmt.Errorf("no history for game '%s'. Source: %w", id, svcerrors.ErrNotFound)
+	}
+	return slices.Clone(entries), nil
+}
+
+// record adds the outbox and history entries for a write changing the game from before to after, the caller must
+// hold the lock
+func (r *MemoryRepository) record(ctx context.Context, before, after *model.Game) error {
+	e, err := newOutboxEntry(before, after)
+	if err != nil {
+		return err
+	}
+	h, err := newHistoryEntry(ctx, before, after)
+	if err != nil {
+		return err
+	}
+
+	r.outboxSeq++
+	e.Seq = r.outboxSeq
+	r.outbox = append(r.outbox, e)
+	r.history[h.GameID] = append(r.history[h.GameID], h)
+	return nil
+}
+
+// outboxIndex finds the outbox entry with the given Seq, the caller must hold the lock
+func (r *MemoryRepository) outboxIndex(seq int64) (int, error) {
+	i := slices.IndexFunc(r.outbox, func(e OutboxEntry) bool { return e.Seq == seq })
+	if i < 0 {
+		return -1, fmt.Errorf("no outbox entry with sequence %d. Source: %w", seq, svcerrors.ErrNotFound)
+	}
+	return i, nil
 }
@@ -0,0 +1,93 @@
+package secondary
+
+import (
+	"context"
+	"github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"time"
+)
+
+// OutboxEntry is an event recorded by a repository adapter in the same write as the change to the game it
+// describes, so the event is never lost between the write and publishing it. Entries stay in the outbox until
+// they have been relayed to the subscribers.
+type OutboxEntry struct {
+	// Seq orders the entries, it increases with every entry recorded
+	Seq int64 `json:"seq"`
+
+	// GameID identifies the game the event is about, the entries of one game are relayed in order
+	GameID pkg.GameID `json:"gameId"`
+
+	// Envelope is the event as it will be delivered, its ID stays the same however often it is retried
+	Envelope events.Envelope `json:"envelope"`
+
+	// Attempts is the number of failed attempts at relaying the entry so far
+	Attempts int `json:"attempts"`
+
+	// LastError is the error from the last failed attempt
+	LastError string `json:"lastError,omitempty"`
+
+	// LastAttemptAt is when the last failed attempt was made
+	LastAttemptAt time.Time `json:"lastAttemptAt,omitzero"`
+}
+
+// Outbox defines the port for reading the outbox which every repository adapter keeps alongside the games
+type Outbox interface {
+	// PendingOutbox returns every entry not yet relayed, in Seq order
+	PendingOutbox(ctx context.Context) ([]OutboxEntry, error)
+
+	// MarkRelayed removes the entry with the given Seq from the outbox once it has been delivered, returning
+	// svcerrors.ErrNotFound if there is no such entry
+	MarkRelayed(ctx context.Context, seq int64) error
+
+	// MarkFailed records a failed attempt at relaying the entry with the given Seq, returning svcerrors.ErrNotFound
+	// if there is no such entry
+	MarkFailed(ctx context.Context, seq int64, reason string, at time.Time) error
+}
+
+// changeEvent returns the event describing a write which changed the game from before to after, before is nil when
+// the game was created and after is nil when it was purged. Deleting a game writes its tombstone, which is described
+// as the game being deleted.
+func changeEvent(before, after *model.Game) events.Event {
+	switch {
+	case before == nil:
+		return events.GameCreated{Game: *after}
+	case after == nil:
+		return events.GamePurged{GameID: before.ID, RoundID: before.RoundID}
+	case after.IsDeleted() && !before.IsDeleted():
+		return events.GameDeleted{GameID: before.ID, RoundID: before.RoundID, Side1ID: before.Side1ID, Side2ID: before.Side2ID}
+	case before.IsDeleted() && !after.IsDeleted():
+		return events.GameRestored{Game: *after}
+	case after.Status == pkg.GameStatePlayCompleted && before.Status != pkg.GameStatePlayCompleted:
+		return events.GameCompleted{Game: *after}
+	default:
+		return events.GameUpdated{Game: *after, ReplacedSides: replacedSides(before, after)}
+	}
+}
+
+// replacedSides returns the sides of the game before a change which are no longer sides of it after
+func replacedSides(before, after *model.Game) []players.PlayerID {
+	var replaced []players.PlayerID
+	for _, side := range []players.PlayerID{before.Side1ID, before.Side2ID} {
+		if side != "" && side != after.Side1ID && side != after.Side2ID {
+			replaced = append(replaced, side)
+		}
+	}
+	return replaced
+}
+
+// newOutboxEntry builds the entry to record for the write which changed the game from before to after, the Seq is
+// left for the adapter to assign
+func newOutboxEntry(before, after *model.Game) (OutboxEntry, error) {
+	env, err := events.NewEnvelope(changeEvent(before, after))
+	if err != nil {
+		return OutboxEntry{}, err
+	}
+
+	g := before
+	if g == nil {
+		g = after
+	}
+	return OutboxEntry{GameID: g.ID, Envelope: env}, nil
+}
@@ -2,33 +2,122 @@ package secondary
 
 import (
 	"context"
+	"fmt"
 	"github.com/rpatton4/mesbg-league/games/pkg"
 	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"os"
+	"strings"
+	"time"
 )
 
-// Repository defines the port for writing Games to persistent storage
+// Repository defines the port for writing Games to persistent storage. Every write which changes a game also
+// records the event describing the change in the Outbox, and an entry in the History of the game, in the same
+// transaction.
 type Repository interface {
+	Outbox
+	History
+
 	// GetByID retrieves a game by ID from the repository, if no game with the given
-	// ID exists, it returns nil, svcerrors.ErrNotFound.
+	// ID exists, or it has been deleted, it returns nil, svcerrors.ErrNotFound.
 	GetByID(ctx context.Context, id pkg.GameID) (*model.Game, error)
 
-	// Create persists a new game instance to the repository and returns the game with an assigned ID.
+	// Create persists a new game instance to the repository and returns the game with an assigned ID, at version 1.
 	Create(ctx context.Context, g *model.Game) (*model.Game, error)
 
 	// Replace completely replaces an existing game instance with the provided one, using the ID from the provided game
 	// to find which game to replace. This cannot be used to create a new Game, and it is an idempotent operation.
 	// This is an intended equivalent to the HTTP PUT operation, though it purposefully does not allow the create which
 	// PUT is sometimes interpreted as allowing (because that leaves ID creation up to the client).
+	// If the game has a Version which is not the stored one, svcerrors.ErrVersionConflict is returned and nothing
+	// is written, otherwise the game is returned with its new version.
 	Replace(ctx context.Context, g *model.Game) (*model.Game, error)
 
-	// DeleteByID deletes an existing game instance in the repository. Returns true if the game was found and
-	// deleted, false otherwise. This is an idempotent operation.
-	DeleteByID(ctx context.Context, id pkg.GameID) (bool, error)
+	// DeleteByID deletes an existing game instance in the repository by writing its tombstone, after which the
+	// game is treated as missing by every other operation until it is restored. Returns true if the game was found
+	// and deleted, false otherwise. A version other than zero must be the stored one, svcerrors.ErrVersionConflict
+	// is returned otherwise.
+	DeleteByID(ctx context.Context, id pkg.GameID, version int64) (bool, error)
+
+	// Restore removes the tombstone of a deleted game, returning the game as it was before it was deleted at its
+	// next version. If there is no deleted game with the given ID, svcerrors.ErrNotFound is returned.
+	Restore(ctx context.Context, id pkg.GameID) (*model.Game, error)
+
+	// Purge removes every game deleted before the given time for good, returning how many were removed. Their
+	// history is kept and records them being purged, and a GamePurged event is added to the outbox for each.
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+
+	// List returns one page of the games matching the filters in the query, ordered by ascending ID so that paging
+	// with the returned cursor is stable. Deleted games are only included if the query asks for them. A malformed
+	// cursor returns svcerrors.ErrInvalidQuery.
+	List(ctx context.Context, q model.GameQuery) (*model.GameList, error)
+}
+
+const (
+	// RepositoryKindMemory selects the in-memory adapter, nothing survives a restart
+	RepositoryKindMemory = "memory"
+
+	// RepositoryKindSQLite selects the SQLite adapter, storing games in the file at RepositoryConfig.SQLitePath
+	RepositoryKindSQLite = "sqlite"
+)
+
+// RepositoryConfig holds the settings used to choose and set up the repository adapter
+type RepositoryConfig struct {
+	// Kind is one of the RepositoryKindXYZ constants, empty means RepositoryKindMemory
+	Kind string
+
+	// SQLitePath is the database file used by the SQLite adapter
+	SQLitePath string
+}
+
+// RepositoryConfigFromEnv reads the repository settings from the environment. GAMES_REPOSITORY selects the adapter
+// ("memory" or "sqlite") and GAMES_SQLITE_PATH sets the database file, defaulting to games.db in the working directory.
+func RepositoryConfigFromEnv() RepositoryConfig {
+	cfg := RepositoryConfig{
+		Kind:       os.Getenv("GAMES_REPOSITORY"),
+		SQLitePath: os.Getenv("GAMES_SQLITE_PATH"),
+	}
+	if cfg.SQLitePath == "" {
+		cfg.SQLitePath = "games.db"
+	}
+	return cfg
+}
+
+// NewRepository creates the repository adapter selected by the given configuration
+func NewRepository(cfg RepositoryConfig) (Repository, error) {
+	switch strings.ToLower(cfg.Kind) {
+	case "", RepositoryKindMemory:
+		return NewMemoryRepository(), nil
+	case RepositoryKindSQLite:
+		return NewSQLiteRepository(cfg.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unknown games repository kind '%s'", cfg.Kind)
+	}
 }
 
 // NewDefaultRepository creates an instance of the default repository implementation. This default is controlled
-// by configuration.
-// TODO: Implement the configuration control for this
-func NewDefaultRepository() Repository {
-	return NewMemoryRepository()
+// by configuration, see RepositoryConfigFromEnv.
+func NewDefaultRepository() (Repository, error) {
+	return NewRepository(RepositoryConfigFromEnv())
+}
+
+// validateGame checks the game before it is written by any adapter, returning an error wrapping
+// svcerrors.ErrModelInvalid or svcerrors.ErrModelMissing if it cannot be stored.
+func validateGame(g *model.Game) error {
+	v, f, err := g.IsValid()
+	if err != nil {
+		return err
+	} else if !v {
+		return fmt.Errorf("game %w: %s", svcerrors.ErrModelInvalid, strings.Join(f, "; "))
+	}
+	return nil
+}
+
+// checkVersion returns svcerrors.ErrVersionConflict if a write based on the given version of the game cannot be
+// applied to the stored version, a given version of zero always can
+func checkVersion(id pkg.GameID, stored, given int64) error {
+	if given != 0 && given != stored {
+		return fmt.Errorf("game '%s' is at version %d, not %d. Source: %w", id, stored, given, svcerrors.ErrVersionConflict)
+	}
+	return nil
 }
@@ -0,0 +1,545 @@
+package secondary
+
+import (
+	"context"
+	"errors"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// runRepositoryConformance runs the behavior every adapter of the Repository port has to share against the
+// repositories created by newRepo, which is called once for each case so that every case starts empty.
+func runRepositoryConformance(t *testing.T, newRepo func(t *testing.T) Repository) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, r Repository)
+	}{
+		{"AddGame", testRepoAddGame},
+		{"GetById", testRepoGetById},
+		{"ReplaceGame", testRepoReplaceGame},
+		{"DeleteGame", testRepoDeleteGame},
+		{"ListGames", testRepoListGames},
+		{"Outbox", testRepoOutbox},
+		{"Versions", testRepoVersions},
+		{"Reports", testRepoReports},
+		{"Breakdowns", testRepoBreakdowns},
+		{"History", testRepoHistory},
+		{"SoftDelete", testRepoSoftDelete},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, newRepo(t))
+		})
+	}
+}
+
+func testRepoAddGame(t *testing.T, r Repository) {
+	g := createFakeGame()
+
+	g, err := r.Create(context.Background(), g)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if g.ID == games.GameID("") {
+		t.Errorf("Expected game ID to be assigned, got 0")
+	}
+}
+
+func testRepoGetById(t *testing.T, r Repository) {
+	// Create the game to search for
+	g := createFakeGame()
+
+	g, err := r.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if g.ID == games.GameID("") {
+		t.Errorf("Expected game ID to be assigned, got 0")
+	}
+
+	// Search for it
+	result, err := r.GetByID(context.Background(), g.ID)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if result.ID != g.ID {
+		t.Errorf("Expected game IDs to match, they didn't")
+	}
+}
+
+func testRepoReplaceGame(t *testing.T, r Repository) {
+	originalScore := 20
+	updatedScore := 30
+	originalRoundID := rounds.RoundID("999")
+
+	// Create the game to search for
+	g := createFakeGame()
+	g.Side1TotalVictoryPoints = originalScore
+	g.RoundID = originalRoundID
+
+	g, err := r.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if g.ID == games.GameID("") {
+		t.Errorf("Expected game ID to be assigned, got 0")
+	}
+
+	g.Side1TotalVictoryPoints = updatedScore
+	g.RoundID = "" // Clear out the round ID to put it back to unset
+
+	g2, err2 := r.Replace(context.Background(), g)
+	if err2 != nil {
+		t.Fatalf("Expected no error, got %v", err2)
+	}
+	if g2.Side1TotalVictoryPoints != updatedScore {
+		t.Errorf("Expected score to be updated and it wasn't ")
+	}
+	if g2.RoundID != "" {
+		t.Errorf("Expected RoundID to be un-set and it wasn't ")
+	}
+
+	g3, err3 := r.GetByID(context.Background(), g2.ID)
+	if err3 != nil {
+		t.Fatalf("Expected no error, got %v", err3)
+	}
+	if g3.Side1TotalVictoryPoints != updatedScore {
+		t.Errorf("Expected queried score to be updated and it wasn't ")
+	}
+	if g3.RoundID != "" {
+		t.Errorf("Expected queried RoundID to be un-set and it wasn't ")
+	}
+}
+
+func testRepoDeleteGame(t *testing.T, r Repository) {
+	// Create the game to delete
+	g := createFakeGame()
+	g, err := r.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if g.ID == games.GameID("") {
+		t.Errorf("Expected game ID to be assigned, got 0")
+	}
+
+	success, err := r.DeleteByID(context.Background(), g.ID, 0)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !success {
+		t.Errorf("Expected delete to return true, got false")
+	}
+
+	// now delete it again, it should fail
+	success, err = r.DeleteByID(context.Background(), g.ID, 0)
+	if err == nil {
+		t.Fatalf("Expected error while deleting non-existent game, but it succeeded")
+	}
+
+	if success {
+		t.Errorf("Expected delete to return false, got true")
+	}
+}
+
+func testRepoListGames(t *testing.T, r Repository) {
+	for i := 0; i < 5; i++ {
+		g := createFakeGame()
+		if i%2 == 0 {
+			g.RoundID = "round-a"
+		} else {
+			g.RoundID = "round-b"
+			g.Side2ID = "777"
+			g.Status = games.GameStateInProgress
+		}
+		if _, err := r.Create(context.Background(), g); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Filter by round
+	l, err := r.List(context.Background(), model.GameQuery{RoundID: "round-a"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(l.Games) != 3 {
+		t.Errorf("Expected 3 games in round-a, got %d", len(l.Games))
+	}
+
+	// Filter by either side
+	l, err = r.List(context.Background(), model.GameQuery{SideID: "777"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(l.Games) != 2 {
+		t.Errorf("Expected 2 games with player 777, got %d", len(l.Games))
+	}
+
+	// Filter by status
+	l, err = r.List(context.Background(), model.GameQuery{Statuses: []games.GameState{games.GameStatePlayCompleted}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(l.Games) != 3 {
+		t.Errorf("Expected 3 completed games, got %d", len(l.Games))
+	}
+
+	// Page through everything two at a time, the order should be stable and nothing repeated
+	seen := map[games.GameID]bool{}
+	var last games.GameID
+	q := model.GameQuery{Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("Expected paging to finish after 3 pages")
+		}
+		l, err = r.List(context.Background(), q)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, g := range l.Games {
+			if seen[g.ID] {
+				t.Errorf("Game %s was returned more than once", g.ID)
+			}
+			if last != "" && compareGameIDs(last, g.ID) >= 0 {
+				t.Errorf("Expected games in ascending ID order, got %s after %s", g.ID, last)
+			}
+			seen[g.ID] = true
+			last = g.ID
+		}
+		if l.NextCursor == "" {
+			break
+		}
+		q.Cursor = l.NextCursor
+	}
+	if len(seen) != 5 {
+		t.Errorf("Expected to page through 5 games, got %d", len(seen))
+	}
+
+	// A cursor which can't be decoded is rejected
+	if _, err = r.List(context.Background(), model.GameQuery{Cursor: "!!not a cursor!!"}); err == nil {
+		t.Errorf("Expected error for a malformed cursor, got nil")
+	}
+}
+
+func testRepoVersions(t *testing.T, r Repository) {
+	g, err := r.Create(context.Background(), createFakeGame())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if g.Version != 1 {
+		t.Errorf("Expected a new game to be at version 1, got %d", g.Version)
+	}
+
+	stale := *g
+	updated := *g
+	updated.Side1TotalVictoryPoints = 1
+	if result, err := r.Replace(context.Background(), &updated); err != nil || result.Version != 2 {
+		t.Fatalf("Expected the game to move to version 2, got %+v, %v", result, err)
+	}
+
+	stale.Side1TotalVictoryPoints = 2
+	if _, err = r.Replace(context.Background(), &stale); !errors.Is(err, svcerrors.ErrVersionConflict) {
+		t.Errorf("Expected version conflict replacing from version 1, got %v", err)
+	}
+	if stale.Version != 1 {
+		t.Errorf("Expected a rejected game to keep the version it was sent with, got %d", stale.Version)
+	}
+	if ok, err := r.DeleteByID(context.Background(), g.ID, 1); ok || !errors.Is(err, svcerrors.ErrVersionConflict) {
+		t.Errorf("Expected version conflict deleting version 1, got %v, %v", ok, err)
+	}
+
+	// Version zero skips the check
+	unchecked := updated
+	unchecked.Version = 0
+	unchecked.Side1TotalVictoryPoints = 3
+	if result, err := r.Replace(context.Background(), &unchecked); err != nil || result.Version != 3 {
+		t.Fatalf("Expected an unchecked replace to move the game to version 3, got %+v, %v", result, err)
+	}
+	if result, _ := r.GetByID(context.Background(), g.ID); result.Side1TotalVictoryPoints != 3 || result.Version != 3 {
+		t.Errorf("Expected the stored game to be at version 3, got %+v", result)
+	}
+	if ok, err := r.DeleteByID(context.Background(), g.ID, 3); !ok || err != nil {
+		t.Errorf("Expected the current version to be deleted, got %v, %v", ok, err)
+	}
+}
+
+func testRepoOutbox(t *testing.T, r Repository) {
+	g := createFakeGame()
+	g.Status = games.GameStateInProgress
+	g, err := r.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	completed := *g
+	completed.Status = games.GameStatePlayCompleted
+	if _, err = r.Replace(context.Background(), &completed); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = r.DeleteByID(context.Background(), g.ID, 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Failed writes record nothing
+	_, _ = r.Create(context.Background(), &model.Game{})
+	_, _ = r.Replace(context.Background(), &completed)
+	_, _ = r.DeleteByID(context.Background(), g.ID, 0)
+
+	pending, err := r.PendingOutbox(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []events.Type{events.TypeGameCreated, events.TypeGameCompleted, events.TypeGameDeleted}
+	if len(pending) != len(want) {
+		t.Fatalf("Expected %d outbox entries, got %+v", len(want), pending)
+	}
+	for i, e := range pending {
+		if e.Envelope.Type != want[i] || e.GameID != g.ID || e.Envelope.ID == "" || (i > 0 && e.Seq <= pending[i-1].Seq) {
+			t.Errorf("Expected entry %d to be a %s event for game %s in sequence, got %+v", i, want[i], g.ID, e)
+		}
+	}
+	deleted, err := events.Decode[events.GameDeleted](pending[2].Envelope)
+	if err != nil || deleted.RoundID != g.RoundID || deleted.Side1ID != g.Side1ID || deleted.Side2ID != g.Side2ID {
+		t.Errorf("Expected the deleted event to carry the round and sides of the game, got %+v, %v", deleted, err)
+	}
+
+	at := time.Now().UTC().Truncate(time.Millisecond)
+	if err = r.MarkFailed(context.Background(), pending[0].Seq, "subscriber is down", at); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err = r.MarkRelayed(context.Background(), pending[1].Seq); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pending, _ = r.PendingOutbox(context.Background())
+	if len(pending) != 2 || pending[0].Attempts != 1 || pending[0].LastError != "subscriber is down" || !pending[0].LastAttemptAt.Equal(at) {
+		t.Errorf("Expected the failed attempt to be recorded and the relayed entry removed, got %+v", pending)
+	}
+
+	if err = r.MarkRelayed(context.Background(), 9999); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown entry, got %v", err)
+	}
+}
+
+func testRepoReports(t *testing.T, r Repository) {
+	g := createFakeGame()
+	g.Status = games.GameStateDisputed
+	submitted := time.Date(2025, 3, 1, 18, 30, 0, 0, time.UTC)
+	g.Reports = []model.ResultReport{
+		{ReporterID: g.Side1ID, Result: model.GameResult{Side1TotalVictoryPoints: 10, Side2TotalVictoryPoints: 15}, SubmittedAt: submitted},
+		{ReporterID: g.Side2ID, Result: model.GameResult{Side1TotalVictoryPoints: 8, Side2TotalVictoryPoints: 15, Side2KilledGeneral: true}, SubmittedAt: submitted},
+	}
+	g, err := r.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stored, err := r.GetByID(context.Background(), g.ID)
+	if err != nil || len(stored.Reports) != 2 || stored.ReportBy(g.Side2ID) == nil || !stored.ReportBy(g.Side2ID).Result.Side2KilledGeneral ||
+		!stored.Reports[0].SubmittedAt.Equal(submitted) || stored.Resolution != nil {
+		t.Fatalf("Expected both reports to be stored without a resolution, got %+v, %v", stored, err)
+	}
+
+	resolved := *stored
+	resolved.Status = games.GameStatePlayCompleted
+	resolved.Resolution = &model.Resolution{ResolvedBy: "1", Reason: "photo of the table", ResolvedAt: submitted}
+	if _, err = r.Replace(context.Background(), &resolved); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, err = r.GetByID(context.Background(), g.ID)
+	if err != nil || stored.Resolution == nil || stored.Resolution.Reason != "photo of the table" || len(stored.Reports) != 2 {
+		t.Errorf("Expected the resolution to be stored alongside the reports, got %+v, %v", stored, err)
+	}
+
+	// Reports can only come from the sides, once each
+	invalid := *stored
+	invalid.Reports = append(slices.Clone(stored.Reports), model.ResultReport{ReporterID: "not-a-side"})
+	if _, err = r.Replace(context.Background(), &invalid); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error for a report from outside the game, got %v", err)
+	}
+}
+
+func testRepoBreakdowns(t *testing.T, r Repository) {
+	g := createFakeGame()
+	g.Side1TotalVictoryPoints, g.Side2TotalVictoryPoints = 9, 3
+	g.Side1Breakdown = &model.SideBreakdown{VictoryPoints: map[string]int{"objectives": 6, "heroes": 3}, HeroesSlain: []string{"Gothmog"}, ModelsRemoved: 12}
+	g, err := r.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stored, err := r.GetByID(context.Background(), g.ID)
+	if err != nil || stored.Side2Breakdown != nil || !reflect.DeepEqual(stored.Side1Breakdown, g.Side1Breakdown) {
+		t.Fatalf("Expected only the breakdown of side 1 to be stored, got %+v, %v", stored, err)
+	}
+
+	broken := *stored
+	broken.Side2Breakdown = &model.SideBreakdown{VictoryPoints: map[string]int{"objectives": 3}, ModelsRemoved: 4, Broken: true}
+	if _, err = r.Replace(context.Background(), &broken); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored, err = r.GetByID(context.Background(), g.ID); err != nil || stored.Side2Breakdown == nil || !stored.Side2Breakdown.Broken {
+		t.Errorf("Expected the breakdown of side 2 to be stored, got %+v, %v", stored, err)
+	}
+
+	// The categories of a breakdown have to add up to the total of the side
+	invalid := *stored
+	invalid.Side1TotalVictoryPoints = 12
+	if _, err = r.Replace(context.Background(), &invalid); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error for a breakdown which does not add up, got %v", err)
+	}
+}
+
+func testRepoHistory(t *testing.T, r Repository) {
+	if _, err := r.History(context.Background(), "does-not-exist"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error for a game with no history, got %v", err)
+	}
+
+	g, err := r.Create(auth.WithActor(context.Background(), "organizer"), createFakeGame())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	updated := *g
+	updated.Side1TotalVictoryPoints = 12
+	ctx := WithChangeReason(auth.WithActor(context.Background(), "player-123"), "miscounted the objectives")
+	if _, err = r.Replace(ctx, &updated); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = r.DeleteByID(context.Background(), g.ID, 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The history outlives the game
+	entries, err := r.History(context.Background(), g.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 history entries, got %+v", entries)
+	}
+	if entries[0].Action != model.HistoryActionCreated || entries[0].Actor != "organizer" || entries[0].Version != 1 || len(entries[0].Changes) == 0 {
+		t.Errorf("Expected the creation by the organizer first, got %+v", entries[0])
+	}
+
+	changed := entries[1]
+	if changed.Action != model.HistoryActionUpdated || changed.Actor != "player-123" || changed.Reason != "miscounted the objectives" ||
+		changed.Version != 2 || changed.At.IsZero() {
+		t.Errorf("Expected the update with its actor and reason second, got %+v", changed)
+	}
+	if len(changed.Changes) != 1 || changed.Changes[0].Field != "side1TotalVictoryPoints" ||
+		string(changed.Changes[0].From) != "10" || string(changed.Changes[0].To) != "12" {
+		t.Errorf("Expected only the victory points to have changed from 10 to 12, got %+v", changed.Changes)
+	}
+
+	if entries[2].Action != model.HistoryActionDeleted || entries[2].Version != 3 || entries[2].Actor != "" ||
+		len(entries[2].Changes) != 1 || entries[2].Changes[0].Field != "deletedAt" {
+		t.Errorf("Expected the anonymous deletion writing the tombstone last, got %+v", entries[2])
+	}
+}
+
+func testRepoSoftDelete(t *testing.T, r Repository) {
+	g, err := r.Create(context.Background(), createFakeGame())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	kept, err := r.Create(context.Background(), createFakeGame())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = r.Restore(context.Background(), g.ID); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error restoring a game which is not deleted, got %v", err)
+	}
+	if ok, err := r.DeleteByID(context.Background(), g.ID, 1); !ok || err != nil {
+		t.Fatalf("Expected the game to be deleted, got %v, %v", ok, err)
+	}
+
+	// The tombstone hides the game from everything but listings asking for it
+	if result, err := r.GetByID(context.Background(), g.ID); result != nil || !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected a deleted game to be not found, got %+v, %v", result, err)
+	}
+	if _, err = r.Replace(context.Background(), g); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error replacing a deleted game, got %v", err)
+	}
+	if l, err := r.List(context.Background(), model.GameQuery{}); err != nil || len(l.Games) != 1 || l.Games[0].ID != kept.ID {
+		t.Errorf("Expected only the game which was kept to be listed, got %+v, %v", l, err)
+	}
+	l, err := r.List(context.Background(), model.GameQuery{IncludeDeleted: true})
+	if err != nil || len(l.Games) != 2 || !l.Games[0].IsDeleted() || l.Games[1].IsDeleted() {
+		t.Errorf("Expected the deleted game to be listed when asked for, got %+v, %v", l, err)
+	}
+
+	restored, err := r.Restore(context.Background(), g.ID)
+	if err != nil {
+		t.Fatalf("Expected no error restoring the game, got %v", err)
+	}
+	if restored.IsDeleted() || restored.Version != 3 || restored.Side1TotalVictoryPoints != g.Side1TotalVictoryPoints {
+		t.Errorf("Expected the game back as it was at version 3, got %+v", restored)
+	}
+	if result, err := r.GetByID(context.Background(), g.ID); err != nil || result.IsDeleted() {
+		t.Errorf("Expected the restored game to be found, got %+v, %v", result, err)
+	}
+
+	pending, err := r.PendingOutbox(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if last := pending[len(pending)-1].Envelope.Type; pending[len(pending)-2].Envelope.Type != events.TypeGameDeleted || last != events.TypeGameRestored {
+		t.Errorf("Expected the delete and restore to be in the outbox, got %+v", pending)
+	}
+
+	// Only tombstones older than the cutoff are purged
+	if _, err = r.DeleteByID(context.Background(), g.ID, 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n, err := r.Purge(context.Background(), time.Now().Add(-time.Hour)); n != 0 || err != nil {
+		t.Errorf("Expected nothing deleted over an hour ago to be purged, got %d, %v", n, err)
+	}
+	if n, err := r.Purge(context.Background(), time.Now().Add(time.Second)); n != 1 || err != nil {
+		t.Errorf("Expected the deleted game to be purged, got %d, %v", n, err)
+	}
+	if _, err = r.Restore(context.Background(), g.ID); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error restoring a purged game, got %v", err)
+	}
+	if l, err := r.List(context.Background(), model.GameQuery{IncludeDeleted: true}); err != nil || len(l.Games) != 1 {
+		t.Errorf("Expected only the game which was kept to remain, got %+v, %v", l, err)
+	}
+	entries, err := r.History(context.Background(), g.ID)
+	if err != nil || len(entries) != 5 || entries[4].Action != model.HistoryActionPurged {
+		t.Errorf("Expected the history of the purged game to be kept and record it being purged, got %+v, %v", entries, err)
+	}
+	pending, err = r.PendingOutbox(context.Background())
+	if err != nil || len(pending) == 0 || pending[len(pending)-1].Envelope.Type != events.TypeGamePurged || pending[len(pending)-1].GameID != g.ID {
+		t.Errorf("Expected the purge to be recorded in the outbox, got %+v, %v", pending, err)
+	}
+	purgedEvents := 0
+	for _, e := range pending {
+		if e.Envelope.Type == events.TypeGamePurged {
+			purgedEvents++
+		}
+	}
+	if purgedEvents != 1 {
+		t.Errorf("Expected one GamePurged event for the one purged game, got %d", purgedEvents)
+	}
+}
+
+func createFakeGame() *model.Game {
+	return &model.Game{
+		Side1ID:                 "123",
+		Side2ID:                 "456",
+		RoundID:                 "789",
+		Side1TotalVictoryPoints: 10,
+		Side2TotalVictoryPoints: 15,
+		Side1KilledGeneral:      true,
+		Side2KilledGeneral:      true,
+		Status:                  games.GameStatePlayCompleted,
+	}
+}
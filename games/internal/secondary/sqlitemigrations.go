@@ -0,0 +1,111 @@
+package secondary
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// sqliteMigrations holds the schema changes for the SQLite adapter, in order. The position in the slice (starting
+// at 1) is the schema version, so migrations must only ever be appended, never edited or reordered once released.
+var sqliteMigrations = []string{
+	// 1: the games table, with indexes for the listing filters
+	`CREATE TABLE games (
+		id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+		side1_id             TEXT    NOT NULL,
+		side2_id             TEXT    NOT NULL DEFAULT '',
+		round_id             TEXT    NOT NULL DEFAULT '',
+		side1_victory_points INTEGER NOT NULL DEFAULT 0,
+		side2_victory_points INTEGER NOT NULL DEFAULT 0,
+		side1_killed_general INTEGER NOT NULL DEFAULT 0,
+		side2_killed_general INTEGER NOT NULL DEFAULT 0,
+		status               INTEGER NOT NULL DEFAULT 0,
+		conceding_side_id    TEXT    NOT NULL DEFAULT ''
+	);
+	CREATE INDEX games_round_id ON games (round_id);
+	CREATE INDEX games_side1_id ON games (side1_id);
+	CREATE INDEX games_side2_id ON games (side2_id);
+	CREATE INDEX games_status ON games (status);`,
+
+	// 2: the outbox, holding the events for game changes until they have been relayed
+	`CREATE TABLE games_outbox (
+		seq             INTEGER PRIMARY KEY AUTOINCREMENT,
+		game_id         TEXT    NOT NULL,
+		event_id        TEXT    NOT NULL,
+		event_type      TEXT    NOT NULL,
+		occurred_at     TEXT    NOT NULL,
+		payload         BLOB    NOT NULL,
+		attempts        INTEGER NOT NULL DEFAULT 0,
+		last_error      TEXT    NOT NULL DEFAULT '',
+		last_attempt_at TEXT    NOT NULL DEFAULT ''
+	);`,
+
+	// 3: the version of each game, for rejecting writes based on an out of date copy
+	`ALTER TABLE games ADD COLUMN version INTEGER NOT NULL DEFAULT 1;`,
+
+	// 4: the result reports of each side and any organizer resolution, as JSON since they are always read and
+	// written along with their game
+	`ALTER TABLE games ADD COLUMN reports TEXT NOT NULL DEFAULT '';
+	ALTER TABLE games ADD COLUMN resolution TEXT NOT NULL DEFAULT '';`,
+
+	// 5: the history of every write to each game, kept after the game is deleted
+	`CREATE TABLE games_history (
+		seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+		game_id TEXT    NOT NULL,
+		version INTEGER NOT NULL,
+		action  TEXT    NOT NULL,
+		at      TEXT    NOT NULL,
+		actor   TEXT    NOT NULL DEFAULT '',
+		reason  TEXT    NOT NULL DEFAULT '',
+		changes BLOB    NOT NULL
+	);
+	CREATE INDEX games_history_game_id ON games_history (game_id);`,
+
+	// 6: the tombstone of each deleted game, kept until the game is purged
+	`ALTER TABLE games ADD COLUMN deleted_at TEXT NOT NULL DEFAULT '';
+	CREATE INDEX games_deleted_at ON games (deleted_at);`,
+
+	// 7: the optional breakdown of each side's result, as JSON in the same way as the reports
+	`ALTER TABLE games ADD COLUMN side1_breakdown TEXT NOT NULL DEFAULT '';
+	ALTER TABLE games ADD COLUMN side2_breakdown TEXT NOT NULL DEFAULT '';`,
+}
+
+// migrateSQLite brings the schema of the given database up to date, applying each outstanding migration in its own
+// transaction and recording it in the schema_migrations table.
+func migrateSQLite(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT    NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("unable to create the schema_migrations table: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("unable to read the current schema version: %w", err)
+	}
+
+	for i := current; i < len(sqliteMigrations); i++ {
+		version := i + 1
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("unable to apply schema migration %d: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("unable to record schema migration %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("unable to commit schema migration %d: %w", version, err)
+		}
+		slog.Info("Applied games schema migration", "version", version)
+	}
+
+	return nil
+}
@@ -0,0 +1,577 @@
+package secondary
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"strconv"
+	"strings"
+	"time"
+
+	// Pure Go SQLite driver, registered as "sqlite", so the service builds without cgo
+	_ "modernc.org/sqlite"
+)
+
+// gameColumns is the column list used when reading games, in the order expected by scanGame
+const gameColumns = `id, side1_id, side2_id, round_id, side1_victory_points, side2_victory_points,
+	side1_killed_general, side2_killed_general, side1_breakdown, side2_breakdown, status, conceding_side_id, version,
+	reports, resolution, deleted_at`
+
+// tombstoneLayout is used for the deleted_at column, with a fixed number of fractional digits so that the tombstones
+// sort in time order as text
+const tombstoneLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// SQLiteRepository defines a repository (adapter) for the Games service which stores games in a SQLite database
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository opens (creating if needed) the SQLite database at the given path and migrates its schema to
+// the latest version. Use ":memory:" for a database which only lasts as long as the repository.
+func NewSQLiteRepository(path string) (*SQLiteRepository, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the games database '%s': %w", path, err)
+	}
+
+	// SQLite only allows one writer at a time, and every connection to ":memory:" is a separate database, so a
+	// single connection keeps both cases simple
+	db.SetMaxOpenConns(1)
+
+	if err := migrateSQLite(context.Background(), db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &SQLiteRepository{db: db}, nil
+}
+
+// Close releases the database held by the repository
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+// GetByID retrieves a game by ID from the database, if no game with the given ID exists, or it has been deleted,
+// it returns svcerrors.ErrNotFound.
+func (r *SQLiteRepository) GetByID(ctx context.Context, id pkg.GameID) (*model.Game, error) {
+	g, err := getGame(ctx, r.db, id)
+	if err != nil {
+		return nil, err
+	} else if g.IsDeleted() {
+		return nil, svcerrors.ErrNotFound
+	}
+	return g, nil
+}
+
+// Create persists a new game instance to the database and returns the game with an assigned ID.
+func (r *SQLiteRepository) Create(ctx context.Context, g *model.Game) (*model.Game, error) {
+	if err := validateGame(g); err != nil {
+		return nil, err
+	}
+
+	reports, resolution, err := encodeReports(g)
+	if err != nil {
+		return nil, err
+	}
+	breakdown1, breakdown2, err := encodeBreakdowns(g)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `INSERT INTO games (side1_id, side2_id, round_id, side1_victory_points,
+			side2_victory_points, side1_killed_general, side2_killed_general, side1_breakdown, side2_breakdown, status,
+			conceding_side_id, reports, resolution) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			string(g.Side1ID), string(g.Side2ID), string(g.RoundID), g.Side1TotalVictoryPoints, g.Side2TotalVictoryPoints,
+			g.Side1KilledGeneral, g.Side2KilledGeneral, breakdown1, breakdown2, int(g.Status), string(g.ConcedingSideID),
+			reports, resolution)
+		if err != nil {
+			return fmt.Errorf("unable to insert game: %w", err)
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("unable to read the ID of the new game: %w", err)
+		}
+		g.ID = pkg.GameID(strconv.FormatInt(id, 10))
+		g.Version = 1
+		g.DeletedAt = time.Time{}
+		return recordChange(ctx, tx, nil, g)
+	})
+	if err != nil {
+		g.ID, g.Version = "", 0
+		return nil, err
+	}
+	return g, nil
+}
+
+// Replace completely replaces an existing game instance with the provided one, using the ID from the provided game
+// to find which game to replace. This cannot be used to create a new Game, and it is an idempotent operation.
+// If the game is missing, invalid or based on an older version, this returns the appropriate svcerror
+func (r *SQLiteRepository) Replace(ctx context.Context, g *model.Game) (*model.Game, error) {
+	if err := validateGame(g); err != nil {
+		return nil, err
+	} else if g.ID == "" {
+		return nil, fmt.Errorf("the game data sent with update is missing a game ID. Source: %w", svcerrors.ErrInvalidID)
+	}
+
+	reports, resolution, err := encodeReports(g)
+	if err != nil {
+		return nil, err
+	}
+	breakdown1, breakdown2, err := encodeBreakdowns(g)
+	if err != nil {
+		return nil, err
+	}
+
+	given := g.Version
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := getGame(ctx, tx, g.ID)
+		if errors.Is(err, svcerrors.ErrNotFound) || (err == nil && before.IsDeleted()) {
+			return fmt.Errorf("the game with the given ID '%s' is not found. Source: %w", g.ID, svcerrors.ErrNotFound)
+		} else if err != nil {
+			return err
+		} else if err = checkVersion(g.ID, before.Version, given); err != nil {
+			return err
+		}
+
+		g.Version = before.Version + 1
+		g.DeletedAt = time.Time{}
+		if _, err = tx.ExecContext(ctx, `UPDATE games SET side1_id = ?, side2_id = ?, round_id = ?, side1_victory_points = ?,
+			side2_victory_points = ?, side1_killed_general = ?, side2_killed_general = ?, side1_breakdown = ?,
+			side2_breakdown = ?, status = ?, conceding_side_id = ?, version = ?, reports = ?, resolution = ? WHERE id = ?`,
+			string(g.Side1ID), string(g.Side2ID), string(g.RoundID), g.Side1TotalVictoryPoints, g.Side2TotalVictoryPoints,
+			g.Side1KilledGeneral, g.Side2KilledGeneral, breakdown1, breakdown2, int(g.Status), string(g.ConcedingSideID),
+			g.Version, reports, resolution, string(g.ID)); err != nil {
+			return fmt.Errorf("unable to update game '%s': %w", g.ID, err)
+		}
+		return recordChange(ctx, tx, before, g)
+	})
+	if err != nil {
+		g.Version = given
+		return nil, err
+	}
+	return g, nil
+}
+
+// DeleteByID deletes an existing game instance from the database by writing its tombstone. Returns true if the game
+// was found and deleted, false otherwise. A version other than zero must be the stored one.
+func (r *SQLiteRepository) DeleteByID(ctx context.Context, id pkg.GameID, version int64) (bool, error) {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := getGame(ctx, tx, id)
+		if err != nil {
+			return err
+		} else if before.IsDeleted() {
+			return svcerrors.ErrNotFound
+		} else if err = checkVersion(id, before.Version, version); err != nil {
+			return err
+		}
+
+		tombstone := *before
+		tombstone.Version++
+		tombstone.DeletedAt = time.Now().UTC()
+		if _, err = tx.ExecContext(ctx, `UPDATE games SET deleted_at = ?, version = ? WHERE id = ?`,
+			tombstone.DeletedAt.Format(tombstoneLayout), tombstone.Version, string(id)); err != nil {
+			return fmt.Errorf("unable to delete game '%s': %w", id, err)
+		}
+		return recordChange(ctx, tx, before, &tombstone)
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Restore removes the tombstone of a deleted game, returning the game as it was before it was deleted
+func (r *SQLiteRepository) Restore(ctx context.Context, id pkg.GameID) (*model.Game, error) {
+	var restored model.Game
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := getGame(ctx, tx, id)
+		if errors.Is(err, svcerrors.ErrNotFound) || (err == nil && !before.IsDeleted()) {
+			return fmt.Errorf("there is no deleted game with the ID '%s'. Source: %w", id, svcerrors.ErrNotFound)
+		} else if err != nil {
+			return err
+		}
+
+		restored = *before
+		restored.Version++
+		restored.DeletedAt = time.Time{}
+		if _, err = tx.ExecContext(ctx, `UPDATE games SET deleted_at = '', version = ? WHERE id = ?`,
+			restored.Version, string(id)); err != nil {
+			return fmt.Errorf("unable to restore game '%s': %w", id, err)
+		}
+		return recordChange(ctx, tx, before, &restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// Purge removes every game deleted before the given time for good, returning how many were removed. The history of
+// each game records it being purged, and a GamePurged event is added to the outbox for it, in the same transaction.
+func (r *SQLiteRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	purged := 0
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `SELECT `+gameColumns+` FROM games WHERE deleted_at != '' AND deleted_at < ?`,
+			deletedBefore.UTC().Format(tombstoneLayout))
+		if err != nil {
+			return fmt.Errorf("unable to find the deleted games to purge: %w", err)
+		}
+		tombstones := []*model.Game{}
+		for rows.Next() {
+			g, err := scanGame(rows)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("unable to read a deleted game to purge: %w", err)
+			}
+			tombstones = append(tombstones, g)
+		}
+		if err = errors.Join(rows.Err(), rows.Close()); err != nil {
+			return fmt.Errorf("unable to find the deleted games to purge: %w", err)
+		}
+
+		for _, g := range tombstones {
+			if _, err = tx.ExecContext(ctx, `DELETE FROM games WHERE id = ?`, string(g.ID)); err != nil {
+				return fmt.Errorf("unable to purge game '%s': %w", g.ID, err)
+			}
+			if err = recordChange(ctx, tx, g, nil); err != nil {
+				return err
+			}
+		}
+		purged = len(tombstones)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// List returns one page of the games matching the query, ordered by ascending ID.
+func (r *SQLiteRepository) List(ctx context.Context, q model.GameQuery) (*model.GameList, error) {
+	after, err := decodeCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	where := []string{}
+	args := []any{}
+	if !q.IncludeDeleted {
+		where = append(where, "deleted_at = ''")
+	}
+	if after != "" {
+		where = append(where, "id > ?")
+		args = append(args, string(after))
+	}
+	if q.RoundID != "" {
+		where = append(where, "round_id = ?")
+		args = append(args, string(q.RoundID))
+	}
+	if q.SideID != "" {
+		where = append(where, "(side1_id = ? OR side2_id = ?)")
+		args = append(args, string(q.SideID), string(q.SideID))
+	}
+	if len(q.Statuses) > 0 {
+		where = append(where, "status IN (?"+strings.Repeat(", ?", len(q.Statuses)-1)+")")
+		for _, s := range q.Statuses {
+			args = append(args, int(s))
+		}
+	}
+
+	query := `SELECT ` + gameColumns + ` FROM games`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	limit := q.PageLimit()
+	query += ` ORDER BY id LIMIT ?`
+	args = append(args, limit+1) // one extra row tells us whether there is another page
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list games: %w", err)
+	}
+	defer rows.Close()
+
+	result := &model.GameList{Games: []model.Game{}}
+	for rows.Next() {
+		if len(result.Games) == limit {
+			result.NextCursor = encodeCursor(result.Games[limit-1].ID)
+			break
+		}
+		g, err := scanGame(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read listed game: %w", err)
+		}
+		result.Games = append(result.Games, *g)
+	}
+	return result, rows.Err()
+}
+
+// PendingOutbox returns every outbox entry not yet relayed, in Seq order
+func (r *SQLiteRepository) PendingOutbox(ctx context.Context) ([]OutboxEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT seq, game_id, event_id, event_type, occurred_at, payload, attempts,
+		last_error, last_attempt_at FROM games_outbox ORDER BY seq`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the outbox: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []OutboxEntry{}
+	for rows.Next() {
+		var (
+			e                       OutboxEntry
+			gameID, eventType       string
+			occurredAt, lastAttempt string
+			payload                 []byte
+		)
+		if err := rows.Scan(&e.Seq, &gameID, &e.Envelope.ID, &eventType, &occurredAt, &payload, &e.Attempts,
+			&e.LastError, &lastAttempt); err != nil {
+			return nil, fmt.Errorf("unable to read outbox entry: %w", err)
+		}
+		e.GameID = pkg.GameID(gameID)
+		e.Envelope.Type = events.Type(eventType)
+		e.Envelope.Payload = payload
+		e.Envelope.OccurredAt, _ = time.Parse(time.RFC3339Nano, occurredAt)
+		if lastAttempt != "" {
+			e.LastAttemptAt, _ = time.Parse(time.RFC3339Nano, lastAttempt)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// MarkRelayed removes the outbox entry with the given Seq once it has been delivered
+func (r *SQLiteRepository) MarkRelayed(ctx context.Context, seq int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM games_outbox WHERE seq = ?`, seq)
+	return outboxUpdated(res, err, seq)
+}
+
+// MarkFailed records a failed attempt at relaying the outbox entry with the given Seq
+func (r *SQLiteRepository) MarkFailed(ctx context.Context, seq int64, reason string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE games_outbox SET attempts = attempts + 1, last_error = ?, last_attempt_at = ?
+		WHERE seq = ?`, reason, at.UTC().Format(time.RFC3339Nano), seq)
+	return outboxUpdated(res, err, seq)
+}
+
+// History returns every entry recorded for the game with the given ID, oldest first
+func (r *SQLiteRepository) History(ctx context.Context, id pkg.GameID) ([]model.HistoryEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT game_id, version, action, at, actor, reason, changes FROM games_history
+		WHERE game_id = ? ORDER BY seq`, string(id))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the history of game '%s': %w", id, err)
+	}
+	defer rows.Close()
+
+	entries := []model.HistoryEntry{}
+	for rows.Next() {
+		var (
+			e                  model.HistoryEntry
+			gameID, action, at string
+			changes            []byte
+		)
+		if err := rows.Scan(&gameID, &e.Version, &action, &at, &e.Actor, &e.Reason, &changes); err != nil {
+			return nil, fmt.Errorf("unable to read history entry: %w", err)
+		}
+		e.GameID = pkg.GameID(gameID)
+		e.Action = model.HistoryAction(action)
+		e.At, _ = time.Parse(time.RFC3339Nano, at)
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, fmt.Errorf("unable to read the changes of a history entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	} else if len(entries) == 0 {
+		return nil, fmt.Errorf("no history for game '%s'. Source: %w", id, svcerrors.ErrNotFound)
+	}
+	return entries, nil
+}
+
+// inTx runs fn in a transaction, committing it if fn succeeds and rolling it back otherwise
+func (r *SQLiteRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to start a transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier covers both *sql.DB and *sql.Tx so games can be read inside or outside a transaction
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// getGame reads the game with the given ID, returning svcerrors.ErrNotFound if there is none
+func getGame(ctx context.Context, q querier, id pkg.GameID) (*model.Game, error) {
+	row := q.QueryRowContext(ctx, `SELECT `+gameColumns+` FROM games WHERE id = ?`, string(id))
+	g, err := scanGame(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, svcerrors.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read game '%s': %w", id, err)
+	}
+	return g, nil
+}
+
+// recordChange adds the outbox and history entries for a write changing the game from before to after, in the
+// transaction of the write
+func recordChange(ctx context.Context, tx *sql.Tx, before, after *model.Game) error {
+	if err := recordOutbox(ctx, tx, before, after); err != nil {
+		return err
+	}
+	return recordHistory(ctx, tx, before, after)
+}
+
+// recordHistory adds the history entry for a write changing the game from before to after, in the transaction of
+// the write
+func recordHistory(ctx context.Context, tx *sql.Tx, before, after *model.Game) error {
+	e, err := newHistoryEntry(ctx, before, after)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return fmt.Errorf("unable to write the changes to game '%s': %w", e.GameID, err)
+	}
+	if _, err = tx.ExecContext(ctx, `INSERT INTO games_history (game_id, version, action, at, actor, reason, changes)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, string(e.GameID), e.Version, string(e.Action), e.At.Format(time.RFC3339Nano),
+		e.Actor, e.Reason, changes); err != nil {
+		return fmt.Errorf("unable to record the history entry for game '%s': %w", e.GameID, err)
+	}
+	return nil
+}
+
+// recordOutbox adds the outbox entry for a write changing the game from before to after, in the transaction of
+// the write
+func recordOutbox(ctx context.Context, tx *sql.Tx, before, after *model.Game) error {
+	e, err := newOutboxEntry(before, after)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `INSERT INTO games_outbox (game_id, event_id, event_type, occurred_at, payload)
+		VALUES (?, ?, ?, ?, ?)`, string(e.GameID), e.Envelope.ID, string(e.Envelope.Type),
+		e.Envelope.OccurredAt.Format(time.RFC3339Nano), []byte(e.Envelope.Payload)); err != nil {
+		return fmt.Errorf("unable to record the outbox entry for game '%s': %w", e.GameID, err)
+	}
+	return nil
+}
+
+// outboxUpdated turns the result of changing a single outbox entry into the error to return, if any
+func outboxUpdated(res sql.Result, err error, seq int64) error {
+	if err != nil {
+		return fmt.Errorf("unable to update outbox entry %d: %w", seq, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no outbox entry with sequence %d. Source: %w", seq, svcerrors.ErrNotFound)
+	}
+	return nil
+}
+
+// rowScanner covers both *sql.Row and *sql.Rows so a single function can read games from either
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanGame reads a game from a row selected with gameColumns
+func scanGame(row rowScanner) (*model.Game, error) {
+	var (
+		g                          model.Game
+		id                         int64
+		side1, side2, round, ceded string
+		status                     int
+		reports, resolution        string
+		breakdown1, breakdown2     string
+		deleted                    string
+	)
+	if err := row.Scan(&id, &side1, &side2, &round, &g.Side1TotalVictoryPoints, &g.Side2TotalVictoryPoints,
+		&g.Side1KilledGeneral, &g.Side2KilledGeneral, &breakdown1, &breakdown2, &status, &ceded, &g.Version, &reports,
+		&resolution, &deleted); err != nil {
+		return nil, err
+	}
+	if deleted != "" {
+		g.DeletedAt, _ = time.Parse(tombstoneLayout, deleted)
+	}
+	if reports != "" {
+		if err := json.Unmarshal([]byte(reports), &g.Reports); err != nil {
+			return nil, fmt.Errorf("unable to read the result reports: %w", err)
+		}
+	}
+	if resolution != "" {
+		g.Resolution = &model.Resolution{}
+		if err := json.Unmarshal([]byte(resolution), g.Resolution); err != nil {
+			return nil, fmt.Errorf("unable to read the resolution: %w", err)
+		}
+	}
+	if breakdown1 != "" {
+		g.Side1Breakdown = &model.SideBreakdown{}
+		if err := json.Unmarshal([]byte(breakdown1), g.Side1Breakdown); err != nil {
+			return nil, fmt.Errorf("unable to read the breakdown of side 1: %w", err)
+		}
+	}
+	if breakdown2 != "" {
+		g.Side2Breakdown = &model.SideBreakdown{}
+		if err := json.Unmarshal([]byte(breakdown2), g.Side2Breakdown); err != nil {
+			return nil, fmt.Errorf("unable to read the breakdown of side 2: %w", err)
+		}
+	}
+
+	g.ID = pkg.GameID(strconv.FormatInt(id, 10))
+	g.Side1ID = players.PlayerID(side1)
+	g.Side2ID = players.PlayerID(side2)
+	g.RoundID = rounds.RoundID(round)
+	g.Status = pkg.GameState(status)
+	g.ConcedingSideID = players.PlayerID(ceded)
+	return &g, nil
+}
+
+// encodeReports turns the result reports and resolution of the game into the JSON stored in their columns, leaving
+// either empty when the game has none
+func encodeReports(g *model.Game) (string, string, error) {
+	var reports, resolution []byte
+	if len(g.Reports) > 0 {
+		var err error
+		if reports, err = json.Marshal(g.Reports); err != nil {
+			return "", "", fmt.Errorf("unable to write the result reports of game '%s': %w", g.ID, err)
+		}
+	}
+	if g.Resolution != nil {
+		var err error
+		if resolution, err = json.Marshal(g.Resolution); err != nil {
+			return "", "", fmt.Errorf("unable to write the resolution of game '%s': %w", g.ID, err)
+		}
+	}
+	return string(reports), string(resolution), nil
+}
+
+// encodeBreakdowns turns the breakdown of each side of the game into the JSON stored in their columns, leaving
+// either empty when that side has none
+func encodeBreakdowns(g *model.Game) (string, string, error) {
+	encoded := [2]string{}
+	for i, b := range []*model.SideBreakdown{g.Side1Breakdown, g.Side2Breakdown} {
+		if b == nil {
+			continue
+		}
+		j, err := json.Marshal(b)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to write the breakdown of side %d of game '%s': %w", i+1, g.ID, err)
+		}
+		encoded[i] = string(j)
+	}
+	return encoded[0], encoded[1], nil
+}
@@ -0,0 +1,70 @@
+package secondary
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+var _ Repository = (*SQLiteRepository)(nil)
+
+func TestSQLiteRepository(t *testing.T) {
+	runRepositoryConformance(t, func(t *testing.T) Repository {
+		return newTestSQLiteRepository(t, ":memory:")
+	})
+}
+
+func TestSQLiteRepoSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "games.db")
+
+	r := newTestSQLiteRepository(t, path)
+	g, err := r.Create(context.Background(), createFakeGame())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatalf("Expected no error closing the repository, got %v", err)
+	}
+
+	// Reopening runs the migrations again, which must leave the existing schema and data alone
+	r = newTestSQLiteRepository(t, path)
+	result, err := r.GetByID(context.Background(), g.ID)
+	if err != nil {
+		t.Fatalf("Expected the game to survive reopening the database, got %v", err)
+	}
+	if result.Side1ID != g.Side1ID || result.Side2TotalVictoryPoints != g.Side2TotalVictoryPoints || result.Status != g.Status {
+		t.Errorf("Expected the reopened game to match the original, got %+v", result)
+	}
+}
+
+func TestRepositoryConfig(t *testing.T) {
+	r, err := NewRepository(RepositoryConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := r.(*MemoryRepository); !ok {
+		t.Errorf("Expected the memory repository by default, got %T", r)
+	}
+
+	r, err = NewRepository(RepositoryConfig{Kind: RepositoryKindSQLite, SQLitePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := r.(*SQLiteRepository); !ok {
+		t.Errorf("Expected the SQLite repository, got %T", r)
+	}
+
+	if _, err = NewRepository(RepositoryConfig{Kind: "carrier-pigeon"}); err == nil {
+		t.Errorf("Expected error for an unknown repository kind, got nil")
+	}
+}
+
+// newTestSQLiteRepository opens a SQLite repository at the given path which is closed when the test finishes
+func newTestSQLiteRepository(t *testing.T, path string) *SQLiteRepository {
+	r, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("Expected no error opening the SQLite repository, got %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
@@ -0,0 +1,15 @@
+package secondary
+
+import (
+	"context"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+)
+
+// VictoryPointLimits defines the port for finding out how many victory points can be scored in a game, which is
+// set by the scenario played in the game's round and so is owned by other services.
+type VictoryPointLimits interface {
+	// MaxVictoryPoints returns the most victory points one side can score in the game, along with the name of the
+	// scenario setting the limit. Zero is returned if nothing limits the game, such as when its round has no
+	// scenario.
+	MaxVictoryPoints(ctx context.Context, g *model.Game) (int, string, error)
+}
@@ -23,11 +23,18 @@ type GamesGateway interface {
 	Create(ctx context.Context, game *model.Game) (*model.Game, error)
 
 	// Replace updates an existing game in the service with the provided game.
-	// A generic error is returned if the game to replaced is not known to the service.
+	// A generic error is returned if the game to replaced is not known to the service. The Version of the game is
+	// passed along, if it is set and the game has changed since that version svcerrors.ErrVersionConflict is
+	// returned, so a game read with GetByID can be changed and written back safely.
 	// This is an idempotent operation.
 	Replace(ctx context.Context, g *model.Game) (*model.Game, error)
 
 	// DeleteByID removes the game with the given id from the service. Returns true if the game was found and
-	// deleted, false otherwise. This is an idempotent operation.
-	DeleteByID(ctx context.Context, id games.GameID) (bool, error)
+	// deleted, false otherwise. This is an idempotent operation. If the version is not zero and the game has
+	// changed since that version svcerrors.ErrVersionConflict is returned.
+	DeleteByID(ctx context.Context, id games.GameID, version int64) (bool, error)
+
+	// List returns one page of the games matching the filters in the query, ordered by ID. Pass the NextCursor
+	// from the returned list back in the query to fetch the following page.
+	List(ctx context.Context, q model.GameQuery) (*model.GameList, error)
 }
@@ -0,0 +1,240 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	"github.com/rpatton4/mesbg-league/games/internal/primary"
+	"github.com/rpatton4/mesbg-league/games/internal/secondary"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Both gateways have to satisfy the interface
+var _ GamesGateway = (*InProcessGateway)(nil)
+var _ GamesGateway = (*HTTPGateway)(nil)
+
+func TestInProcessGatewayContract(t *testing.T) {
+	runGatewayContract(t, func(t *testing.T) GamesGateway {
+		return NewInProcessGatewayWithController(primary.NewTxnController(secondary.NewMemoryRepository(), nil, nil))
+	})
+}
+
+func TestHTTPGatewayContract(t *testing.T) {
+	runGatewayContract(t, func(t *testing.T) GamesGateway {
+		srv := newTestGamesServer(t)
+		return New(srv.URL, WithHTTPClient(srv.Client()))
+	})
+}
+
+// runGatewayContract runs the same scenarios against any GamesGateway, so that callers get identical results and
+// errors whichever implementation they are given. newGateway is called for each case so every case starts empty.
+func runGatewayContract(t *testing.T, newGateway func(t *testing.T) GamesGateway) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, gw GamesGateway)
+	}{
+		{"CreateAndGet", testGatewayCreateAndGet},
+		{"CreateInvalid", testGatewayCreateInvalid},
+		{"GetUnknown", testGatewayGetUnknown},
+		{"Replace", testGatewayReplace},
+		{"Delete", testGatewayDelete},
+		{"VersionConflict", testGatewayVersionConflict},
+		{"List", testGatewayList},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, newGateway(t))
+		})
+	}
+}
+
+func testGatewayCreateAndGet(t *testing.T, gw GamesGateway) {
+	g, err := gw.Create(context.Background(), createFakeGame())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if g.ID == "" {
+		t.Fatalf("Expected game ID to be assigned")
+	}
+
+	result, err := gw.GetByID(context.Background(), g.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.ID != g.ID || result.Side1ID != g.Side1ID || result.Side2TotalVictoryPoints != g.Side2TotalVictoryPoints {
+		t.Errorf("Expected the fetched game to match the created one, got %+v", result)
+	}
+}
+
+func testGatewayCreateInvalid(t *testing.T, gw GamesGateway) {
+	invalid := createFakeGame()
+	invalid.Side2ID = ""
+	if _, err := gw.Create(context.Background(), invalid); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error, got %v", err)
+	}
+
+	if _, err := gw.Create(context.Background(), nil); !errors.Is(err, svcerrors.ErrModelMissing) {
+		t.Errorf("Expected missing model error, got %v", err)
+	}
+}
+
+func testGatewayGetUnknown(t *testing.T, gw GamesGateway) {
+	if _, err := gw.GetByID(context.Background(), "9999"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if _, err := gw.GetByID(context.Background(), ""); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected invalid ID error, got %v", err)
+	}
+}
+
+func testGatewayReplace(t *testing.T, gw GamesGateway) {
+	// The scores are only changed directly while the game is being played
+	g := createFakeGame()
+	g.Status = games.GameStateInProgress
+	g, err := gw.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	g.Side1TotalVictoryPoints = 18
+	result, err := gw.Replace(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Side1TotalVictoryPoints != 18 {
+		t.Errorf("Expected the score to be updated, got %d", result.Side1TotalVictoryPoints)
+	}
+
+	reopened := *result
+	reopened.Status = games.GameStateNotStarted
+	if _, err = gw.Replace(context.Background(), &reopened); !errors.Is(err, svcerrors.ErrIllegalStateTransition) {
+		t.Errorf("Expected illegal transition error, got %v", err)
+	}
+
+	unknown := *result
+	unknown.ID = "9999"
+	if _, err = gw.Replace(context.Background(), &unknown); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	missingID := *result
+	missingID.ID = ""
+	if _, err = gw.Replace(context.Background(), &missingID); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected invalid ID error, got %v", err)
+	}
+}
+
+func testGatewayDelete(t *testing.T, gw GamesGateway) {
+	g, err := gw.Create(context.Background(), createFakeGame())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ok, err := gw.DeleteByID(context.Background(), g.ID, 0)
+	if err != nil || !ok {
+		t.Fatalf("Expected delete to succeed, got %v, %v", ok, err)
+	}
+
+	ok, err = gw.DeleteByID(context.Background(), g.ID, 0)
+	if !errors.Is(err, svcerrors.ErrNotFound) || ok {
+		t.Errorf("Expected not found deleting a second time, got %v, %v", ok, err)
+	}
+}
+
+func testGatewayVersionConflict(t *testing.T, gw GamesGateway) {
+	// The scores are only changed directly while the game is being played
+	g := createFakeGame()
+	g.Status = games.GameStateInProgress
+	g, err := gw.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	read, err := gw.GetByID(context.Background(), g.ID)
+	if err != nil || read.Version != 1 {
+		t.Fatalf("Expected the new game at version 1, got %+v, %v", read, err)
+	}
+
+	// Two copies of the same version are changed, only the first write can be based on it
+	first, second := *read, *read
+	first.Side1TotalVictoryPoints = 11
+	second.Side1TotalVictoryPoints = 12
+	updated, err := gw.Replace(context.Background(), &first)
+	if err != nil || updated.Version != 2 {
+		t.Fatalf("Expected the first write to move the game to version 2, got %+v, %v", updated, err)
+	}
+	if _, err = gw.Replace(context.Background(), &second); !errors.Is(err, svcerrors.ErrVersionConflict) {
+		t.Errorf("Expected version conflict for the stale write, got %v", err)
+	}
+	if ok, err := gw.DeleteByID(context.Background(), g.ID, read.Version); ok || !errors.Is(err, svcerrors.ErrVersionConflict) {
+		t.Errorf("Expected version conflict deleting a stale version, got %v, %v", ok, err)
+	}
+
+	if result, _ := gw.GetByID(context.Background(), g.ID); result.Side1TotalVictoryPoints != 11 || result.Version != 2 {
+		t.Errorf("Expected the first write to be kept, got %+v", result)
+	}
+	if ok, err := gw.DeleteByID(context.Background(), g.ID, updated.Version); !ok || err != nil {
+		t.Errorf("Expected the current version to be deleted, got %v, %v", ok, err)
+	}
+}
+
+func testGatewayList(t *testing.T, gw GamesGateway) {
+	for i := 0; i < 3; i++ {
+		g := createFakeGame()
+		if i == 0 {
+			g.RoundID = "other-round"
+		}
+		if _, err := gw.Create(context.Background(), g); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	l, err := gw.List(context.Background(), model.GameQuery{RoundID: "789", Limit: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(l.Games) != 1 || l.NextCursor == "" {
+		t.Fatalf("Expected one game and a cursor for the next page, got %+v", l)
+	}
+
+	l, err = gw.List(context.Background(), model.GameQuery{RoundID: "789", Limit: 1, Cursor: l.NextCursor})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(l.Games) != 1 || l.NextCursor != "" {
+		t.Errorf("Expected the last game and no cursor, got %+v", l)
+	}
+
+	if _, err = gw.List(context.Background(), model.GameQuery{Cursor: "!!"}); !errors.Is(err, svcerrors.ErrInvalidQuery) {
+		t.Errorf("Expected invalid query error, got %v", err)
+	}
+}
+
+// newTestGamesServer starts the Games service routes over a fresh memory repository, closed when the test ends
+func newTestGamesServer(t *testing.T) *httptest.Server {
+	router := http.NewServeMux()
+	api := humago.New(router, huma.DefaultConfig("Games Service", "1.0.0"))
+	primary.RegisterRoutes(api, primary.NewHumaHandler(primary.NewTxnController(secondary.NewMemoryRepository(), nil, nil)))
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func createFakeGame() *model.Game {
+	return &model.Game{
+		Side1ID:                 "123",
+		Side2ID:                 "456",
+		RoundID:                 "789",
+		Side1TotalVictoryPoints: 10,
+		Side2TotalVictoryPoints: 15,
+		Side1KilledGeneral:      true,
+		Status:                  games.GameStatePlayCompleted,
+	}
+}
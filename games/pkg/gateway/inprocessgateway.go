@@ -18,11 +18,16 @@ func NewInProcessGatewayWithController(ctrl primary.SingleController) *InProcess
 	return &InProcessGateway{ctrl}
 }
 
-// NewDefaultInProcessGateway creates a new InProcessGateway with a default repository and txncontroller
-func NewDefaultInProcessGateway() *InProcessGateway {
-	repo := secondary.NewDefaultRepository()
-	ctrl := primary.NewTxnController(repo)
-	return NewInProcessGatewayWithController(ctrl)
+// NewDefaultInProcessGateway creates a new InProcessGateway with a default repository and txncontroller. An error is
+// returned if the configured repository cannot be set up. The default controller has no access to the rounds or
+// scenarios, so it does not check results against the victory points of a scenario.
+func NewDefaultInProcessGateway() (*InProcessGateway, error) {
+	repo, err := secondary.NewDefaultRepository()
+	if err != nil {
+		return nil, err
+	}
+	ctrl := primary.NewTxnController(repo, nil, nil)
+	return NewInProcessGatewayWithController(ctrl), nil
 }
 
 func (ipg *InProcessGateway) GetByID(ctx context.Context, id games.GameID) (*model.Game, error) {
@@ -34,6 +39,9 @@ func (ipg *InProcessGateway) Create(ctx context.Context, g *model.Game) (*model.
 func (ipg *InProcessGateway) Replace(ctx context.Context, g *model.Game) (*model.Game, error) {
 	return ipg.ctrl.Replace(ctx, g)
 }
-func (ipg *InProcessGateway) DeleteByID(ctx context.Context, id games.GameID) (bool, error) {
-	return ipg.ctrl.DeleteByID(ctx, id)
+func (ipg *InProcessGateway) DeleteByID(ctx context.Context, id games.GameID, version int64) (bool, error) {
+	return ipg.ctrl.DeleteByID(ctx, id, version)
+}
+func (ipg *InProcessGateway) List(ctx context.Context, q model.GameQuery) (*model.GameList, error) {
+	return ipg.ctrl.List(ctx, q)
 }
@@ -1,44 +1,319 @@
 package gateway
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/danielgtaylor/huma/v2"
 	gamesheader "github.com/rpatton4/mesbg-league/games/pkg"
 	games "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
 	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const (
+	// DefaultTimeout is how long a single attempt at a call may take before it is abandoned
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultRetries is how many times an idempotent call is retried after a failed first attempt
+	DefaultRetries = 2
+
+	// DefaultBackoff is the wait before the first retry, doubling for each retry after that
+	DefaultBackoff = 100 * time.Millisecond
+)
+
+// HTTPGateway is the GamesGateway implementation for calling the Games service over HTTP(S). Errors returned by the
+// service are mapped back to the same svcerrors values the InProcessGateway returns, so callers can use errors.Is
+// without caring which gateway they have.
 type HTTPGateway struct {
-	addr string
+	addr    string
+	client  *http.Client
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+// Option configures an HTTPGateway when it is created
+type Option func(*HTTPGateway)
+
+// WithHTTPClient sets the client used to make the calls, http.DefaultClient is used otherwise
+func WithHTTPClient(c *http.Client) Option {
+	return func(g *HTTPGateway) {
+		g.client = c
+	}
+}
+
+// WithTimeout sets how long a single attempt at a call may take, zero means no timeout beyond the caller's context
+func WithTimeout(d time.Duration) Option {
+	return func(g *HTTPGateway) {
+		g.timeout = d
+	}
 }
 
-func New(addr string) *HTTPGateway {
-	return &HTTPGateway{addr: addr}
+// WithRetries sets how many times idempotent calls (everything except Create) are retried after a network error
+// or a response indicating the service is temporarily unavailable, and the wait before the first retry
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(g *HTTPGateway) {
+		g.retries = retries
+		g.backoff = backoff
+	}
+}
+
+// New creates an HTTPGateway for the Games service at the given base address, e.g. "http://localhost:8081"
+func New(addr string, opts ...Option) *HTTPGateway {
+	g := &HTTPGateway{
+		addr:    strings.TrimSuffix(addr, "/"),
+		client:  http.DefaultClient,
+		timeout: DefaultTimeout,
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
+// GetByID returns the game with the given id, or a svcerrors.ErrNotFound if no game with that id exists
 func (g *HTTPGateway) GetByID(ctx context.Context, id gamesheader.GameID) (*games.Game, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.addr+"/"+string(id), nil)
-	if err != nil {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+
+	var game games.Game
+	if err := g.do(ctx, http.MethodGet, "/games/"+url.PathEscape(string(id)), nil, nil, &game, true); err != nil {
 		return nil, err
 	}
+	return &game, nil
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
+// Create persists a new game instance to the service and returns the game with an assigned ID. Creation is not
+// idempotent, so it is never retried.
+func (g *HTTPGateway) Create(ctx context.Context, game *games.Game) (*games.Game, error) {
+	if game == nil {
+		return nil, fmt.Errorf("the game to be created cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	}
+
+	var created games.Game
+	if err := g.do(ctx, http.MethodPost, "/games", nil, game, &created, false); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Replace updates an existing game in the service with the provided game, sending its Version as the If-Match
+// header so the service rejects the update if the game has changed since. A versioned replace is not retried: if
+// the first attempt was applied but its response lost, a retry would be rejected as a version conflict.
+func (g *HTTPGateway) Replace(ctx context.Context, game *games.Game) (*games.Game, error) {
+	if game == nil {
+		return nil, fmt.Errorf("the game to be replaced cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	} else if game.ID == "" {
+		return nil, fmt.Errorf("the game data sent with update is missing a game ID. Source: %w", svcerrors.ErrInvalidID)
+	}
+
+	var replaced games.Game
+	if err := g.do(ctx, http.MethodPut, "/games/"+url.PathEscape(string(game.ID)), ifMatch(game.Version), game, &replaced, game.Version == 0); err != nil {
 		return nil, err
 	}
+	return &replaced, nil
+}
 
+// DeleteByID removes the game with the given id from the service. Returns true if the game was found and
+// deleted, false otherwise. The version is sent as the If-Match header, zero deletes whatever the version. A retried
+// delete which finds no game counts as deleted, as the attempt before it may have deleted the game and then lost the
+// response.
+func (g *HTTPGateway) DeleteByID(ctx context.Context, id gamesheader.GameID, version int64) (bool, error) {
+	if id == "" {
+		return false, svcerrors.ErrInvalidID
+	}
+
+	if err := g.do(ctx, http.MethodDelete, "/games/"+url.PathEscape(string(id)), ifMatch(version), nil, nil, true); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// List returns one page of the games matching the filters in the query, ordered by ID.
+func (g *HTTPGateway) List(ctx context.Context, q games.GameQuery) (*games.GameList, error) {
+	if q.Limit < 0 {
+		return nil, fmt.Errorf("the page limit cannot be negative, got %d. Source: %w", q.Limit, svcerrors.ErrInvalidQuery)
+	}
+
+	v := url.Values{}
+	if q.RoundID != "" {
+		v.Set("roundId", string(q.RoundID))
+	}
+	if q.SideID != "" {
+		v.Set("sideId", string(q.SideID))
+	}
+	for _, s := range q.Statuses {
+		v.Add("status", strconv.Itoa(int(s)))
+	}
+	if q.IncludeDeleted {
+		v.Set("includeDeleted", "true")
+	}
+	if q.Cursor != "" {
+		v.Set("cursor", q.Cursor)
+	}
+	v.Set("limit", strconv.Itoa(q.PageLimit()))
+
+	var list games.GameList
+	if err := g.do(ctx, http.MethodGet, "/games?"+v.Encode(), nil, nil, &list, true); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ifMatch returns the If-Match header for a write based on the given version of a game, the service requires the
+// header so "*" is sent for version zero
+func ifMatch(version int64) http.Header {
+	h := http.Header{}
+	if version == 0 {
+		h.Set("If-Match", "*")
+	} else {
+		h.Set("If-Match", `"`+strconv.FormatInt(version, 10)+`"`)
+	}
+	return h
+}
+
+// do makes the call to the service, retrying idempotent calls which fail in a way that may succeed on another try.
+// Any given headers are added to the request, and the response body is decoded into out when it is not nil.
+func (g *HTTPGateway) do(ctx context.Context, method, path string, header http.Header, in any, out any, idempotent bool) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to encode the request body: %w", err)
+		}
+	}
+
+	attempts := 1
+	if idempotent {
+		attempts += g.retries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			wait := g.backoff << (attempt - 1)
+			slog.Debug("Retrying call to the games service", "method", method, "path", path, "attempt", attempt+1, "wait", wait, "error", lastErr)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		retry, err := g.attempt(ctx, method, path, header, body, out)
+		if attempt > 0 && method == http.MethodDelete && errors.Is(err, svcerrors.ErrNotFound) {
+			// An earlier attempt whose response was lost already deleted it
+			return nil
+		}
+		if err == nil || !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// attempt makes a single call to the service, reporting whether a failure is worth retrying
+func (g *HTTPGateway) attempt(ctx context.Context, method, path string, header http.Header, body []byte, out any) (bool, error) {
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, g.addr+path, reader)
+	if err != nil {
+		return false, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if actor := auth.Actor(ctx); actor != "" {
+		req.Header.Set(auth.ActorHeader, actor)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		// The caller giving up is final, anything else at the network level may be temporary
+		return ctx.Err() == nil || errors.Is(ctx.Err(), context.DeadlineExceeded), err
+	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNotFound {
-		return nil, svcerrors.ErrNotFound
-	} else if resp.StatusCode/100 != 2 {
-		return nil, fmt.Errorf("non-2xx response: %v", resp)
+	if resp.StatusCode/100 != 2 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout
+		return retry, errorFromResponse(resp)
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, fmt.Errorf("failed to decode the games service response: %w", err)
+		}
 	}
-	var game *games.Game
-	if err := json.NewDecoder(resp.Body).Decode(&game); err != nil {
-		return nil, fmt.Errorf("failed to decode game: %w", err)
+	return false, nil
+}
+
+// detailSentinels are the errors which can be recognised from the detail of a 4xx response, in the order they are
+// checked. The service includes the underlying error text in its responses, which always contains the sentinel.
+var detailSentinels = []error{
+	svcerrors.ErrVersionConflict,
+	svcerrors.ErrIllegalStateTransition,
+	svcerrors.ErrInvalidQuery,
+	svcerrors.ErrInvalidID,
+	svcerrors.ErrModelMissing,
+	svcerrors.ErrNotFound,
+	svcerrors.ErrModelInvalid,
+}
+
+// errorFromResponse turns an error response from the service back into the svcerrors value which caused it
+func errorFromResponse(resp *http.Response) error {
+	var problem huma.ErrorModel
+	b, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(b, &problem); err != nil || problem.Detail == "" {
+		problem.Detail = strings.TrimSpace(string(b))
+	}
+
+	var sentinel error
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		sentinel = svcerrors.ErrNotFound
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		sentinel = svcerrors.ErrVersionConflict
+	case resp.StatusCode == http.StatusConflict && strings.Contains(problem.Detail, svcerrors.ErrVersionConflict.Error()):
+		sentinel = svcerrors.ErrVersionConflict
+	case resp.StatusCode == http.StatusConflict:
+		sentinel = svcerrors.ErrIllegalStateTransition
+	case resp.StatusCode/100 == 4:
+		sentinel = svcerrors.ErrModelInvalid
+		for _, s := range detailSentinels {
+			if strings.Contains(problem.Detail, s.Error()) {
+				sentinel = s
+				break
+			}
+		}
+	default:
+		return fmt.Errorf("games service responded %d: %s", resp.StatusCode, problem.Detail)
 	}
-	return game, nil
+	return fmt.Errorf("games service responded %d: %s. Source: %w", resp.StatusCode, problem.Detail, sentinel)
 }
@@ -0,0 +1,131 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPGatewayRetriesIdempotentCalls(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","side1Id":"8","side2Id":"9","roundId":"7"}`))
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithRetries(2, time.Millisecond))
+	g, err := gw.GetByID(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after retrying, got %v", err)
+	}
+	if g.ID != "1" || calls.Load() != 3 {
+		t.Errorf("Expected game 1 after 3 calls, got game %s after %d calls", g.ID, calls.Load())
+	}
+}
+
+func TestHTTPGatewayDoesNotRetryCreate(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "try again later", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithRetries(3, time.Millisecond))
+	if _, err := gw.Create(context.Background(), createFakeGame()); err == nil {
+		t.Fatalf("Expected an error from the unavailable service, got nil")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected create to be called once, got %d calls", calls.Load())
+	}
+}
+
+func TestHTTPGatewayDoesNotRetryVersionedReplace(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		// The game is replaced but the response is lost on the way back
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithRetries(3, time.Millisecond))
+	game := createFakeGame()
+	game.ID = "1"
+	game.Version = 2
+	if _, err := gw.Replace(context.Background(), game); err == nil {
+		t.Fatalf("Expected an error from the failed replace, got nil")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected a versioned replace to be called once, got %d calls", calls.Load())
+	}
+}
+
+func TestHTTPGatewayRetriedDeleteOfMissingGame(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// The game is deleted but the response is lost on the way back
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		http.Error(w, "game not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithRetries(2, time.Millisecond))
+	deleted, err := gw.DeleteByID(context.Background(), "1", 0)
+	if err != nil || !deleted {
+		t.Errorf("Expected a retried delete finding no game to count as deleted, got %t, %v", deleted, err)
+	}
+
+	calls.Store(1)
+	if deleted, err = gw.DeleteByID(context.Background(), "1", 0); !errors.Is(err, svcerrors.ErrNotFound) || deleted {
+		t.Errorf("Expected a first attempt finding no game to return ErrNotFound, got %t, %v", deleted, err)
+	}
+}
+
+func TestHTTPGatewayTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithTimeout(10*time.Millisecond), WithRetries(0, 0))
+	_, err := gw.GetByID(context.Background(), "1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the call to time out, got %v", err)
+	}
+	if errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected a timeout not to look like a missing game")
+	}
+}
+
+func TestHTTPGatewayForwardsActor(t *testing.T) {
+	var actor atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor.Store(r.Header.Get(auth.ActorHeader))
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()))
+	_, _ = gw.GetByID(auth.WithActor(context.Background(), "organizer"), "1")
+	if actor.Load() != "organizer" {
+		t.Errorf("Expected the actor to be sent in the %s header, got %v", auth.ActorHeader, actor.Load())
+	}
+}
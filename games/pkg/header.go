@@ -28,4 +28,12 @@ const (
 
 	// GameStateCancelled indicates the game was not played, but was cancelled for some reason, such as a player dropping out of a tournament or league
 	GameStateCancelled GameState = 5
+
+	// GameStateAwaitingConfirmation indicates one side has reported the result of the game and it is waiting for the
+	// other side to report theirs, the game is completed once both reports agree
+	GameStateAwaitingConfirmation GameState = 6
+
+	// GameStateDisputed indicates both sides have reported the result of the game but the reports do not agree, an
+	// organizer has to resolve the dispute before the game is completed
+	GameStateDisputed GameState = 7
 )
@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"slices"
+	"strconv"
+)
+
+// gameStateTransitions is the lifecycle of a game, mapping each state to the states it may move on to. States which
+// are not keys in the table are terminal, once a game reaches them it can only be changed with an explicit override.
+// GameStatePlayCompleted is deliberately missing, a game is only completed by settling its result reports.
+var gameStateTransitions = map[GameState][]GameState{
+	GameStateNotStarted:           {GameStateInProgress, GameStateBye, GameStateConceded, GameStateCancelled, GameStateAwaitingConfirmation},
+	GameStateInProgress:           {GameStateConceded, GameStateCancelled, GameStateAwaitingConfirmation},
+	GameStateAwaitingConfirmation: {GameStateDisputed, GameStateConceded, GameStateCancelled},
+	GameStateDisputed:             {GameStateCancelled},
+}
+
+// gameStateSettlements are the moves only the result reports can make: a game taking reports is completed by both
+// sides agreeing on its result, or by an organizer resolving their dispute, never by simply being given a result
+var gameStateSettlements = map[GameState][]GameState{
+	GameStateAwaitingConfirmation: {GameStatePlayCompleted},
+	GameStateDisputed:             {GameStatePlayCompleted},
+}
+
+// countedGameStates are the states of games which count towards a league, everything else is either not finished
+// yet or was cancelled
+var countedGameStates = []GameState{GameStatePlayCompleted, GameStateBye, GameStateConceded}
+
+// gameStateNames holds the display names of the states, used in logging and error messages
+var gameStateNames = map[GameState]string{
+	GameStateNotStarted:    "NotStarted",
+	GameStateInProgress:    "InProgress",
+	GameStatePlayCompleted: "PlayCompleted",
+	GameStateBye:           "Bye",
+	GameStateConceded:      "Conceded",
+	GameStateCancelled:     "Cancelled",
+
+	GameStateAwaitingConfirmation: "AwaitingConfirmation",
+	GameStateDisputed:             "Disputed",
+}
+
+// IsValid returns true if the state is one of the GameStateXYZ constants
+func (s GameState) IsValid() bool {
+	_, ok := gameStateNames[s]
+	return ok
+}
+
+// IsTerminal returns true if the game can no longer change state without an override, i.e. it is finished one
+// way or another
+func (s GameState) IsTerminal() bool {
+	_, ok := gameStateTransitions[s]
+	return s.IsValid() && !ok
+}
+
+// CanTransitionTo returns true if the lifecycle allows a game in this state to move to the given state. Staying in
+// the same state is always allowed so that other details of the game can be corrected.
+func (s GameState) CanTransitionTo(to GameState) bool {
+	if !s.IsValid() || !to.IsValid() {
+		return false
+	}
+	if s == to {
+		return true
+	}
+	for _, allowed := range gameStateTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanSettleTo returns true if the result reports of a game in this state can move it to the given state, either by
+// both sides agreeing or by an organizer resolving their dispute. Every move the lifecycle allows is included.
+func (s GameState) CanSettleTo(to GameState) bool {
+	return s.CanTransitionTo(to) || (to.IsValid() && slices.Contains(gameStateSettlements[s], to))
+}
+
+// CountedGameStates returns the states of games which count towards a league, both for its standings and for the
+// totals of its participants
+func CountedGameStates() []GameState {
+	return slices.Clone(countedGameStates)
+}
+
+// String returns the display name of the state, or the number for unknown states
+func (s GameState) String() string {
+	if n, ok := gameStateNames[s]; ok {
+		return n
+	}
+	return "GameState(" + strconv.Itoa(int(s)) + ")"
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// SideBreakdown is the optional detail of how one side's game went, beyond the total victory points. It is used
+// for the bonus points and awards of a league, such as heroes slain and most kills.
+type SideBreakdown struct {
+	// VictoryPoints holds the victory points the side scored for each objective category, e.g. "objectives" or
+	// "wounding the general". When it is given the categories must add up to the side's total victory points.
+	VictoryPoints map[string]int `json:"victoryPoints,omitempty" example:"{\"objectives\":6,\"breaking\":3}" doc:"The victory points the side scored for each objective category, adding up to the side's total"`
+
+	// HeroesSlain lists the enemy heroes the side killed, by name
+	HeroesSlain []string `json:"heroesSlain,omitempty" example:"[\"Boromir, Captain of the White Tower\"]" doc:"The names of the enemy heroes the side killed"`
+
+	// ModelsRemoved is the number of enemy models, heroes and warriors alike, the side removed as casualties
+	ModelsRemoved int `json:"modelsRemoved,omitempty" example:"14" doc:"The number of enemy models the side removed as casualties"`
+
+	// Broken is true if the side was reduced to half its starting models or fewer
+	Broken bool `json:"broken,omitempty" example:"true" doc:"True if the side was broken, reduced to half its starting models"`
+
+	// Quartered is true if the side was reduced to a quarter of its starting models or fewer, which means it was
+	// also broken
+	Quartered bool `json:"quartered,omitempty" example:"false" doc:"True if the side was quartered, reduced to a quarter of its starting models"`
+}
+
+// VictoryPointsTotal returns the sum of the victory points of every objective category
+func (b *SideBreakdown) VictoryPointsTotal() int {
+	total := 0
+	for _, vp := range b.VictoryPoints {
+		total += vp
+	}
+	return total
+}
+
+// Problems returns a description of everything wrong with the breakdown of the given side, checking its victory
+// point categories against the total victory points of the side. A nil breakdown has no problems.
+func (b *SideBreakdown) Problems(side string, totalVictoryPoints int) []string {
+	problems := []string{}
+	if b == nil {
+		return problems
+	}
+
+	for _, category := range slices.Sorted(maps.Keys(b.VictoryPoints)) {
+		if vp := b.VictoryPoints[category]; category == "" || vp < 0 {
+			problems = append(problems, fmt.Sprintf("%s.VictoryPoints['%s']=%d needs a category and 0 or more points", side, category, vp))
+		}
+	}
+	if sum := b.VictoryPointsTotal(); len(b.VictoryPoints) > 0 && sum != totalVictoryPoints {
+		problems = append(problems, fmt.Sprintf("%s.VictoryPoints add up to %d, not the total of %d", side, sum, totalVictoryPoints))
+	}
+	if slices.Contains(b.HeroesSlain, "") {
+		problems = append(problems, side+".HeroesSlain cannot have a hero without a name")
+	}
+	if b.ModelsRemoved < 0 {
+		problems = append(problems, fmt.Sprintf("%s.ModelsRemoved=%d must be 0 or more", side, b.ModelsRemoved))
+	} else if b.ModelsRemoved < len(b.HeroesSlain) {
+		problems = append(problems, fmt.Sprintf("%s.ModelsRemoved=%d is fewer than the %d heroes slain", side, b.ModelsRemoved, len(b.HeroesSlain)))
+	}
+	if b.Quartered && !b.Broken {
+		problems = append(problems, side+" is Quartered but not Broken, a quartered side is always broken")
+	}
+	return problems
+}
+
+// breakdownProblems returns a description of everything wrong with the breakdowns of a result with the given totals
+func breakdownProblems(side1, side2 *SideBreakdown, side1Total, side2Total int) []string {
+	return append(side1.Problems("Side1Breakdown", side1Total), side2.Problems("Side2Breakdown", side2Total)...)
+}
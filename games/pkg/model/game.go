@@ -8,6 +8,7 @@ import (
 	players "github.com/rpatton4/mesbg-league/players/pkg"
 	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
 	"log/slog"
+	"time"
 )
 
 // Game represents a game of Middle Earth Strategic Battle Game played between two players, with associated information
@@ -39,9 +40,84 @@ type Game struct {
 	// Side2TotalGeneralsKilled is true if the side 2 player killed the opposing general
 	Side2KilledGeneral bool `json:"side2KilledGeneral,omitempty" example:"false" doc:"True if the second player killed the opposing general, false otherwise"`
 
+	// Side1Breakdown is the optional detail of how the game went for the first side
+	Side1Breakdown *SideBreakdown `json:"side1Breakdown,omitempty" doc:"The optional detail of how the game went for the first side, such as heroes slain and models removed"`
+
+	// Side2Breakdown is the optional detail of how the game went for the second side
+	Side2Breakdown *SideBreakdown `json:"side2Breakdown,omitempty" doc:"The optional detail of how the game went for the second side, such as heroes slain and models removed"`
+
 	// Status is used to track whether the game is scheduled, played, conceded etc.
 	// See the GameStateXYZ constants for potential values.
 	Status games.GameState `json:"status,omitempty" example:"1" doc:"The current state of the game, indicating whether it is scheduled, in progress, completed etc."`
+
+	// ConcedingSideID is the identifier of the player who conceded the game, only set when the Status is
+	// GameStateConceded
+	ConcedingSideID players.PlayerID `json:"concedingSideId,omitempty" example:"5678" doc:"The unique identifier of the side which conceded the game, if it was conceded"`
+
+	// Version is set by the service, starting at 1 and going up by one with every change to the game. A write which
+	// gives the version it was based on is rejected with svcerrors.ErrVersionConflict if the game has changed since,
+	// zero skips the check.
+	Version int64 `json:"version,omitempty" example:"3" doc:"The version of the game, set by the service and increased with every change"`
+
+	// Reports holds the result reported by each side, at most one per side. See GameStateAwaitingConfirmation and
+	// GameStateDisputed for how the reports move the game towards GameStatePlayCompleted.
+	Reports []ResultReport `json:"reports,omitempty" doc:"The result reported by each side, at most one per side"`
+
+	// Resolution records how an organizer settled the game if its reports did not agree
+	Resolution *Resolution `json:"resolution,omitempty" doc:"How an organizer settled the game if the reports of the sides did not agree"`
+
+	// DeletedAt is the tombstone of a deleted game, set by the service when the game is deleted. Deleted games are
+	// left out of reads and listings until they are restored, or purged for good.
+	DeletedAt time.Time `json:"deletedAt,omitzero" doc:"When the game was deleted, only set on deleted games"`
+}
+
+// IsDeleted returns true if the game has been deleted, but not yet purged
+func (g *Game) IsDeleted() bool {
+	return !g.DeletedAt.IsZero()
+}
+
+// GameResult holds the outcome of a played game, used when reporting or resolving a game without having to send the
+// whole Game
+type GameResult struct {
+	// Side1TotalVictoryPoints is the total victory points scored by the first side in the game
+	Side1TotalVictoryPoints int `json:"side1TotalVictoryPoints" example:"12" doc:"The total number of victory points scored by the first player"`
+
+	// Side2TotalVictoryPoints is the total victory points scored by the second side in the game
+	Side2TotalVictoryPoints int `json:"side2TotalVictoryPoints" example:"4" doc:"The total number of victory points scored by the second player"`
+
+	// Side1KilledGeneral is true if the side 1 player killed the opposing general
+	Side1KilledGeneral bool `json:"side1KilledGeneral,omitempty" example:"true" doc:"True if the first player killed the opposing general, false otherwise"`
+
+	// Side2KilledGeneral is true if the side 2 player killed the opposing general
+	Side2KilledGeneral bool `json:"side2KilledGeneral,omitempty" example:"false" doc:"True if the second player killed the opposing general, false otherwise"`
+
+	// Side1Breakdown is the optional detail of how the game went for the first side
+	Side1Breakdown *SideBreakdown `json:"side1Breakdown,omitempty" doc:"The optional detail of how the game went for the first side, such as heroes slain and models removed"`
+
+	// Side2Breakdown is the optional detail of how the game went for the second side
+	Side2Breakdown *SideBreakdown `json:"side2Breakdown,omitempty" doc:"The optional detail of how the game went for the second side, such as heroes slain and models removed"`
+}
+
+// ApplyTo copies the result onto the given game, leaving everything else about the game untouched
+func (r *GameResult) ApplyTo(g *Game) {
+	g.Side1TotalVictoryPoints = r.Side1TotalVictoryPoints
+	g.Side2TotalVictoryPoints = r.Side2TotalVictoryPoints
+	g.Side1KilledGeneral = r.Side1KilledGeneral
+	g.Side2KilledGeneral = r.Side2KilledGeneral
+	g.Side1Breakdown = r.Side1Breakdown
+	g.Side2Breakdown = r.Side2Breakdown
+}
+
+// Result returns the outcome recorded on the game, the reverse of GameResult.ApplyTo
+func (g *Game) Result() GameResult {
+	return GameResult{
+		Side1TotalVictoryPoints: g.Side1TotalVictoryPoints,
+		Side2TotalVictoryPoints: g.Side2TotalVictoryPoints,
+		Side1KilledGeneral:      g.Side1KilledGeneral,
+		Side2KilledGeneral:      g.Side2KilledGeneral,
+		Side1Breakdown:          g.Side1Breakdown,
+		Side2Breakdown:          g.Side2Breakdown,
+	}
 }
 
 // IsValid checks if the game instance has all required fields set and returns a boolean indicating validity. A slice
@@ -53,7 +129,19 @@ func (g *Game) IsValid() (bool, []string, error) {
 		return false, invalidFields, svcerrors.ErrModelMissing
 	}
 
-	if g.Side1ID == "" || g.Side2ID == "" || g.Status == 0 {
+	// A bye has nobody on the other side, every other game needs both
+	side2Missing := g.Side2ID == "" && g.Status != games.GameStateBye
+	concederInvalid := g.ConcedingSideID != "" && g.ConcedingSideID != g.Side1ID && g.ConcedingSideID != g.Side2ID
+	reportsInvalid := !g.reportsValid()
+	breakdowns := breakdownProblems(g.Side1Breakdown, g.Side2Breakdown, g.Side1TotalVictoryPoints, g.Side2TotalVictoryPoints)
+	for _, r := range g.Reports {
+		res := r.Result
+		for _, p := range breakdownProblems(res.Side1Breakdown, res.Side2Breakdown, res.Side1TotalVictoryPoints, res.Side2TotalVictoryPoints) {
+			breakdowns = append(breakdowns, "Reports['"+string(r.ReporterID)+"']."+p)
+		}
+	}
+
+	if g.Side1ID == "" || side2Missing || !g.Status.IsValid() || concederInvalid || reportsInvalid || len(breakdowns) > 0 {
 		j, err := json.Marshal(g)
 		if err != nil {
 			slog.Error("Unable to marshall the game instance to json", "func", "IsValid", "error", err.Error())
@@ -63,15 +151,34 @@ func (g *Game) IsValid() (bool, []string, error) {
 		if g.Side1ID == "" {
 			invalidFields = append(invalidFields, "Side1ID='"+string(g.Side1ID)+"'")
 		}
-		if g.Side2ID == "" {
+		if side2Missing {
 			invalidFields = append(invalidFields, "Side2ID='"+string(g.Side2ID)+"'")
 		}
-		if g.Status == 0 {
-			invalidFields = append(invalidFields, "Status is not set")
+		if !g.Status.IsValid() {
+			invalidFields = append(invalidFields, "Status is not a known game state: "+g.Status.String())
+		}
+		if concederInvalid {
+			invalidFields = append(invalidFields, "ConcedingSideID='"+string(g.ConcedingSideID)+"' is not one of the sides")
+		}
+		if reportsInvalid {
+			invalidFields = append(invalidFields, "Reports must come from the sides of the game, at most one each")
 		}
+		invalidFields = append(invalidFields, breakdowns...)
 
 		return false, invalidFields, nil
 	} else {
 		return true, invalidFields, nil
 	}
 }
+
+// reportsValid returns true if every result report comes from one of the sides, with no side reporting twice
+func (g *Game) reportsValid() bool {
+	seen := map[players.PlayerID]bool{}
+	for _, r := range g.Reports {
+		if r.ReporterID == "" || (r.ReporterID != g.Side1ID && r.ReporterID != g.Side2ID) || seen[r.ReporterID] {
+			return false
+		}
+		seen[r.ReporterID] = true
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	"slices"
+	"time"
+)
+
+// HistoryAction says what kind of write a HistoryEntry records
+type HistoryAction string
+
+const (
+	// HistoryActionCreated records the game being created
+	HistoryActionCreated HistoryAction = "created"
+
+	// HistoryActionUpdated records any change to an existing game, including moves through its lifecycle
+	HistoryActionUpdated HistoryAction = "updated"
+
+	// HistoryActionDeleted records the game being deleted
+	HistoryActionDeleted HistoryAction = "deleted"
+
+	// HistoryActionRestored records a deleted game being restored
+	HistoryActionRestored HistoryAction = "restored"
+
+	// HistoryActionPurged records a deleted game being removed for good, its history is all that is left of it
+	HistoryActionPurged HistoryAction = "purged"
+)
+
+// HistoryEntry is an immutable record of one write to a game, kept so that any change can be traced back to who
+// made it and what the game looked like before.
+type HistoryEntry struct {
+	// GameID identifies the game which was written
+	GameID games.GameID `json:"gameId" example:"1234" doc:"The unique identifier for the game which was written"`
+
+	// Version is the version of the game the write produced
+	Version int64 `json:"version" example:"3" doc:"The version of the game the write produced"`
+
+	// Action is what kind of write was made
+	Action HistoryAction `json:"action" enum:"created,updated,deleted,restored,purged" example:"updated" doc:"What kind of write was made"`
+
+	// At is when the write was made
+	At time.Time `json:"at" doc:"When the write was made"`
+
+	// Actor is whoever made the write, empty if the caller did not identify itself
+	Actor string `json:"actor" example:"5678" doc:"Whoever made the write, empty if the caller did not identify itself"`
+
+	// Reason is why the write was made, if one was given
+	Reason string `json:"reason,omitempty" example:"Result entered against the wrong game" doc:"Why the write was made, if a reason was given"`
+
+	// Changes holds the previous and new value of every field the write changed
+	Changes []FieldChange `json:"changes" doc:"The previous and new value of every field the write changed"`
+}
+
+// FieldChange is the previous and new value of one field of a game, in their JSON form. A value is left out when
+// the field was not set, such as before the game was created.
+type FieldChange struct {
+	// Field is the JSON name of the field
+	Field string `json:"field" example:"side1TotalVictoryPoints" doc:"The JSON name of the field which changed"`
+
+	// From is the value of the field before the write
+	From json.RawMessage `json:"from,omitempty" doc:"The value of the field before the write, left out if it was not set"`
+
+	// To is the value of the field after the write
+	To json.RawMessage `json:"to,omitempty" doc:"The value of the field after the write, left out if it is no longer set"`
+}
+
+// DiffGames returns the fields which differ between the two versions of a game, ordered by field name. Either game
+// may be nil, for a game being created or purged. The Version is left out as it changes with every write.
+func DiffGames(before, after *Game) ([]FieldChange, error) {
+	from, err := gameFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := gameFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	changes := []FieldChange{}
+	for _, name := range names {
+		if name != "version" && !bytes.Equal(from[name], to[name]) {
+			changes = append(changes, FieldChange{Field: name, From: from[name], To: to[name]})
+		}
+	}
+	return changes, nil
+}
+
+// gameFields splits the JSON form of the game into its fields, a nil game has none
+func gameFields(g *Game) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if g == nil {
+		return fields, nil
+	}
+
+	j, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	return fields, json.Unmarshal(j, &fields)
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+)
+
+// DefaultListLimit is the number of games returned in one page of a listing when the caller does not ask for a
+// specific page size
+const DefaultListLimit = 50
+
+// MaxListLimit is the largest page size which will be honoured when listing games, larger requests are capped
+const MaxListLimit = 200
+
+// GameQuery holds the filters and paging information used when listing games. Any filter left at its zero value
+// is not applied, so an empty GameQuery returns the first page of all games.
+type GameQuery struct {
+	// RoundID limits the results to games which are part of the given round
+	RoundID rounds.RoundID
+
+	// SideID limits the results to games where the given player is on either side
+	SideID players.PlayerID
+
+	// Statuses limits the results to games in any of the given states
+	Statuses []games.GameState
+
+	// IncludeDeleted adds deleted games which have not been purged yet to the results, they are left out otherwise
+	IncludeDeleted bool
+
+	// Cursor is the opaque value returned as NextCursor by a previous page, used to fetch the page after it.
+	// Leave empty to start from the first page.
+	Cursor string
+
+	// Limit is the maximum number of games to return in the page, see DefaultListLimit and MaxListLimit
+	Limit int
+}
+
+// PageLimit returns the page size to use for the query, applying the default and the maximum
+func (q GameQuery) PageLimit() int {
+	if q.Limit <= 0 {
+		return DefaultListLimit
+	} else if q.Limit > MaxListLimit {
+		return MaxListLimit
+	}
+	return q.Limit
+}
+
+// Matches returns true if the given game passes all the filters set on the query. Paging fields are not considered.
+func (q GameQuery) Matches(g *Game) bool {
+	if g == nil || (g.IsDeleted() && !q.IncludeDeleted) {
+		return false
+	}
+	if q.RoundID != "" && g.RoundID != q.RoundID {
+		return false
+	}
+	if q.SideID != "" && g.Side1ID != q.SideID && g.Side2ID != q.SideID {
+		return false
+	}
+	if len(q.Statuses) > 0 {
+		for _, s := range q.Statuses {
+			if g.Status == s {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// GameList is one page of games returned from a listing, in a stable order (ascending by ID).
+type GameList struct {
+	// Games holds the games in this page, it is empty rather than nil when nothing matches
+	Games []Game `json:"games" doc:"The games in this page of results, ordered by ID"`
+
+	// NextCursor is set when there are more games after this page, pass it back as the cursor to fetch them
+	NextCursor string `json:"nextCursor,omitempty" example:"MTI" doc:"Opaque cursor for the next page, absent when this is the last page"`
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"time"
+)
+
+// ResultReport is one side's account of how a game ended. Each side reports separately, and the game is only
+// completed once the reports of both sides agree.
+type ResultReport struct {
+	// ReporterID is the identifier of the side submitting the report, it must be one of the sides in the game
+	ReporterID players.PlayerID `json:"reporterId" example:"5678" doc:"The unique identifier of the side submitting the report"`
+
+	// Result is the outcome of the game as the reporting side saw it
+	Result GameResult `json:"result" doc:"The outcome of the game according to the reporting side"`
+
+	// SubmittedAt is when the report was last submitted, set by the service
+	SubmittedAt time.Time `json:"submittedAt,omitzero" doc:"When the report was last submitted, set by the service"`
+}
+
+// Agrees returns true if the two reports give the same result for the game: the same victory points for each side
+// and the same generals killed. The breakdowns do not have to agree, each side knows its own game best.
+func (r *ResultReport) Agrees(other *ResultReport) bool {
+	a, b := r.Result, other.Result
+	return a.Side1TotalVictoryPoints == b.Side1TotalVictoryPoints && a.Side2TotalVictoryPoints == b.Side2TotalVictoryPoints &&
+		a.Side1KilledGeneral == b.Side1KilledGeneral && a.Side2KilledGeneral == b.Side2KilledGeneral
+}
+
+// AgreedResult returns the result settled on by the agreeing reports of the first and second sides of a game. The
+// breakdown of each side is taken from its own report, or from the other side's report if it did not give one.
+func AgreedResult(side1, side2 *ResultReport) GameResult {
+	result := side1.Result
+	if result.Side2Breakdown = side2.Result.Side2Breakdown; result.Side2Breakdown == nil {
+		result.Side2Breakdown = side1.Result.Side2Breakdown
+	}
+	if result.Side1Breakdown == nil {
+		result.Side1Breakdown = side2.Result.Side1Breakdown
+	}
+	return result
+}
+
+// Resolution records how an organizer settled a game whose result reports did not agree
+type Resolution struct {
+	// ResolvedBy is the identifier of the organizer who resolved the dispute
+	ResolvedBy players.PlayerID `json:"resolvedBy" example:"4321" doc:"The unique identifier of the organizer who resolved the dispute"`
+
+	// Reason explains how the organizer arrived at the result
+	Reason string `json:"reason" example:"Both players agreed the objective was held at the end" doc:"Why the organizer settled on the result"`
+
+	// ResolvedAt is when the dispute was resolved, set by the service
+	ResolvedAt time.Time `json:"resolvedAt,omitzero" doc:"When the dispute was resolved, set by the service"`
+}
+
+// ReportBy returns the report submitted by the given side, or nil if that side has not reported
+func (g *Game) ReportBy(side players.PlayerID) *ResultReport {
+	for i := range g.Reports {
+		if g.Reports[i].ReporterID == side {
+			return &g.Reports[i]
+		}
+	}
+	return nil
+}
@@ -1,20 +1,44 @@
 package main
 
 import (
-	"github.com/rpatton4/mesbg-league/leagues/internal/primary"
-	"github.com/rpatton4/mesbg-league/leagues/internal/secondary"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	"github.com/rpatton4/mesbg-league/games/pkg/gateway"
+	padapters "github.com/rpatton4/mesbg-league/leagues/internal/primary"
+	sadapters "github.com/rpatton4/mesbg-league/leagues/internal/secondary"
+	roundsgateway "github.com/rpatton4/mesbg-league/rounds/pkg/gateway"
 	"log/slog"
 	"net/http"
+	"os"
 )
 
+var port = "8082"
+
 func main() {
-	slog.Info("Starting the Leagues service...")
-	repo := secondary.New()
-	ctrl := primary.New(repo)
-	handler := primary.NewHandler(ctrl)
+	logHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
+	slog.SetDefault(slog.New(logHandler))
+
+	slog.Info("Starting the Leagues service on port " + port)
+	gamesAddr := os.Getenv("GAMES_SERVICE_ADDR")
+	if gamesAddr == "" {
+		gamesAddr = "http://localhost:8081"
+	}
+	roundsAddr := os.Getenv("ROUNDS_SERVICE_ADDR")
+	if roundsAddr == "" {
+		roundsAddr = "http://localhost:8085"
+	}
+
+	repo := sadapters.NewDefaultRepository()
+	ctrl := padapters.NewTxnController(repo, gateway.New(gamesAddr), roundsgateway.New(roundsAddr))
+	handler := padapters.NewHumaHandler(ctrl)
+
+	router := http.NewServeMux()
+
+	api := humago.New(router, huma.DefaultConfig("Leagues Service", "1.0.0"))
+
+	padapters.RegisterRoutes(api, handler)
 
-	http.Handle("/leagues", http.HandlerFunc(handler.GetLeague))
-	if err := http.ListenAndServe(":8082", nil); err != nil {
+	if err := http.ListenAndServe(":"+port, router); err != nil {
 		slog.Error("Failed to start HTTP server", "error", err.Error())
 		panic(err)
 	}
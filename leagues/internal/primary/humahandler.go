@@ -0,0 +1,312 @@
+package primary
+
+import (
+	"context"
+	"errors"
+	"github.com/danielgtaylor/huma/v2"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// HumaHandler defines the HTTP handler (adapter) for Leagues operations received via HTTP(S).
+type HumaHandler struct {
+	ctrl SingleController
+}
+
+// <editor-fold desc="I/O Struct Definitions">
+
+// Huma requires structs for both the input and output of each function registered as a handler for an HTTP operation.
+// See the games service HumaHandler for the conventions followed here.
+// Please keep the structs organized, with request and then response for any operation together
+
+// GetByIDRequest defines the input for the GetByID operation.
+type GetByIDRequest struct {
+	// ID is the unique identifier for the league to retrieve, taken from the path /leagues/{id}
+	ID leagues.LeagueID `path:"id" example:"1" doc:"The unique identifier for the league to retrieve"`
+}
+
+// GetByIDResponse defines the output for the GetByID operation.
+type GetByIDResponse struct {
+	// Body holds the league with the requested ID, Huma will marshall this to JSON for the HTTP response
+	Body model.League
+}
+
+// PostRequest defines the input for the Post operation, which creates a new league.
+type PostRequest struct {
+	// Body holds the info for the league to be created
+	Body *model.League
+}
+
+// PostResponse defines the output for the Post operation.
+type PostResponse struct {
+	// Body holds the newly created league, including its assigned ID, Huma will marshall this to JSON for the HTTP response
+	Body model.League
+}
+
+// PutRequest defines the input for the Put operation, which replaces a league.
+type PutRequest struct {
+	// ID is the unique identifier for the league to update, taken from the path /leagues/{id}
+	ID leagues.LeagueID `path:"id" example:"1" doc:"The unique identifier for the league to update"`
+
+	// Body holds the League model to replace the league with the ID from the path
+	Body *model.League
+}
+
+// PutResponse defines the output for the Put operation.
+type PutResponse struct {
+	// Body holds the updated league, Huma will marshall this to JSON for the HTTP response
+	Body model.League
+}
+
+// DeleteRequest defines the input for the Delete operation.
+type DeleteRequest struct {
+	// ID is the unique identifier for the league to delete, taken from the path /leagues/{id}
+	ID leagues.LeagueID `path:"id" example:"1" doc:"The unique identifier for the league to delete"`
+}
+
+// ListRequest defines the input for the List operation, all of the filters are optional and are taken from the query
+type ListRequest struct {
+	// Active limits the results to active or ended leagues
+	Active string `query:"active" enum:"true,false" doc:"Only return leagues which are active (true) or have ended (false)"`
+
+	// Cursor is the value of nextCursor from a previous page, used to continue the listing
+	Cursor string `query:"cursor" doc:"The nextCursor value from the previous page, omit to start at the first page"`
+
+	// Limit is the maximum number of leagues to return in the page
+	Limit int `query:"limit" default:"50" minimum:"1" maximum:"200" doc:"The maximum number of leagues to return in one page"`
+}
+
+// ListResponse defines the output for the List operation.
+type ListResponse struct {
+	// Body holds the page of leagues and the cursor for the next page
+	Body model.LeagueList
+}
+
+// StandingsRequest defines the input for the Standings operation.
+type StandingsRequest struct {
+	// ID is the unique identifier for the league, taken from the path /leagues/{id}/standings
+	ID leagues.LeagueID `path:"id" example:"1" doc:"The unique identifier for the league to rank"`
+}
+
+// StandingsResponse defines the output for the Standings operation.
+type StandingsResponse struct {
+	// Body holds the ranked standings table
+	Body model.Standings
+}
+
+// PairingsRequest defines the input for the Pairings operation, which pairs a round of the league.
+type PairingsRequest struct {
+	// ID is the unique identifier for the league, taken from the path
+	ID leagues.LeagueID `path:"id" example:"1" doc:"The unique identifier for the league the round is in"`
+
+	// Number is the number of the round to pair, taken from the path
+	Number int `path:"number" minimum:"1" example:"2" doc:"The number of the round to pair"`
+
+	// DryRun returns the proposed pairings without creating any games when true
+	DryRun bool `query:"dryRun" doc:"Return the proposed pairings without creating the round or its games"`
+}
+
+// PairingsResponse defines the output for the Pairings operation.
+type PairingsResponse struct {
+	// Status is 201 when the games were created, 200 for a dry run
+	Status int
+
+	// Body holds the pairings, and the games created for them
+	Body model.RoundPairings
+}
+
+// ScheduleRequest defines the input for the Schedule operation, which creates a round-robin for the league.
+type ScheduleRequest struct {
+	// ID is the unique identifier for the league, taken from the path
+	ID leagues.LeagueID `path:"id" example:"1" doc:"The unique identifier for the league to schedule"`
+
+	// Double makes every pair of players meet twice, with the sides swapped
+	Double bool `query:"double" doc:"Schedule a double round-robin, with each pair meeting twice"`
+}
+
+// ScheduleResponse defines the output for the Schedule operation.
+type ScheduleResponse struct {
+	// Status is 201 as the rounds and games were created
+	Status int
+
+	// Body holds the rounds created, with their games
+	Body model.Schedule
+}
+
+//</editor-fold>
+
+// NewHumaHandler creates a new instance of the HTTP handler for league operations.
+func NewHumaHandler(c SingleController) *HumaHandler {
+	return &HumaHandler{ctrl: c}
+}
+
+// GetByID queries the controller for the league with the ID taken from the path, returns it if found
+// 404 is returned if no such league exists
+// 400 is returned if the league ID is invalid
+func (h *HumaHandler) GetByID(ctx context.Context, req *GetByIDRequest) (*GetByIDResponse, error) {
+	slog.Debug("GetByID called", "leagueID", req.ID)
+
+	l, err := h.ctrl.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, leagueError("get", req.ID, err)
+	}
+
+	return &GetByIDResponse{
+		Body: *l,
+	}, nil
+}
+
+// Post reads the league JSON from the HTTP call and sends it on to the controller to create the league
+// 400 is returned if the league is missing or invalid
+func (h *HumaHandler) Post(ctx context.Context, req *PostRequest) (*PostResponse, error) {
+	slog.Debug("Post called", "PostRequest Body", req.Body)
+
+	l, err := h.ctrl.Create(ctx, req.Body)
+	if err != nil {
+		return nil, leagueError("create", "", err)
+	}
+
+	slog.Debug("Created league", "league", l)
+	return &PostResponse{
+		Body: *l,
+	}, nil
+}
+
+// Put reads the league JSON from the HTTP call and sends it on to the controller to fully update the league with
+// the ID from the path. The ID in the body may be left out, but if it is given it has to match the path.
+// 400 is returned if the league is missing or invalid
+// 404 is returned if no such league exists
+func (h *HumaHandler) Put(ctx context.Context, req *PutRequest) (*PutResponse, error) {
+	slog.Debug("Put called", "leagueID", req.ID, "PutRequest Body", req.Body)
+
+	if req.Body != nil {
+		if req.Body.ID == "" {
+			req.Body.ID = req.ID
+		} else if req.Body.ID != req.ID {
+			return nil, huma.Error400BadRequest("the league ID in the body '" + string(req.Body.ID) + "' does not match the path '" + string(req.ID) + "'")
+		}
+	}
+
+	l, err := h.ctrl.Replace(ctx, req.Body)
+	if err != nil {
+		return nil, leagueError("update", req.ID, err)
+	}
+
+	slog.Debug("Updated league", "league", l)
+	return &PutResponse{
+		Body: *l,
+	}, nil
+}
+
+// Delete deletes the league with the ID from the path.
+// 404 is returned if no such league exists
+func (h *HumaHandler) Delete(ctx context.Context, req *DeleteRequest) (*struct{}, error) {
+	slog.Debug("Delete called", "leagueID", req.ID)
+
+	if _, err := h.ctrl.DeleteByID(ctx, req.ID); err != nil {
+		if errors.Is(err, svcerrors.ErrInvalidID) {
+			err = svcerrors.ErrNotFound
+		}
+		return nil, leagueError("delete", req.ID, err)
+	}
+	return nil, nil
+}
+
+// List queries the controller for a page of leagues matching the filters from the query string
+// 400 is returned if the filters or cursor are invalid
+func (h *HumaHandler) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	slog.Debug("List called", "active", req.Active, "cursor", req.Cursor)
+
+	q := model.LeagueQuery{Cursor: req.Cursor, Limit: req.Limit}
+	if req.Active != "" {
+		active, err := strconv.ParseBool(req.Active)
+		if err != nil {
+			return nil, huma.Error400BadRequest("the active filter must be true or false: " + err.Error())
+		}
+		q.Active = &active
+	}
+
+	l, err := h.ctrl.List(ctx, q)
+	if err != nil {
+		return nil, leagueError("list", "", err)
+	}
+
+	return &ListResponse{
+		Body: *l,
+	}, nil
+}
+
+// Standings computes the standings table for the league with the ID from the path
+// 404 is returned if no such league exists
+func (h *HumaHandler) Standings(ctx context.Context, req *StandingsRequest) (*StandingsResponse, error) {
+	slog.Debug("Standings called", "leagueID", req.ID)
+
+	s, err := h.ctrl.Standings(ctx, req.ID)
+	if err != nil {
+		return nil, leagueError("rank", req.ID, err)
+	}
+
+	return &StandingsResponse{
+		Body: *s,
+	}, nil
+}
+
+// Pairings generates Swiss pairings for the round number and league ID from the path, creating the games for them
+// unless this is a dry run
+// 400 is returned if the league cannot be paired, for example with fewer than two participants
+// 404 is returned if no such league exists
+// 409 is returned if the round already has games
+func (h *HumaHandler) Pairings(ctx context.Context, req *PairingsRequest) (*PairingsResponse, error) {
+	slog.Debug("Pairings called", "leagueID", req.ID, "round", req.Number, "dryRun", req.DryRun)
+
+	p, err := h.ctrl.GeneratePairings(ctx, req.ID, req.Number, req.DryRun)
+	if err != nil {
+		return nil, leagueError("pair round "+strconv.Itoa(req.Number)+" of", req.ID, err)
+	}
+
+	status := http.StatusCreated
+	if req.DryRun {
+		status = http.StatusOK
+	}
+	return &PairingsResponse{
+		Status: status,
+		Body:   *p,
+	}, nil
+}
+
+// Schedule generates the full round-robin schedule for the league with the ID from the path
+// 400 is returned if the league cannot be scheduled, for example if the rounds do not fit between its dates
+// 404 is returned if no such league exists
+// 409 is returned if the league already has games
+func (h *HumaHandler) Schedule(ctx context.Context, req *ScheduleRequest) (*ScheduleResponse, error) {
+	slog.Debug("Schedule called", "leagueID", req.ID, "double", req.Double)
+
+	s, err := h.ctrl.GenerateSchedule(ctx, req.ID, req.Double)
+	if err != nil {
+		return nil, leagueError("schedule", req.ID, err)
+	}
+
+	return &ScheduleResponse{
+		Status: http.StatusCreated,
+		Body:   *s,
+	}, nil
+}
+
+// leagueError maps an error from the controller to the HTTP response, so that every operation reports errors with
+// the same status codes
+func leagueError(action string, id leagues.LeagueID, err error) error {
+	slog.Error("Unable to "+action+" the league", "leagueID", id, "error", err)
+	if errors.Is(err, svcerrors.ErrNotFound) {
+		return huma.Error404NotFound("No such league exists")
+	} else if errors.Is(err, svcerrors.ErrConflict) {
+		return huma.Error409Conflict("unable to " + action + " the league in its current state: " + err.Error())
+	} else if errors.Is(err, svcerrors.ErrInvalidID) || errors.Is(err, svcerrors.ErrModelMissing) ||
+		errors.Is(err, svcerrors.ErrModelInvalid) || errors.Is(err, svcerrors.ErrInvalidQuery) {
+		return huma.Error400BadRequest("client sent an invalid request to " + action + " the league: " + err.Error())
+	}
+	return huma.Error500InternalServerError("error while trying to " + action + " the league: " + err.Error())
+}
@@ -0,0 +1,154 @@
+package primary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/danielgtaylor/huma/v2"
+	mock_primary "github.com/rpatton4/mesbg-league/leagues/internal/primary/mocks"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"testing"
+)
+
+func TestHumaHandlerMockedGetByID(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	mockController.EXPECT().GetByID(gomock.Any(), leagues.LeagueID("1")).Return(&model.League{ID: "1", Name: "Test League"}, nil).Times(1)
+	mockController.EXPECT().GetByID(gomock.Any(), leagues.LeagueID("999")).Return(nil, svcerrors.ErrNotFound).Times(1)
+
+	res, err := handler.GetByID(context.Background(), &GetByIDRequest{ID: "1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Body.ID != "1" {
+		t.Errorf("expected league ID '1', got '%s'", res.Body.ID)
+	}
+
+	_, err = handler.GetByID(context.Background(), &GetByIDRequest{ID: "999"})
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestHumaHandlerMockedPost(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	valid := &model.League{Name: "Test League"}
+	invalid := &model.League{}
+	mockController.EXPECT().Create(gomock.Any(), valid).Return(&model.League{ID: "1", Name: "Test League"}, nil).Times(1)
+	mockController.EXPECT().Create(gomock.Any(), invalid).Return(nil, fmt.Errorf("league %w: Name is required", svcerrors.ErrModelInvalid)).Times(1)
+	mockController.EXPECT().Create(gomock.Any(), nil).Return(nil, svcerrors.ErrModelMissing).Times(1)
+
+	res, err := handler.Post(context.Background(), &PostRequest{Body: valid})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Body.ID != "1" {
+		t.Errorf("expected the assigned league ID '1', got '%s'", res.Body.ID)
+	}
+
+	_, err = handler.Post(context.Background(), &PostRequest{Body: invalid})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	_, err = handler.Post(context.Background(), &PostRequest{})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestHumaHandlerMockedPut(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	// The ID from the path is used when the body does not have one
+	mockController.EXPECT().Replace(gomock.Any(), &model.League{ID: "1", Name: "Renamed"}).Return(&model.League{ID: "1", Name: "Renamed"}, nil).Times(1)
+	mockController.EXPECT().Replace(gomock.Any(), &model.League{ID: "999", Name: "Renamed"}).Return(nil, svcerrors.ErrNotFound).Times(1)
+
+	res, err := handler.Put(context.Background(), &PutRequest{ID: "1", Body: &model.League{Name: "Renamed"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Body.Name != "Renamed" {
+		t.Errorf("expected the updated league, got %+v", res.Body)
+	}
+
+	_, err = handler.Put(context.Background(), &PutRequest{ID: "999", Body: &model.League{Name: "Renamed"}})
+	assertStatus(t, err, http.StatusNotFound)
+
+	// A body for a different league is rejected without reaching the controller
+	_, err = handler.Put(context.Background(), &PutRequest{ID: "1", Body: &model.League{ID: "2", Name: "Renamed"}})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestHumaHandlerMockedDelete(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	mockController.EXPECT().DeleteByID(gomock.Any(), leagues.LeagueID("1")).Return(true, nil).Times(1)
+	mockController.EXPECT().DeleteByID(gomock.Any(), leagues.LeagueID("999")).Return(false, svcerrors.ErrNotFound).Times(1)
+
+	if _, err := handler.Delete(context.Background(), &DeleteRequest{ID: "1"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err := handler.Delete(context.Background(), &DeleteRequest{ID: "999"})
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestHumaHandlerMockedList(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	active := true
+	mockController.EXPECT().List(gomock.Any(), model.LeagueQuery{Active: &active, Limit: 10}).Return(&model.LeagueList{Leagues: []model.League{{ID: "1"}}}, nil).Times(1)
+	mockController.EXPECT().List(gomock.Any(), model.LeagueQuery{Cursor: "!!", Limit: 10}).Return(nil, svcerrors.ErrInvalidQuery).Times(1)
+
+	res, err := handler.List(context.Background(), &ListRequest{Active: "true", Limit: 10})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(res.Body.Leagues) != 1 {
+		t.Errorf("expected 1 league, got %d", len(res.Body.Leagues))
+	}
+
+	_, err = handler.List(context.Background(), &ListRequest{Cursor: "!!", Limit: 10})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestHumaHandlerMockedPairings(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_primary.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	mockController.EXPECT().GeneratePairings(gomock.Any(), leagues.LeagueID("1"), 2, true).Return(&model.RoundPairings{DryRun: true}, nil).Times(1)
+	mockController.EXPECT().GeneratePairings(gomock.Any(), leagues.LeagueID("1"), 2, false).Return(&model.RoundPairings{}, nil).Times(1)
+	mockController.EXPECT().GeneratePairings(gomock.Any(), leagues.LeagueID("1"), 1, false).Return(nil, svcerrors.ErrConflict).Times(1)
+
+	res, err := handler.Pairings(context.Background(), &PairingsRequest{ID: "1", Number: 2, DryRun: true})
+	if err != nil || res.Status != http.StatusOK {
+		t.Errorf("expected 200 for a dry run, got %v, %v", res, err)
+	}
+	res, err = handler.Pairings(context.Background(), &PairingsRequest{ID: "1", Number: 2})
+	if err != nil || res.Status != http.StatusCreated {
+		t.Errorf("expected 201 when the games are created, got %v, %v", res, err)
+	}
+	_, err = handler.Pairings(context.Background(), &PairingsRequest{ID: "1", Number: 1})
+	assertStatus(t, err, http.StatusConflict)
+}
+
+// assertStatus checks that the error from a handler is a Huma error with the given HTTP status
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var statusError huma.StatusError
+	if err == nil {
+		t.Errorf("expected an error with status %d, got nil", status)
+	} else if !errors.As(err, &statusError) || statusError.GetStatus() != status {
+		t.Errorf("expected an error with status %d, got %v", status, err)
+	}
+}
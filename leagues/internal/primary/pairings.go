@@ -0,0 +1,154 @@
+package primary
+
+import (
+	"cmp"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"slices"
+)
+
+// maxPairingSteps bounds the search for pairings without rematches, so a league where they cannot be avoided does
+// not have to try every possible combination before falling back to allowing them
+const maxPairingSteps = 100_000
+
+// SwissPairings pairs the players in the standings for the next round. Each player is paired with the unpaired
+// player closest to them on tournament points, starting from the top of the table, without pairing any two players
+// who have met in one of the previous games. With an odd number of players the lowest ranked player who has had the
+// fewest byes gets one. Rematches are only allowed when there is no way to avoid them, and are then flagged on the
+// pairing.
+func SwissPairings(s *model.Standings, previous []gamesmodel.Game) []model.Pairing {
+	met := map[[2]players.PlayerID]bool{}
+	for _, g := range previous {
+		if g.Status != games.GameStateCancelled && g.Side1ID != "" && g.Side2ID != "" {
+			met[[2]players.PlayerID{g.Side1ID, g.Side2ID}] = true
+			met[[2]players.PlayerID{g.Side2ID, g.Side1ID}] = true
+		}
+	}
+	p := &pairer{met: met}
+
+	rows := s.Rows
+	if len(rows)%2 == 0 {
+		pairs, ok := p.pair(rows, false)
+		if !ok {
+			pairs, _ = p.pair(rows, true)
+		}
+		return p.pairings(pairs, nil)
+	}
+
+	candidates := byeCandidates(rows)
+	for _, bye := range candidates {
+		// Each candidate gets the whole step budget, so a hard search for one does not rule out the rest
+		p.steps = 0
+		if pairs, ok := p.pair(without(rows, bye), false); ok {
+			return p.pairings(pairs, bye)
+		}
+	}
+	pairs, _ := p.pair(without(rows, candidates[0]), true)
+	return p.pairings(pairs, candidates[0])
+}
+
+// pairer searches for pairings, keeping track of which players have already met
+type pairer struct {
+	met   map[[2]players.PlayerID]bool
+	steps int
+}
+
+// pair pairs the rows, which are in rank order, by backtracking through the closest opponents for each player in
+// turn. When rematches are allowed the first complete pairing is taken, preferring opponents not met before.
+func (p *pairer) pair(rows []model.StandingsRow, allowRematch bool) ([][2]model.StandingsRow, bool) {
+	if len(rows) == 0 {
+		return nil, true
+	}
+	if !allowRematch {
+		p.steps++
+		if p.steps > maxPairingSteps {
+			return nil, false
+		}
+	}
+
+	first, rest := rows[0], rows[1:]
+	order := make([]int, len(rest))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Or(
+			compareBool(p.rematch(first, rest[a]), p.rematch(first, rest[b])),
+			cmp.Compare(abs(first.TournamentPoints-rest[a].TournamentPoints), abs(first.TournamentPoints-rest[b].TournamentPoints)),
+		)
+	})
+
+	for _, i := range order {
+		if !allowRematch && p.rematch(first, rest[i]) {
+			continue
+		}
+		remaining := append(slices.Clone(rest[:i]), rest[i+1:]...)
+		if pairs, ok := p.pair(remaining, allowRematch); ok {
+			return append([][2]model.StandingsRow{{first, rest[i]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+// rematch returns true if the two players have met before
+func (p *pairer) rematch(a, b model.StandingsRow) bool {
+	return p.met[[2]players.PlayerID{a.PlayerID, b.PlayerID}]
+}
+
+// pairings numbers the pairs as tables, with the bye, if there is one, on the last table
+func (p *pairer) pairings(pairs [][2]model.StandingsRow, bye *model.StandingsRow) []model.Pairing {
+	ps := make([]model.Pairing, 0, len(pairs)+1)
+	for _, pair := range pairs {
+		ps = append(ps, model.Pairing{
+			Table:   len(ps) + 1,
+			Side1ID: pair[0].PlayerID,
+			Side2ID: pair[1].PlayerID,
+			Rematch: p.rematch(pair[0], pair[1]),
+		})
+	}
+	if bye != nil {
+		ps = append(ps, model.Pairing{Table: len(ps) + 1, Side1ID: bye.PlayerID, Bye: true})
+	}
+	return ps
+}
+
+// byeCandidates returns the rows in the order they should be considered for a bye: fewest byes first, and the
+// lowest ranked first among those with the same number
+func byeCandidates(rows []model.StandingsRow) []*model.StandingsRow {
+	candidates := make([]*model.StandingsRow, len(rows))
+	for i := range rows {
+		candidates[len(rows)-1-i] = &rows[i]
+	}
+	slices.SortStableFunc(candidates, func(a, b *model.StandingsRow) int {
+		return cmp.Compare(a.Byes, b.Byes)
+	})
+	return candidates
+}
+
+// without returns a copy of the rows leaving out the given player
+func without(rows []model.StandingsRow, r *model.StandingsRow) []model.StandingsRow {
+	return slices.DeleteFunc(slices.Clone(rows), func(o model.StandingsRow) bool {
+		return o.PlayerID == r.PlayerID
+	})
+}
+
+// compareBool orders false before true
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
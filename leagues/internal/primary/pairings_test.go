@@ -0,0 +1,167 @@
+package primary
+
+import (
+	"context"
+	"errors"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"testing"
+)
+
+func TestSwissPairingsClosestScores(t *testing.T) {
+	l := createFakeLeague("a", "b", "c", "d")
+	gs := []gamesmodel.Game{
+		{ID: "1", Side1ID: "a", Side2ID: "c", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+		{ID: "2", Side1ID: "b", Side2ID: "d", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+	}
+
+	ps := SwissPairings(ComputeStandings(l, gs), gs)
+	assertPairings(t, ps, [][2]string{{"a", "b"}, {"c", "d"}})
+}
+
+func TestSwissPairingsAvoidsRematches(t *testing.T) {
+	l := createFakeLeague("a", "b", "c", "d")
+	gs := []gamesmodel.Game{
+		{ID: "1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+		{ID: "2", Side1ID: "c", Side2ID: "d", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+		{ID: "3", Side1ID: "a", Side2ID: "c", Status: games.GameStateCancelled},
+	}
+
+	// a and c are the closest on points, but the cancelled game between them does not count as meeting
+	ps := SwissPairings(ComputeStandings(l, gs), gs)
+	assertPairings(t, ps, [][2]string{{"a", "c"}, {"b", "d"}})
+
+	// Once they have met, b and d have to play someone else as well
+	gs[2].Status = games.GameStatePlayCompleted
+	gs[2].Side1TotalVictoryPoints = 6
+	ps = SwissPairings(ComputeStandings(l, gs), gs)
+	assertPairings(t, ps, [][2]string{{"a", "d"}, {"c", "b"}})
+	for _, p := range ps {
+		if p.Rematch {
+			t.Errorf("Expected no rematches, got %+v", p)
+		}
+	}
+}
+
+func TestSwissPairingsForcedRematch(t *testing.T) {
+	l := createFakeLeague("a", "b")
+	gs := []gamesmodel.Game{
+		{ID: "1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+	}
+
+	ps := SwissPairings(ComputeStandings(l, gs), gs)
+	assertPairings(t, ps, [][2]string{{"a", "b"}})
+	if !ps[0].Rematch {
+		t.Errorf("Expected the only possible pairing to be flagged as a rematch")
+	}
+}
+
+func TestSwissPairingsOddCountGetsBye(t *testing.T) {
+	l := createFakeLeague("a", "b", "c")
+	ps := SwissPairings(ComputeStandings(l, nil), nil)
+	assertPairings(t, ps, [][2]string{{"a", "b"}, {"c", ""}})
+
+	// c has had a bye already, so the next lowest ranked player who has not gets it
+	gs := []gamesmodel.Game{
+		{ID: "1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+		{ID: "2", Side1ID: "c", Status: games.GameStateBye},
+	}
+	ps = SwissPairings(ComputeStandings(l, gs), gs)
+	assertPairings(t, ps, [][2]string{{"a", "c"}, {"b", ""}})
+	if !ps[1].Bye || ps[1].Table != 2 {
+		t.Errorf("Expected the bye on the last table, got %+v", ps[1])
+	}
+}
+
+func TestControllerGeneratePairings(t *testing.T) {
+	l := createFakeLeague("a", "b", "c")
+	src := &stubGamesGateway{}
+	ctrl := newTestController(t, l, src)
+	r1, err := ctrl.rounds.Create(context.Background(), &rounds.Round{LeagueID: l.ID, Number: 1})
+	if err != nil {
+		t.Fatalf("Unable to create the first round: %v", err)
+	}
+	src.pages = map[string][]gamesmodel.GameList{
+		string(r1.ID): {{Games: []gamesmodel.Game{
+			{ID: "1", Side1ID: "a", Side2ID: "b", RoundID: r1.ID, Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+			{ID: "2", Side1ID: "c", RoundID: r1.ID, Status: games.GameStateBye},
+		}}},
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		rp, err := ctrl.GeneratePairings(context.Background(), l.ID, 2, true)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !rp.DryRun || len(rp.Pairings) != 2 || len(rp.Games) != 0 || len(src.created) != 0 {
+			t.Errorf("Expected proposed pairings only, got %+v with %d games created", rp, len(src.created))
+		}
+		if stored, _ := ctrl.GetByID(context.Background(), l.ID); len(stored.RoundIDs) != 0 || len(leagueRoundsOf(t, ctrl, l.ID)) != 1 {
+			t.Errorf("Expected the league and its rounds to be left alone on a dry run, got %v", stored.RoundIDs)
+		}
+	})
+
+	t.Run("CreateFailureRollsBack", func(t *testing.T) {
+		src.failAt = 2
+		defer func() { src.failAt, src.created, src.deleted = 0, nil, nil }()
+
+		if _, err := ctrl.GeneratePairings(context.Background(), l.ID, 2, false); err == nil {
+			t.Fatalf("Expected error when a game cannot be created, got nil")
+		}
+		if len(src.deleted) != 1 || src.deleted[0] != src.created[0].ID {
+			t.Errorf("Expected the game created before the failure to be deleted, got %v", src.deleted)
+		}
+		if rs := leagueRoundsOf(t, ctrl, l.ID); len(rs) != 1 {
+			t.Errorf("Expected the round created for the games to be deleted, got %+v", rs)
+		}
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		rp, err := ctrl.GeneratePairings(context.Background(), l.ID, 2, false)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(rp.Games) != 2 || rp.RoundID == "" || rp.RoundID == r1.ID || rp.Games[0].RoundID != rp.RoundID {
+			t.Fatalf("Expected 2 games in a new round, got %+v", rp)
+		}
+		if rp.Games[1].Status != games.GameStateBye || rp.Games[1].Side1ID != "b" {
+			t.Errorf("Expected a bye game for b, got %+v", rp.Games[1])
+		}
+
+		stored, _ := ctrl.GetByID(context.Background(), l.ID)
+		if len(stored.RoundIDs) != 2 || stored.RoundIDs[1] != rp.RoundID {
+			t.Errorf("Expected both rounds to be referred to by the league, got %v", stored.RoundIDs)
+		}
+		rs := leagueRoundsOf(t, ctrl, l.ID)
+		if len(rs) != 2 || rs[1].ID != rp.RoundID || rs[1].Number != 2 || len(rs[1].GameIDs) != 2 {
+			t.Errorf("Expected the new round to be created in the rounds service with its games, got %+v", rs)
+		}
+	})
+
+	t.Run("AlreadyPaired", func(t *testing.T) {
+		if _, err := ctrl.GeneratePairings(context.Background(), l.ID, 2, false); !errors.Is(err, svcerrors.ErrConflict) {
+			t.Errorf("Expected ErrConflict for a round which already has games, got %v", err)
+		}
+	})
+
+	t.Run("InvalidRoundNumber", func(t *testing.T) {
+		if _, err := ctrl.GeneratePairings(context.Background(), l.ID, 0, true); !errors.Is(err, svcerrors.ErrModelInvalid) {
+			t.Errorf("Expected ErrModelInvalid for round 0, got %v", err)
+		}
+	})
+}
+
+func assertPairings(t *testing.T, ps []model.Pairing, expected [][2]string) {
+	t.Helper()
+	if len(ps) != len(expected) {
+		t.Fatalf("Expected %d pairings, got %d: %+v", len(expected), len(ps), ps)
+	}
+	for i, e := range expected {
+		if string(ps[i].Side1ID) != e[0] || string(ps[i].Side2ID) != e[1] {
+			t.Errorf("Expected table %d to be %s v %s, got %s v %s", i+1, e[0], e[1], ps[i].Side1ID, ps[i].Side2ID)
+		}
+	}
+}
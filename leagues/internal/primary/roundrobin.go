@@ -0,0 +1,98 @@
+package primary
+
+import (
+	"fmt"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"time"
+)
+
+// RoundRobinPairings returns the pairings for every round of a round-robin between the given players, using the
+// circle method: the first player stays put while the rest rotate around them one place each round. With an odd
+// number of players the one who would face the empty seat gets a bye. With double set every pairing is repeated in
+// a second half of the rounds with the sides swapped.
+func RoundRobinPairings(ids []players.PlayerID, double bool) [][]model.Pairing {
+	seats := append([]players.PlayerID{}, ids...)
+	if len(seats)%2 == 1 {
+		seats = append(seats, "")
+	}
+	if len(seats) < 2 {
+		return nil
+	}
+
+	n := len(seats)
+	rounds := make([][]model.Pairing, 0, n-1)
+	for r := 0; r < n-1; r++ {
+		var ps []model.Pairing
+		var bye *model.Pairing
+		for i := 0; i < n/2; i++ {
+			side1, side2 := seats[i], seats[n-1-i]
+			// Swap the sides of the fixed player's game every other round, so they do not always have the same one
+			if i == 0 && r%2 == 1 {
+				side1, side2 = side2, side1
+			}
+
+			switch {
+			case side1 == "":
+				bye = &model.Pairing{Side1ID: side2, Bye: true}
+			case side2 == "":
+				bye = &model.Pairing{Side1ID: side1, Bye: true}
+			default:
+				ps = append(ps, model.Pairing{Table: len(ps) + 1, Side1ID: side1, Side2ID: side2})
+			}
+		}
+		if bye != nil {
+			bye.Table = len(ps) + 1
+			ps = append(ps, *bye)
+		}
+		rounds = append(rounds, ps)
+
+		// Rotate every seat but the first one place
+		seats = append(seats[:1], append([]players.PlayerID{seats[n-1]}, seats[1:n-1]...)...)
+	}
+
+	if double {
+		for _, ps := range rounds[:n-1] {
+			swapped := make([]model.Pairing, len(ps))
+			for i, p := range ps {
+				swapped[i] = p
+				if !p.Bye {
+					swapped[i].Side1ID, swapped[i].Side2ID = p.Side2ID, p.Side1ID
+				}
+			}
+			rounds = append(rounds, swapped)
+		}
+	}
+	return rounds
+}
+
+// RoundDates returns the dates for the given number of rounds of the league, one a week on the league's expected
+// day of the week, starting from the first such day on or after the start date. A svcerrors.ErrModelInvalid is
+// returned if the league's dates cannot be read or the rounds do not all fit before the end date.
+func RoundDates(l *model.League, count int) ([]string, error) {
+	start, err := time.Parse(model.DateLayout, l.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("the start date '%s' is not in YYYY-MM-DD format. Source: %w", l.StartDate, svcerrors.ErrModelInvalid)
+	}
+	end, err := time.Parse(model.DateLayout, l.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("the end date '%s' is not in YYYY-MM-DD format. Source: %w", l.EndDate, svcerrors.ErrModelInvalid)
+	}
+	day, ok := model.ParseWeekday(l.ExpectedDayOfWeek)
+	if !ok {
+		return nil, fmt.Errorf("the expected day of the week '%s' is not a day of the week. Source: %w", l.ExpectedDayOfWeek, svcerrors.ErrModelInvalid)
+	}
+
+	d := start.AddDate(0, 0, (int(day)-int(start.Weekday())+7)%7)
+	dates := make([]string, 0, count)
+	for range count {
+		if d.After(end) {
+			return nil, fmt.Errorf("only %d of the %d rounds fit on a %s between %s and %s. Source: %w",
+				len(dates), count, day, l.StartDate, l.EndDate, svcerrors.ErrModelInvalid)
+		}
+		dates = append(dates, d.Format(model.DateLayout))
+		d = d.AddDate(0, 0, 7)
+	}
+	return dates, nil
+}
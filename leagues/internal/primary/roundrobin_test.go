@@ -0,0 +1,142 @@
+package primary
+
+import (
+	"context"
+	"errors"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"testing"
+)
+
+func TestRoundRobinPairings(t *testing.T) {
+	tests := []struct {
+		name         string
+		ids          []players.PlayerID
+		double       bool
+		rounds, byes int
+	}{
+		{name: "Even", ids: []players.PlayerID{"a", "b", "c", "d"}, rounds: 3},
+		{name: "Odd", ids: []players.PlayerID{"a", "b", "c", "d", "e"}, rounds: 5, byes: 1},
+		{name: "Double", ids: []players.PlayerID{"a", "b", "c", "d"}, double: true, rounds: 6},
+		{name: "DoubleOdd", ids: []players.PlayerID{"a", "b", "c"}, double: true, rounds: 6, byes: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := RoundRobinPairings(tt.ids, tt.double)
+			if len(rs) != tt.rounds {
+				t.Fatalf("Expected %d rounds, got %d", tt.rounds, len(rs))
+			}
+
+			meetings := map[[2]players.PlayerID]int{}
+			byes := map[players.PlayerID]int{}
+			for i, ps := range rs {
+				seen := map[players.PlayerID]bool{}
+				for _, p := range ps {
+					if seen[p.Side1ID] || seen[p.Side2ID] {
+						t.Errorf("Expected each player once in round %d, got %+v", i+1, ps)
+					}
+					seen[p.Side1ID], seen[p.Side2ID] = true, true
+					if p.Bye {
+						byes[p.Side1ID]++
+					} else {
+						meetings[[2]players.PlayerID{p.Side1ID, p.Side2ID}]++
+					}
+				}
+			}
+
+			for i, a := range tt.ids {
+				if byes[a] != tt.byes {
+					t.Errorf("Expected player '%s' to have %d byes, got %d", a, tt.byes, byes[a])
+				}
+				for _, b := range tt.ids[i+1:] {
+					ab, ba := meetings[[2]players.PlayerID{a, b}], meetings[[2]players.PlayerID{b, a}]
+					if !tt.double && ab+ba != 1 {
+						t.Errorf("Expected '%s' and '%s' to meet once, got %d", a, b, ab+ba)
+					} else if tt.double && (ab != 1 || ba != 1) {
+						t.Errorf("Expected '%s' and '%s' to meet once on each side, got %d and %d", a, b, ab, ba)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRoundDates(t *testing.T) {
+	l := &model.League{StartDate: "2025-09-03", EndDate: "2025-09-30", ExpectedDayOfWeek: "monday"}
+
+	dates, err := RoundDates(l, 4)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"2025-09-08", "2025-09-15", "2025-09-22", "2025-09-29"}
+	for i, d := range expected {
+		if dates[i] != d {
+			t.Errorf("Expected round %d on %s, got %s", i+1, d, dates[i])
+		}
+	}
+
+	if _, err = RoundDates(l, 5); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid when the rounds do not fit, got %v", err)
+	}
+
+	l.ExpectedDayOfWeek = "Someday"
+	if _, err = RoundDates(l, 1); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for an unknown day, got %v", err)
+	}
+}
+
+func TestControllerGenerateSchedule(t *testing.T) {
+	l := createFakeLeague("a", "b", "c")
+	l.StartDate, l.EndDate, l.ExpectedDayOfWeek = "2025-09-01", "2025-12-31", "Wednesday"
+	gw := &stubGamesGateway{}
+	ctrl := newTestController(t, l, gw)
+
+	s, err := ctrl.GenerateSchedule(context.Background(), l.ID, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, _ := ctrl.GetByID(context.Background(), l.ID)
+	if len(s.Rounds) != 3 || len(stored.RoundIDs) != 3 || stored.NumberOfGames != 6 || len(gw.created) != 6 {
+		t.Fatalf("Expected 3 rounds of 2 games each, got %d rounds and %d games", len(s.Rounds), len(gw.created))
+	}
+	if r := s.Rounds[2]; r.Number != 3 || r.ID != stored.RoundIDs[2] || r.Date != "2025-09-17" || r.Games[0].RoundID != r.ID {
+		t.Errorf("Unexpected third round: %+v", r)
+	}
+	if rs := leagueRoundsOf(t, ctrl, l.ID); len(rs) != 3 || rs[2].Date != "2025-09-17" || len(rs[2].GameIDs) != 2 {
+		t.Errorf("Expected the rounds to be created in the rounds service with their games, got %+v", rs)
+	}
+
+	if _, err = ctrl.GenerateSchedule(context.Background(), l.ID, false); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict when the league already has games, got %v", err)
+	}
+}
+
+func TestControllerGenerateScheduleRollsBack(t *testing.T) {
+	l := createFakeLeague("a", "b", "c")
+	l.StartDate, l.EndDate, l.ExpectedDayOfWeek = "2025-09-01", "2025-12-31", "Wednesday"
+	gw := &stubGamesGateway{failAt: 3}
+	ctrl := newTestController(t, l, gw)
+	if _, err := ctrl.rounds.Create(context.Background(), &rounds.Round{LeagueID: l.ID, Number: 1, PointsLimit: 300}); err != nil {
+		t.Fatalf("Unable to create the first round: %v", err)
+	}
+
+	// The first game of the second round fails, after the games of the first round were recorded on it
+	if _, err := ctrl.GenerateSchedule(context.Background(), l.ID, false); err == nil {
+		t.Fatalf("Expected error when a game cannot be created, got nil")
+	}
+	if len(gw.deleted) != 2 {
+		t.Errorf("Expected both games created before the failure to be deleted, got %v", gw.deleted)
+	}
+	rs := leagueRoundsOf(t, ctrl, l.ID)
+	if len(rs) != 1 || len(rs[0].GameIDs) != 0 || rs[0].Date != "" || rs[0].PointsLimit != 300 {
+		t.Fatalf("Expected only the first round, as it was before the schedule, got %+v", rs)
+	}
+
+	gw.failAt, gw.created, gw.deleted = 0, nil, nil
+	if s, err := ctrl.GenerateSchedule(context.Background(), l.ID, false); err != nil || len(s.Rounds) != 3 || s.Rounds[0].PointsLimit != 300 {
+		t.Errorf("Expected the schedule to be generated again once the games can be created, got %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,18 @@
+package primary
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// RegisterRoutes registers every Leagues operation of the handler with the given Huma API, so that the service and
+// anything embedding it (such as tests) expose exactly the same routes.
+func RegisterRoutes(api huma.API, handler *HumaHandler) {
+	huma.Get(api, "/leagues", handler.List)
+	huma.Get(api, "/leagues/{id}", handler.GetByID)
+	huma.Post(api, "/leagues", handler.Post)
+	huma.Put(api, "/leagues/{id}", handler.Put)
+	huma.Delete(api, "/leagues/{id}", handler.Delete)
+	huma.Get(api, "/leagues/{id}/standings", handler.Standings)
+	huma.Post(api, "/leagues/{id}/rounds/{number}/pairings", handler.Pairings)
+	huma.Post(api, "/leagues/{id}/schedule", handler.Schedule)
+}
@@ -0,0 +1,42 @@
+package primary
+
+import (
+	"context"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+)
+
+//go:generate mockgen --destination ./mocks/controller.go github.com/rpatton4/mesbg-league/leagues/internal/primary SingleController
+type SingleController interface {
+	// GetByID returns the league with the given id, or a svcerrors.ErrNotFound if no league with that id exists
+	GetByID(ctx context.Context, id leagues.LeagueID) (*model.League, error)
+
+	// Create persists a new league instance to the repository and returns the league with an assigned ID.
+	// A generic error is returned if the league to create is missing, while specific validation errors are
+	// passed along from the repository if the league is invalid in some way.
+	Create(ctx context.Context, l *model.League) (*model.League, error)
+
+	// Replace updates an existing league in the repository with the provided league.
+	// A generic error is returned if the league to replace is not present in the data store.
+	Replace(ctx context.Context, l *model.League) (*model.League, error)
+
+	// DeleteByID removes the league with the given id from the repository. Returns true if the league was found and
+	// deleted, false otherwise. This is an idempotent operation.
+	DeleteByID(ctx context.Context, id leagues.LeagueID) (bool, error)
+
+	// List returns one page of the leagues matching the filters in the query, in a stable order suitable for paging
+	// with the returned cursor. A svcerrors.ErrInvalidQuery is returned if the query cannot be used.
+	List(ctx context.Context, q model.LeagueQuery) (*model.LeagueList, error)
+
+	// Standings returns the ranked standings for the league with the given id, computed from the completed games of
+	// every round in the league.
+	Standings(ctx context.Context, id leagues.LeagueID) (*model.Standings, error)
+
+	// GeneratePairings produces Swiss pairings for the round of the league with the given number and, unless dryRun
+	// is set, creates the games for them. A svcerrors.ErrConflict is returned if the round already has games.
+	GeneratePairings(ctx context.Context, id leagues.LeagueID, number int, dryRun bool) (*model.RoundPairings, error)
+
+	// GenerateSchedule creates every round of a round-robin between the participants of the league, with all their
+	// games. A svcerrors.ErrConflict is returned if the league already has games.
+	GenerateSchedule(ctx context.Context, id leagues.LeagueID, double bool) (*model.Schedule, error)
+}
@@ -0,0 +1,200 @@
+package primary
+
+import (
+	"cmp"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"log/slog"
+	"slices"
+)
+
+// ComputeStandings builds the ranked standings table for the league from the given games, using the league's
+// scoring rules and tiebreakers. Games which are not completed, a bye or conceded are ignored. Every participant
+// of the league gets a row even if they have not played yet.
+func ComputeStandings(l *model.League, gs []gamesmodel.Game) *model.Standings {
+	rules := l.ScoringOrDefault()
+	rows := map[players.PlayerID]*model.StandingsRow{}
+	row := func(id players.PlayerID) *model.StandingsRow {
+		r, ok := rows[id]
+		if !ok {
+			r = &model.StandingsRow{PlayerID: id}
+			rows[id] = r
+		}
+		return r
+	}
+
+	for _, p := range l.Participants {
+		if p != nil && p.PlayerID != "" {
+			row(players.PlayerID(p.PlayerID))
+		}
+	}
+
+	res := newResults()
+	opponents := map[players.PlayerID][]players.PlayerID{}
+	for i := range gs {
+		g := &gs[i]
+		if g.Status == games.GameStateBye {
+			scoreBye(rules, row(g.Side1ID))
+			continue
+		} else if g.Status != games.GameStatePlayCompleted && g.Status != games.GameStateConceded {
+			continue
+		} else if g.Status == games.GameStateConceded && g.ConcedingSideID != g.Side1ID && g.ConcedingSideID != g.Side2ID {
+			slog.Warn("Conceded game does not say which side conceded, leaving it out of the standings", "gameID", g.ID)
+			continue
+		}
+
+		side1, side2 := row(g.Side1ID), row(g.Side2ID)
+		side1Before, side2Before := side1.TournamentPoints, side2.TournamentPoints
+		if g.Status == games.GameStateConceded {
+			scoreConcededGame(rules, side1, side2, g)
+		} else {
+			scorePlayedGame(rules, side1, side2, g)
+		}
+
+		res.recordHeadToHead(g.Side1ID, g.Side2ID, side1.TournamentPoints-side1Before)
+		res.recordHeadToHead(g.Side2ID, g.Side1ID, side2.TournamentPoints-side2Before)
+		opponents[g.Side1ID] = append(opponents[g.Side1ID], g.Side2ID)
+		opponents[g.Side2ID] = append(opponents[g.Side2ID], g.Side1ID)
+	}
+
+	for id, r := range rows {
+		r.VictoryPointDifferential = r.VictoryPointsScored - r.VictoryPointsConceded
+		for _, o := range opponents[id] {
+			r.StrengthOfSchedule += rows[o].TournamentPoints
+		}
+	}
+
+	standings := &model.Standings{LeagueID: l.ID, Rows: make([]model.StandingsRow, 0, len(rows))}
+	for _, r := range rows {
+		standings.Rows = append(standings.Rows, *r)
+	}
+	rankStandings(standings.Rows, tiebreakerChain(l), res)
+	return standings
+}
+
+// scorePlayedGame records a game which was played to its conclusion, the winner being the side with more
+// victory points
+func scorePlayedGame(rules model.ScoringRules, side1, side2 *model.StandingsRow, g *gamesmodel.Game) {
+	recordVictoryPoints(side1, side2, g.Side1TotalVictoryPoints, g.Side2TotalVictoryPoints)
+	recordGenerals(side1, side2, g)
+
+	switch {
+	case g.Side1TotalVictoryPoints > g.Side2TotalVictoryPoints:
+		recordWin(side1, side2, rules.WinPoints, rules.LossPoints)
+	case g.Side2TotalVictoryPoints > g.Side1TotalVictoryPoints:
+		recordWin(side2, side1, rules.WinPoints, rules.LossPoints)
+	default:
+		side1.Draws++
+		side2.Draws++
+		side1.TournamentPoints += rules.DrawPoints
+		side2.TournamentPoints += rules.DrawPoints
+	}
+}
+
+// scoreBye records a bye for the only side in the game
+func scoreBye(rules model.ScoringRules, side *model.StandingsRow) {
+	side.Played++
+	side.Byes++
+	if rules.ByeCountsAsWin {
+		side.Wins++
+	}
+	side.TournamentPoints += rules.ByePoints
+	side.VictoryPointsScored += rules.ByeVictoryPoints
+}
+
+// scoreConcededGame records a game which one side conceded, the other side being the winner regardless of the
+// victory points recorded
+func scoreConcededGame(rules model.ScoringRules, side1, side2 *model.StandingsRow, g *gamesmodel.Game) {
+	winner, loser := side1, side2
+	winnerVP, loserVP := g.Side1TotalVictoryPoints, g.Side2TotalVictoryPoints
+	if g.ConcedingSideID == g.Side1ID {
+		winner, loser = side2, side1
+		winnerVP, loserVP = g.Side2TotalVictoryPoints, g.Side1TotalVictoryPoints
+	}
+	if rules.ConcessionVictoryPoints > 0 {
+		winnerVP, loserVP = rules.ConcessionVictoryPoints, 0
+	}
+
+	recordVictoryPoints(winner, loser, winnerVP, loserVP)
+	recordGenerals(side1, side2, g)
+	recordWin(winner, loser, rules.ConcessionWinPoints, rules.ConcessionLossPoints)
+}
+
+// recordWin counts a decided game for both sides, with the given tournament points for each
+func recordWin(winner, loser *model.StandingsRow, winPoints, lossPoints int) {
+	winner.Wins++
+	loser.Losses++
+	winner.TournamentPoints += winPoints
+	loser.TournamentPoints += lossPoints
+}
+
+// recordVictoryPoints counts the game as played for both sides and totals up the victory points for and against
+func recordVictoryPoints(side1, side2 *model.StandingsRow, side1VP, side2VP int) {
+	side1.Played++
+	side2.Played++
+	side1.VictoryPointsScored += side1VP
+	side1.VictoryPointsConceded += side2VP
+	side2.VictoryPointsScored += side2VP
+	side2.VictoryPointsConceded += side1VP
+}
+
+// recordGenerals totals up the opposing generals killed by each side
+func recordGenerals(side1, side2 *model.StandingsRow, g *gamesmodel.Game) {
+	if g.Side1KilledGeneral {
+		side1.GeneralsKilled++
+	}
+	if g.Side2KilledGeneral {
+		side2.GeneralsKilled++
+	}
+}
+
+// rankStandings sorts the rows using the chain of tiebreakers, assigns the ranks and records which tiebreaker
+// separated each row from the next. Rows which are level on every tiebreaker share a rank, the player ID only keeps
+// the order stable between calls.
+func rankStandings(rows []model.StandingsRow, chain []Tiebreaker, res *Results) {
+	slices.SortStableFunc(rows, func(a, b model.StandingsRow) int {
+		return cmp.Compare(a.PlayerID, b.PlayerID)
+	})
+	breakTies(rows, chain, res)
+
+	for i := range rows {
+		if i > 0 && rows[i-1].DecidedBy == "" {
+			rows[i].Rank = rows[i-1].Rank
+		} else {
+			rows[i].Rank = i + 1
+		}
+	}
+}
+
+// breakTies orders a group of rows which are level on every tiebreaker before the chain, using the first tiebreaker
+// of the chain and then the rest of it on each group still level. Going a tiebreaker at a time means a group
+// tiebreaker such as head-to-head only ever compares the rows it is breaking the tie between, which keeps the order
+// consistent where comparing two rows at a time would not be, e.g. when a beat b, b beat c and c beat a. The last
+// row before each split records the tiebreaker which made it, once the rows before it are in their final order.
+func breakTies(group []model.StandingsRow, chain []Tiebreaker, res *Results) {
+	if len(group) < 2 || len(chain) == 0 {
+		return
+	}
+
+	t := chain[0]
+	compare := func(a, b *model.StandingsRow) int { return t.Compare(a, b, res) }
+	if gt, ok := t.(GroupTiebreaker); ok {
+		scores := gt.Scores(group, res)
+		compare = func(a, b *model.StandingsRow) int { return cmp.Compare(scores[b.PlayerID], scores[a.PlayerID]) }
+	}
+	slices.SortStableFunc(group, func(a, b model.StandingsRow) int { return compare(&a, &b) })
+
+	start := 0
+	for i := 1; i <= len(group); i++ {
+		if i < len(group) && compare(&group[i-1], &group[i]) == 0 {
+			continue
+		}
+		breakTies(group[start:i], chain[1:], res)
+		if i < len(group) {
+			group[i-1].DecidedBy = t.Name()
+		}
+		start = i
+	}
+}
@@ -0,0 +1,184 @@
+package primary
+
+import (
+	"context"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/leagues/internal/secondary"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	participants "github.com/rpatton4/mesbg-league/participants/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	roundsgateway "github.com/rpatton4/mesbg-league/rounds/pkg/gateway"
+	roundsmodel "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestComputeStandingsPlayedGames(t *testing.T) {
+	l := createFakeLeague("a", "b", "c", "d")
+	gs := []gamesmodel.Game{
+		{ID: "1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 12, Side2TotalVictoryPoints: 4, Side1KilledGeneral: true, Status: games.GameStatePlayCompleted},
+		{ID: "2", Side1ID: "c", Side2ID: "d", Side1TotalVictoryPoints: 6, Side2TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+		{ID: "3", Side1ID: "a", Side2ID: "c", Status: games.GameStateNotStarted},
+		{ID: "4", Side1ID: "b", Side2ID: "d", Status: games.GameStateCancelled},
+	}
+
+	s := ComputeStandings(l, gs)
+	if len(s.Rows) != 4 {
+		t.Fatalf("Expected a row for every participant, got %d", len(s.Rows))
+	}
+
+	a := findRow(t, s, "a")
+	if a.Rank != 1 || a.Wins != 1 || a.TournamentPoints != 3 || a.VictoryPointDifferential != 8 || a.GeneralsKilled != 1 || a.Played != 1 {
+		t.Errorf("Unexpected row for the winner: %+v", a)
+	}
+	b := findRow(t, s, "b")
+	if b.Rank != 4 || b.Losses != 1 || b.VictoryPointsConceded != 12 {
+		t.Errorf("Unexpected row for the loser: %+v", b)
+	}
+	c, d := findRow(t, s, "c"), findRow(t, s, "d")
+	if c.Draws != 1 || c.TournamentPoints != 1 || c.Rank != 2 || d.Rank != 2 {
+		t.Errorf("Expected the drawn players to share second place, got %+v and %+v", c, d)
+	}
+}
+
+func TestComputeStandingsByesAndConcessions(t *testing.T) {
+	l := createFakeLeague("a", "b", "c")
+	gs := []gamesmodel.Game{
+		{ID: "1", Side1ID: "a", Status: games.GameStateBye},
+		{ID: "2", Side1ID: "b", Side2ID: "c", Side1TotalVictoryPoints: 9, Side2TotalVictoryPoints: 2, Status: games.GameStateConceded, ConcedingSideID: "b"},
+	}
+
+	// The defaults treat a bye as a win, and a concession as a win for the other side on the recorded points
+	s := ComputeStandings(l, gs)
+	if a := findRow(t, s, "a"); a.Byes != 1 || a.Wins != 1 || a.TournamentPoints != 3 || a.VictoryPointsScored != 0 {
+		t.Errorf("Unexpected row for the bye with the default rules: %+v", a)
+	}
+	if c := findRow(t, s, "c"); c.Wins != 1 || c.TournamentPoints != 3 || c.VictoryPointsScored != 2 {
+		t.Errorf("Unexpected row for the concession winner with the default rules: %+v", c)
+	}
+
+	// A league can choose its own rules
+	l.Scoring = &model.ScoringRules{WinPoints: 3, DrawPoints: 1, ByePoints: 1, ByeVictoryPoints: 5, ConcessionWinPoints: 2, ConcessionVictoryPoints: 10}
+	s = ComputeStandings(l, gs)
+	if a := findRow(t, s, "a"); a.Byes != 1 || a.Wins != 0 || a.TournamentPoints != 1 || a.VictoryPointsScored != 5 {
+		t.Errorf("Unexpected row for the bye with custom rules: %+v", a)
+	}
+	if c := findRow(t, s, "c"); c.TournamentPoints != 2 || c.VictoryPointsScored != 10 || c.VictoryPointsConceded != 0 {
+		t.Errorf("Unexpected row for the concession winner with custom rules: %+v", c)
+	}
+	if b := findRow(t, s, "b"); b.Losses != 1 || b.VictoryPointsScored != 0 || b.VictoryPointsConceded != 10 {
+		t.Errorf("Unexpected row for the conceding side with custom rules: %+v", b)
+	}
+}
+
+func TestControllerStandingsReadsEveryPage(t *testing.T) {
+	l := createFakeLeague("a", "b")
+	l.RoundIDs = []rounds.RoundID{"r1", "r2"}
+	src := &stubGamesGateway{pages: map[string][]gamesmodel.GameList{
+		"r1": {
+			{Games: []gamesmodel.Game{{ID: "1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 5, Status: games.GameStatePlayCompleted}}, NextCursor: "next"},
+			{Games: []gamesmodel.Game{{ID: "2", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 5, Status: games.GameStatePlayCompleted}}},
+		},
+		"r2": {
+			{Games: []gamesmodel.Game{{ID: "3", Side1ID: "b", Side2ID: "a", Side1TotalVictoryPoints: 5, Status: games.GameStatePlayCompleted}}},
+		},
+	}}
+	ctrl := newTestController(t, l, src)
+
+	s, err := ctrl.Standings(context.Background(), l.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a := findRow(t, s, "a"); a.Played != 3 || a.Wins != 2 || a.Rank != 1 {
+		t.Errorf("Expected all three games to be counted, got %+v", a)
+	}
+
+	if _, err = ctrl.Standings(context.Background(), "unknown"); err == nil {
+		t.Errorf("Expected error for an unknown league, got nil")
+	}
+}
+
+// newTestController stores the league in a new in-memory repository, assigning its ID, and returns a controller
+// using that repository, the given games gateway and rounds kept in process
+func newTestController(t *testing.T, l *model.League, gw gamesGateway) *TxnController {
+	t.Helper()
+	repo := secondary.NewMemoryRepository()
+	if _, err := repo.Create(context.Background(), l); err != nil {
+		t.Fatalf("Unable to store the test league: %v", err)
+	}
+	return NewTxnController(repo, gw, roundsgateway.NewDefaultInProcessGateway())
+}
+
+// leagueRoundsOf returns the rounds of the league held by the rounds service the controller uses
+func leagueRoundsOf(t *testing.T, ctrl *TxnController, id leagues.LeagueID) []roundsmodel.Round {
+	t.Helper()
+	rs, err := ctrl.rounds.List(context.Background(), roundsmodel.RoundQuery{LeagueID: id})
+	if err != nil {
+		t.Fatalf("Unable to list the rounds of league '%s': %v", id, err)
+	}
+	return rs.Rounds
+}
+
+// stubGamesGateway returns the pages of games set up for each round, in order, following the cursor, and keeps
+// track of the games created and deleted through it. A round without pages set up lists the games created in it.
+type stubGamesGateway struct {
+	pages   map[string][]gamesmodel.GameList
+	created []gamesmodel.Game
+	deleted []games.GameID
+	failAt  int
+}
+
+func (s *stubGamesGateway) List(_ context.Context, q gamesmodel.GameQuery) (*gamesmodel.GameList, error) {
+	pages := s.pages[string(q.RoundID)]
+	if len(pages) == 0 {
+		l := &gamesmodel.GameList{}
+		for _, g := range s.created {
+			if g.RoundID == q.RoundID && !slices.Contains(s.deleted, g.ID) {
+				l.Games = append(l.Games, g)
+			}
+		}
+		return l, nil
+	}
+	if q.Cursor == "" {
+		return &pages[0], nil
+	}
+	return &pages[1], nil
+}
+
+func createFakeLeague(playerIDs ...string) *model.League {
+	l := &model.League{Name: "Test League"}
+	for _, id := range playerIDs {
+		l.Participants = append(l.Participants, &participants.Participant{PlayerID: id, LeagueID: "1"})
+	}
+	return l
+}
+
+func findRow(t *testing.T, s *model.Standings, id players.PlayerID) model.StandingsRow {
+	for _, r := range s.Rows {
+		if r.PlayerID == id {
+			return r
+		}
+	}
+	t.Fatalf("Expected a standings row for player '%s'", id)
+	return model.StandingsRow{}
+}
+
+func (s *stubGamesGateway) Create(_ context.Context, g *gamesmodel.Game) (*gamesmodel.Game, error) {
+	if s.failAt > 0 && len(s.created)+1 == s.failAt {
+		return nil, svcerrors.ErrModelInvalid
+	}
+	ng := *g
+	ng.ID = games.GameID(strconv.Itoa(len(s.created) + 100))
+	s.created = append(s.created, ng)
+	return &ng, nil
+}
+
+func (s *stubGamesGateway) DeleteByID(_ context.Context, id games.GameID, _ int64) (bool, error) {
+	s.deleted = append(s.deleted, id)
+	return true, nil
+}
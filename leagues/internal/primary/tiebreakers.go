@@ -0,0 +1,150 @@
+package primary
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Tiebreaker orders two rows of the standings. Leagues choose the tiebreakers they use, and the order they apply
+// them in, by name.
+type Tiebreaker interface {
+	// Name is the name a league uses to select the tiebreaker
+	Name() model.TiebreakerName
+
+	// Compare returns a negative number when a should rank above b, a positive number when b should rank above a,
+	// and zero when the tiebreaker cannot separate them
+	Compare(a, b *model.StandingsRow, res *Results) int
+}
+
+// GroupTiebreaker is a Tiebreaker which orders the rows level before it by what happened between them, such as
+// head-to-head, so the order of two rows depends on which other rows are in the tie. The standings use Scores rather
+// than Compare for it, as comparing two rows at a time need not give a consistent order.
+type GroupTiebreaker interface {
+	Tiebreaker
+
+	// Scores returns a score for each row of the group, by player ID, the higher score ranking higher
+	Scores(group []model.StandingsRow, res *Results) map[players.PlayerID]int
+}
+
+// Results holds what the tiebreakers may need to know about the games in the league beyond the totals in each row
+type Results struct {
+	headToHead map[[2]players.PlayerID]int
+}
+
+// newResults creates an empty set of results
+func newResults() *Results {
+	return &Results{headToHead: map[[2]players.PlayerID]int{}}
+}
+
+// recordHeadToHead adds the tournament points player a earned in a game against player b
+func (r *Results) recordHeadToHead(a, b players.PlayerID, points int) {
+	r.headToHead[[2]players.PlayerID{a, b}] += points
+}
+
+// HeadToHeadPoints returns the total tournament points player a has earned in games against player b
+func (r *Results) HeadToHeadPoints(a, b players.PlayerID) int {
+	return r.headToHead[[2]players.PlayerID{a, b}]
+}
+
+// statTiebreaker ranks the row with the higher value of a single statistic higher
+type statTiebreaker struct {
+	name model.TiebreakerName
+	stat func(*model.StandingsRow) int
+}
+
+func (t statTiebreaker) Name() model.TiebreakerName {
+	return t.name
+}
+
+func (t statTiebreaker) Compare(a, b *model.StandingsRow, _ *Results) int {
+	return cmp.Compare(t.stat(b), t.stat(a))
+}
+
+// headToHeadTiebreaker ranks the player who earned more tournament points in the games between the tied players
+// higher
+type headToHeadTiebreaker struct{}
+
+func (headToHeadTiebreaker) Name() model.TiebreakerName {
+	return model.TiebreakerHeadToHead
+}
+
+func (headToHeadTiebreaker) Compare(a, b *model.StandingsRow, res *Results) int {
+	return cmp.Compare(res.HeadToHeadPoints(b.PlayerID, a.PlayerID), res.HeadToHeadPoints(a.PlayerID, b.PlayerID))
+}
+
+// Scores gives each row the tournament points it earned in games against the other rows of the group
+func (headToHeadTiebreaker) Scores(group []model.StandingsRow, res *Results) map[players.PlayerID]int {
+	scores := make(map[players.PlayerID]int, len(group))
+	for _, a := range group {
+		for _, b := range group {
+			scores[a.PlayerID] += res.HeadToHeadPoints(a.PlayerID, b.PlayerID)
+		}
+	}
+	return scores
+}
+
+var (
+	tiebreakersMu sync.RWMutex
+	tiebreakers   = map[model.TiebreakerName]Tiebreaker{}
+)
+
+func init() {
+	RegisterTiebreaker(statTiebreaker{model.TiebreakerTournamentPoints, func(r *model.StandingsRow) int { return r.TournamentPoints }})
+	RegisterTiebreaker(statTiebreaker{model.TiebreakerVictoryPointDifferential, func(r *model.StandingsRow) int { return r.VictoryPointDifferential }})
+	RegisterTiebreaker(statTiebreaker{model.TiebreakerGeneralsKilled, func(r *model.StandingsRow) int { return r.GeneralsKilled }})
+	RegisterTiebreaker(statTiebreaker{model.TiebreakerStrengthOfSchedule, func(r *model.StandingsRow) int { return r.StrengthOfSchedule }})
+	RegisterTiebreaker(headToHeadTiebreaker{})
+}
+
+// RegisterTiebreaker makes a tiebreaker available to leagues under its name, replacing any registered before it
+// with the same name
+func RegisterTiebreaker(t Tiebreaker) {
+	tiebreakersMu.Lock()
+	defer tiebreakersMu.Unlock()
+	tiebreakers[t.Name()] = t
+}
+
+// TiebreakerByName returns the registered tiebreaker with the given name, and whether there is one
+func TiebreakerByName(name model.TiebreakerName) (Tiebreaker, bool) {
+	tiebreakersMu.RLock()
+	defer tiebreakersMu.RUnlock()
+	t, ok := tiebreakers[name]
+	return t, ok
+}
+
+// tiebreakerChain resolves the league's tiebreaker names, in order. Names which are not registered are left out
+// rather than failing the whole table.
+func tiebreakerChain(l *model.League) []Tiebreaker {
+	names := l.TiebreakersOrDefault()
+	chain := make([]Tiebreaker, 0, len(names))
+	for _, n := range names {
+		t, ok := TiebreakerByName(n)
+		if !ok {
+			slog.Warn("League uses an unknown tiebreaker, skipping it", "leagueID", l.ID, "tiebreaker", n)
+			continue
+		}
+		chain = append(chain, t)
+	}
+	return chain
+}
+
+// validateTiebreakers checks that every tiebreaker the league names is registered, returning an error wrapping
+// svcerrors.ErrModelInvalid naming the ones which are not
+func validateTiebreakers(l *model.League) error {
+	unknown := []string{}
+	for _, n := range l.Tiebreakers {
+		if _, ok := TiebreakerByName(n); !ok {
+			unknown = append(unknown, string(n))
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("league %w: Tiebreakers includes unknown names '%s'", svcerrors.ErrModelInvalid, strings.Join(unknown, "', '"))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package primary
+
+import (
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"testing"
+)
+
+// tiebreakerGames leaves a, b and c level on tournament points and victory point differential, with a having beaten
+// b, b having beaten c, and c having beaten a but killing two generals along the way
+func tiebreakerGames() []gamesmodel.Game {
+	return []gamesmodel.Game{
+		{ID: "1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 6, Side2TotalVictoryPoints: 4, Status: games.GameStatePlayCompleted},
+		{ID: "2", Side1ID: "b", Side2ID: "c", Side1TotalVictoryPoints: 6, Side2TotalVictoryPoints: 4, Side2KilledGeneral: true, Status: games.GameStatePlayCompleted},
+		{ID: "3", Side1ID: "c", Side2ID: "a", Side1TotalVictoryPoints: 6, Side2TotalVictoryPoints: 4, Side1KilledGeneral: true, Status: games.GameStatePlayCompleted},
+		{ID: "4", Side1ID: "d", Side2ID: "e", Side1TotalVictoryPoints: 6, Side2TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+	}
+}
+
+func TestTiebreakerChain(t *testing.T) {
+	l := createFakeLeague("a", "b", "c", "d", "e")
+
+	// The default chain cannot separate the three players on 3 points
+	s := ComputeStandings(l, tiebreakerGames())
+	assertOrder(t, s, "a", "b", "c", "d", "e")
+	assertRanks(t, s, 1, 1, 1, 4, 4)
+	assertDecidedBy(t, s, "", "", model.TiebreakerTournamentPoints, "", "")
+
+	// Generals killed puts c on top, head-to-head then separates a and b
+	l.Tiebreakers = []model.TiebreakerName{model.TiebreakerTournamentPoints, model.TiebreakerGeneralsKilled, model.TiebreakerHeadToHead}
+	s = ComputeStandings(l, tiebreakerGames())
+	assertOrder(t, s, "c", "a", "b", "d", "e")
+	assertRanks(t, s, 1, 2, 3, 4, 4)
+	assertDecidedBy(t, s, model.TiebreakerGeneralsKilled, model.TiebreakerHeadToHead, model.TiebreakerTournamentPoints, "", "")
+}
+
+func TestTiebreakerHeadToHeadInACycle(t *testing.T) {
+	l := createFakeLeague("a", "b", "c", "d", "e")
+	l.Tiebreakers = []model.TiebreakerName{model.TiebreakerTournamentPoints, model.TiebreakerHeadToHead}
+
+	// a beat b, b beat c and c beat a, so none of the three did better against the others
+	s := ComputeStandings(l, tiebreakerGames())
+	assertOrder(t, s, "a", "b", "c", "d", "e")
+	assertRanks(t, s, 1, 1, 1, 4, 4)
+	assertDecidedBy(t, s, "", "", model.TiebreakerTournamentPoints, "", "")
+
+	// Once c has also beaten b, c did best in the games between the three while a and b did as well as each other
+	gs := append(tiebreakerGames(), gamesmodel.Game{ID: "5", Side1ID: "c", Side2ID: "b", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+		gamesmodel.Game{ID: "6", Side1ID: "a", Side2ID: "d", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+		gamesmodel.Game{ID: "7", Side1ID: "b", Side2ID: "e", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted})
+	s = ComputeStandings(l, gs)
+	assertOrder(t, s, "c", "a", "b", "d", "e")
+	assertRanks(t, s, 1, 2, 2, 4, 4)
+	assertDecidedBy(t, s, model.TiebreakerHeadToHead, "", model.TiebreakerTournamentPoints, "", "")
+}
+
+func TestTiebreakerStrengthOfSchedule(t *testing.T) {
+	l := createFakeLeague("a", "b", "c", "d")
+	l.Tiebreakers = []model.TiebreakerName{model.TiebreakerTournamentPoints, model.TiebreakerStrengthOfSchedule}
+	gs := []gamesmodel.Game{
+		{ID: "1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+		{ID: "2", Side1ID: "c", Side2ID: "d", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+		{ID: "3", Side1ID: "b", Side2ID: "d", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+	}
+
+	// a, b and c all have one win, but c's only opponent has not won a game
+	s := ComputeStandings(l, gs)
+	assertOrder(t, s, "a", "b", "c", "d")
+	assertRanks(t, s, 1, 1, 3, 4)
+	assertDecidedBy(t, s, "", model.TiebreakerStrengthOfSchedule, model.TiebreakerTournamentPoints, "")
+	if a := findRow(t, s, "a"); a.StrengthOfSchedule != 3 {
+		t.Errorf("Expected a strength of schedule of 3, got %d", a.StrengthOfSchedule)
+	}
+}
+
+func TestTiebreakerUnknownNameIsSkipped(t *testing.T) {
+	l := createFakeLeague("a", "b")
+	l.Tiebreakers = []model.TiebreakerName{"coinToss", model.TiebreakerTournamentPoints}
+	s := ComputeStandings(l, []gamesmodel.Game{
+		{ID: "1", Side1ID: "b", Side2ID: "a", Side1TotalVictoryPoints: 6, Status: games.GameStatePlayCompleted},
+	})
+	assertOrder(t, s, "b", "a")
+	assertDecidedBy(t, s, model.TiebreakerTournamentPoints, "")
+}
+
+func assertOrder(t *testing.T, s *model.Standings, ids ...string) {
+	t.Helper()
+	for i, id := range ids {
+		if string(s.Rows[i].PlayerID) != id {
+			t.Errorf("Expected player '%s' in row %d, got '%s'", id, i, s.Rows[i].PlayerID)
+		}
+	}
+}
+
+func assertRanks(t *testing.T, s *model.Standings, ranks ...int) {
+	t.Helper()
+	for i, r := range ranks {
+		if s.Rows[i].Rank != r {
+			t.Errorf("Expected rank %d in row %d, got %d", r, i, s.Rows[i].Rank)
+		}
+	}
+}
+
+func assertDecidedBy(t *testing.T, s *model.Standings, names ...model.TiebreakerName) {
+	t.Helper()
+	for i, n := range names {
+		if s.Rows[i].DecidedBy != n {
+			t.Errorf("Expected row %d to be decided by '%s', got '%s'", i, n, s.Rows[i].DecidedBy)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package primary
+
+import (
+	"context"
+	"fmt"
+	gamesheader "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/leagues/internal/secondary"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	roundsheader "github.com/rpatton4/mesbg-league/rounds/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// gamesGateway is the part of the games gateway the league controller needs, to read the games played in a league
+// and create the games for new rounds
+type gamesGateway interface {
+	List(ctx context.Context, q gamesmodel.GameQuery) (*gamesmodel.GameList, error)
+	Create(ctx context.Context, g *gamesmodel.Game) (*gamesmodel.Game, error)
+	DeleteByID(ctx context.Context, id gamesheader.GameID, version int64) (bool, error)
+}
+
+// roundsGateway is the part of the rounds gateway the league controller needs, to find and create the rounds of a
+// league and record the games created for them
+type roundsGateway interface {
+	List(ctx context.Context, q rounds.RoundQuery) (*rounds.RoundList, error)
+	Create(ctx context.Context, r *rounds.Round) (*rounds.Round, error)
+	Replace(ctx context.Context, r *rounds.Round) (*rounds.Round, error)
+	DeleteByID(ctx context.Context, id roundsheader.RoundID) (bool, error)
+}
+
+// TxnController implements the single controller for league operations.
+type TxnController struct {
+	repo   secondary.Repository
+	games  gamesGateway
+	rounds roundsGateway
+
+	// roundsMu stops two requests generating the games for rounds at once
+	roundsMu sync.Mutex
+}
+
+// NewTxnController creates a new instance of the leagues controller, using the games gateway to read and create
+// the games played in the leagues, and the rounds gateway to create the rounds those games are played in
+func NewTxnController(r secondary.Repository, g gamesGateway, rs roundsGateway) *TxnController {
+	return &TxnController{repo: r, games: g, rounds: rs}
+}
+
+// GetByID returns the league with the given id, or a svcerrors.ErrNotFound if no league with that id exists
+func (c *TxnController) GetByID(ctx context.Context, id leagues.LeagueID) (*model.League, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+	return c.repo.GetByID(ctx, id)
+}
+
+// Create persists a new league instance to the repository and returns the league with an assigned ID.
+// A generic error is returned if the league to create is missing, and svcerrors.ErrModelInvalid if it uses a
+// tiebreaker which does not exist, while other validation errors are passed along from the repository.
+func (c *TxnController) Create(ctx context.Context, l *model.League) (*model.League, error) {
+	if l == nil {
+		return nil, fmt.Errorf("the league to be created cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	} else if err := validateTiebreakers(l); err != nil {
+		return nil, err
+	}
+	return c.repo.Create(ctx, l)
+}
+
+// Replace updates an existing league in the repository with the provided league.
+// A generic error is returned if the league to replace is not present in the data store, and
+// svcerrors.ErrModelInvalid if it uses a tiebreaker which does not exist.
+func (c *TxnController) Replace(ctx context.Context, l *model.League) (*model.League, error) {
+	if l == nil {
+		return nil, fmt.Errorf("the league to be replaced cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	} else if err := validateTiebreakers(l); err != nil {
+		return nil, err
+	}
+	return c.repo.Replace(ctx, l)
+}
+
+// DeleteByID removes the league with the given id from the repository. Returns true if the league was found and
+// deleted, false otherwise. This is an idempotent operation.
+func (c *TxnController) DeleteByID(ctx context.Context, id leagues.LeagueID) (bool, error) {
+	if id == "" {
+		return false, svcerrors.ErrInvalidID
+	}
+	return c.repo.DeleteByID(ctx, id)
+}
+
+// List returns one page of the leagues matching the filters in the query, in a stable order suitable for paging
+// with the returned cursor. A svcerrors.ErrInvalidQuery is returned if the query cannot be used.
+func (c *TxnController) List(ctx context.Context, q model.LeagueQuery) (*model.LeagueList, error) {
+	if q.Limit < 0 {
+		return nil, fmt.Errorf("the page limit cannot be negative, got %d. Source: %w", q.Limit, svcerrors.ErrInvalidQuery)
+	}
+	return c.repo.List(ctx, q)
+}
+
+// Standings returns the ranked standings for the league with the given id, computed from the completed games of
+// every round in the league. A svcerrors.NotFound is returned if no league with that id exists.
+func (c *TxnController) Standings(ctx context.Context, id leagues.LeagueID) (*model.Standings, error) {
+	l, err := c.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	gs, err := c.leagueGames(ctx, l, gamesheader.CountedGameStates())
+	if err != nil {
+		return nil, err
+	}
+	return ComputeStandings(l, gs), nil
+}
+
+// GeneratePairings produces Swiss pairings for the round of the league with the given number from the current
+// standings, and creates a game for each pairing in that round. The round is created in the rounds service if the
+// league does not have it yet. With dryRun set the pairings are returned without creating anything. A
+// svcerrors.ErrConflict is returned if the round already has games.
+func (c *TxnController) GeneratePairings(ctx context.Context, id leagues.LeagueID, number int, dryRun bool) (*model.RoundPairings, error) {
+	if number < 1 {
+		return nil, fmt.Errorf("the round number must be 1 or more, got %d. Source: %w", number, svcerrors.ErrModelInvalid)
+	}
+
+	c.roundsMu.Lock()
+	defer c.roundsMu.Unlock()
+
+	l, err := c.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(l.Participants) < 2 {
+		return nil, fmt.Errorf("league '%s' needs at least 2 participants to pair a round. Source: %w", l.ID, svcerrors.ErrModelInvalid)
+	}
+
+	rs, err := c.leagueRounds(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	r := roundNumbered(rs, number)
+	if r != nil && len(r.GameIDs) > 0 {
+		return nil, fmt.Errorf("round %d of league '%s' already has games. Source: %w", number, l.ID, svcerrors.ErrConflict)
+	}
+
+	gs, err := c.leagueGames(ctx, l, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range gs {
+		if r != nil && g.RoundID == r.ID && g.Status != gamesheader.GameStateCancelled {
+			return nil, fmt.Errorf("round %d of league '%s' already has games. Source: %w", number, l.ID, svcerrors.ErrConflict)
+		}
+	}
+
+	rp := &model.RoundPairings{
+		LeagueID:    l.ID,
+		RoundNumber: number,
+		DryRun:      dryRun,
+		Pairings:    SwissPairings(ComputeStandings(l, gs), gs),
+	}
+	if r != nil {
+		rp.RoundID = r.ID
+	}
+	if dryRun {
+		return rp, nil
+	}
+
+	var created []*rounds.Round
+	if r == nil {
+		if r, err = c.rounds.Create(ctx, &rounds.Round{LeagueID: l.ID, Number: number}); err != nil {
+			return nil, fmt.Errorf("unable to create round %d of league '%s': %w", number, l.ID, err)
+		}
+		created = append(created, r)
+		rp.RoundID = r.ID
+	}
+
+	if rp.Games, err = c.createRoundGames(ctx, r.ID, rp.Pairings); err == nil {
+		r.Games = rp.Games
+		err = c.replaceRound(ctx, r)
+	}
+	if err != nil {
+		c.deleteGames(ctx, rp.Games)
+		c.deleteRounds(ctx, created)
+		return nil, err
+	}
+
+	if !slices.Contains(l.RoundIDs, r.ID) {
+		l.RoundIDs = append(l.RoundIDs, r.ID)
+	}
+	if _, err = c.repo.Replace(ctx, l); err != nil {
+		return nil, err
+	}
+	return rp, nil
+}
+
+// createRoundGames creates a game in the round for every pairing. If any of them cannot be created the ones
+// which were are returned along with the error, for the caller to remove.
+func (c *TxnController) createRoundGames(ctx context.Context, roundID roundsheader.RoundID, ps []model.Pairing) ([]gamesmodel.Game, error) {
+	created := make([]gamesmodel.Game, 0, len(ps))
+	for _, p := range ps {
+		g := &gamesmodel.Game{Side1ID: p.Side1ID, Side2ID: p.Side2ID, RoundID: roundID, Status: gamesheader.GameStateNotStarted}
+		if p.Bye {
+			g.Status = gamesheader.GameStateBye
+		}
+
+		ng, err := c.games.Create(ctx, g)
+		if err != nil {
+			return created, fmt.Errorf("unable to create the game for table %d of round '%s': %w", p.Table, roundID, err)
+		}
+		created = append(created, *ng)
+	}
+	return created, nil
+}
+
+// replaceRound records the games created for a round in the rounds service
+func (c *TxnController) replaceRound(ctx context.Context, r *rounds.Round) error {
+	if _, err := c.rounds.Replace(ctx, r); err != nil {
+		return fmt.Errorf("unable to record the games of round '%s': %w", r.ID, err)
+	}
+	return nil
+}
+
+// deleteGames removes games created for rounds which could not be completed, so they can be generated again from
+// scratch. Failures are only logged, as the caller is already returning the error which caused the clean up.
+func (c *TxnController) deleteGames(ctx context.Context, gs []gamesmodel.Game) {
+	for _, g := range gs {
+		if _, err := c.games.DeleteByID(ctx, g.ID, g.Version); err != nil {
+			slog.Error("Unable to remove a game after failing to create the rest of the games", "gameID", g.ID, "error", err)
+		}
+	}
+}
+
+// deleteRounds removes rounds created for a league whose games could not be, so they can be generated again from
+// scratch. Failures are only logged, as the caller is already returning the error which caused the clean up.
+func (c *TxnController) deleteRounds(ctx context.Context, rs []*rounds.Round) {
+	for _, r := range rs {
+		if _, err := c.rounds.DeleteByID(ctx, r.ID); err != nil {
+			slog.Error("Unable to remove a round after failing to create its games", "roundID", r.ID, "error", err)
+		}
+	}
+}
+
+// restoreRounds puts rounds the league already had back as they were before games were created for them, so a
+// schedule which could not be completed leaves no IDs of deleted games behind. Failures are only logged, as the
+// caller is already returning the error which caused the clean up.
+func (c *TxnController) restoreRounds(ctx context.Context, rs []rounds.Round) {
+	for i := range rs {
+		if _, err := c.rounds.Replace(ctx, &rs[i]); err != nil {
+			slog.Error("Unable to restore a round after failing to create the games of its league", "roundID", rs[i].ID, "error", err)
+		}
+	}
+}
+
+// GenerateSchedule creates every round of a round-robin between the participants of the league, with all their
+// games, dating the rounds a week apart on the league's expected day of the week. Rounds the league already has are
+// kept along with their scenario and points limit, the rest are created in the rounds service. With double set
+// each pair of players meets twice, with the sides swapped. A svcerrors.ErrConflict is returned if the league
+// already has games.
+func (c *TxnController) GenerateSchedule(ctx context.Context, id leagues.LeagueID, double bool) (*model.Schedule, error) {
+	c.roundsMu.Lock()
+	defer c.roundsMu.Unlock()
+
+	l, err := c.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]players.PlayerID, 0, len(l.Participants))
+	for _, p := range l.Participants {
+		if p != nil && p.PlayerID != "" {
+			ids = append(ids, players.PlayerID(p.PlayerID))
+		}
+	}
+	if len(ids) < 2 {
+		return nil, fmt.Errorf("league '%s' needs at least 2 participants to schedule a round-robin. Source: %w", l.ID, svcerrors.ErrModelInvalid)
+	}
+
+	rs, err := c.leagueRounds(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	gs, err := c.leagueGames(ctx, l, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range gs {
+		if g.Status != gamesheader.GameStateCancelled {
+			return nil, fmt.Errorf("league '%s' already has games. Source: %w", l.ID, svcerrors.ErrConflict)
+		}
+	}
+
+	pairings := RoundRobinPairings(ids, double)
+	dates, err := RoundDates(l, len(pairings))
+	if err != nil {
+		return nil, err
+	}
+
+	sched := &model.Schedule{LeagueID: l.ID, Double: double, Rounds: make([]*rounds.Round, 0, len(pairings))}
+	var (
+		created  []*rounds.Round
+		existing []rounds.Round
+		games    []gamesmodel.Game
+	)
+	for i, ps := range pairings {
+		r := roundNumbered(rs, i+1)
+		if r != nil {
+			existing = append(existing, *r)
+		} else {
+			if r, err = c.rounds.Create(ctx, &rounds.Round{LeagueID: l.ID, Number: i + 1}); err != nil {
+				err = fmt.Errorf("unable to create round %d of league '%s': %w", i+1, l.ID, err)
+				break
+			}
+			created = append(created, r)
+		}
+
+		r.Date = dates[i]
+		r.Games, err = c.createRoundGames(ctx, r.ID, ps)
+		games = append(games, r.Games...)
+		if err != nil {
+			break
+		} else if err = c.replaceRound(ctx, r); err != nil {
+			break
+		}
+		sched.Rounds = append(sched.Rounds, r)
+	}
+	if err != nil {
+		c.deleteGames(ctx, games)
+		c.deleteRounds(ctx, created)
+		c.restoreRounds(ctx, existing)
+		return nil, err
+	}
+
+	for _, r := range sched.Rounds {
+		if !slices.Contains(l.RoundIDs, r.ID) {
+			l.RoundIDs = append(l.RoundIDs, r.ID)
+		}
+	}
+	l.NumberOfGames = len(games)
+	if _, err = c.repo.Replace(ctx, l); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// roundNumbered returns the round with the given number, or nil if there is no such round
+func roundNumbered(rs []rounds.Round, number int) *rounds.Round {
+	for i := range rs {
+		if rs[i].Number == number {
+			return &rs[i]
+		}
+	}
+	return nil
+}
+
+// leagueRounds reads the rounds of the league from the rounds service, following the cursor through as many pages
+// as the league needs. A round the league does not refer to yet, such as one created through the rounds service
+// itself, is added to the league's RoundIDs so its games are counted along with the rest.
+func (c *TxnController) leagueRounds(ctx context.Context, l *model.League) ([]rounds.Round, error) {
+	rs := []rounds.Round{}
+	q := rounds.RoundQuery{LeagueID: l.ID, Limit: rounds.MaxListLimit}
+	for {
+		page, err := c.rounds.List(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the rounds of league '%s': %w", l.ID, err)
+		}
+		rs = append(rs, page.Rounds...)
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	for _, r := range rs {
+		if !slices.Contains(l.RoundIDs, r.ID) {
+			l.RoundIDs = append(l.RoundIDs, r.ID)
+		}
+	}
+	return rs, nil
+}
+
+// leagueGames reads the games in the given states, or every game when no states are given, from all the rounds of
+// the league, following the cursor through as many pages as each round needs
+func (c *TxnController) leagueGames(ctx context.Context, l *model.League, states []gamesheader.GameState) ([]gamesmodel.Game, error) {
+	gs := []gamesmodel.Game{}
+	for _, id := range l.RoundIDs {
+		if id == "" {
+			continue
+		}
+
+		q := gamesmodel.GameQuery{RoundID: id, Statuses: states, Limit: gamesmodel.MaxListLimit}
+		for {
+			page, err := c.games.List(ctx, q)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the games for round '%s' of league '%s': %w", id, l.ID, err)
+			}
+			gs = append(gs, page.Games...)
+			if page.NextCursor == "" {
+				break
+			}
+			q.Cursor = page.NextCursor
+		}
+	}
+	return gs, nil
+}
@@ -0,0 +1,65 @@
+package primary
+
+import (
+	"context"
+	"errors"
+	"github.com/rpatton4/mesbg-league/leagues/internal/secondary"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	roundsgateway "github.com/rpatton4/mesbg-league/rounds/pkg/gateway"
+	"testing"
+)
+
+func TestTxnControllerCRUD(t *testing.T) {
+	ctrl := NewTxnController(secondary.NewMemoryRepository(), &stubGamesGateway{}, roundsgateway.NewDefaultInProcessGateway())
+	ctx := context.Background()
+
+	l, err := ctrl.Create(ctx, &model.League{Name: "Test League", StartDate: "2025-09-01", EndDate: "2025-12-15", ExpectedDayOfWeek: "Monday"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	l.Name = "Renamed"
+	if _, err = ctrl.Replace(ctx, l); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got, err := ctrl.GetByID(ctx, l.ID); err != nil || got.Name != "Renamed" {
+		t.Errorf("Expected the renamed league, got %+v, %v", got, err)
+	}
+
+	if ok, err := ctrl.DeleteByID(ctx, l.ID); !ok || err != nil {
+		t.Errorf("Expected the league to be deleted, got %v, %v", ok, err)
+	}
+	if _, err = ctrl.GetByID(ctx, l.ID); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if _, err = ctrl.GetByID(ctx, ""); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID for an empty ID, got %v", err)
+	}
+}
+
+func TestTxnControllerValidation(t *testing.T) {
+	ctrl := NewTxnController(secondary.NewMemoryRepository(), &stubGamesGateway{}, roundsgateway.NewDefaultInProcessGateway())
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		league *model.League
+		err    error
+	}{
+		{"Missing", nil, svcerrors.ErrModelMissing},
+		{"NoName", &model.League{}, svcerrors.ErrModelInvalid},
+		{"BadDate", &model.League{Name: "x", StartDate: "01/09/2025"}, svcerrors.ErrModelInvalid},
+		{"EndBeforeStart", &model.League{Name: "x", StartDate: "2025-09-01", EndDate: "2025-08-01"}, svcerrors.ErrModelInvalid},
+		{"BadDay", &model.League{Name: "x", ExpectedDayOfWeek: "Funday"}, svcerrors.ErrModelInvalid},
+		{"UnknownTiebreaker", &model.League{Name: "x", Tiebreakers: []model.TiebreakerName{"coinToss"}}, svcerrors.ErrModelInvalid},
+		{"UnknownFormat", &model.League{Name: "x", Format: "sealed"}, svcerrors.ErrModelInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ctrl.Create(ctx, tt.league); !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package secondary
+
+import (
+	"context"
+	"fmt"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+var leagueCounter = 1
+
+// MemoryRepository defines an in-memory repository (adapter) for the Leagues service
+type MemoryRepository struct {
+	sync.RWMutex
+	data map[leagues.LeagueID]*model.League
+}
+
+// NewMemoryRepository creates a new instance of the in-memory league repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{data: map[leagues.LeagueID]*model.League{}}
+}
+
+// GetByID retrieves a league by ID from the in-memory repository, if no league with the given
+// ID exists, it returns ErrNotFound.
+func (r *MemoryRepository) GetByID(_ context.Context, id leagues.LeagueID) (*model.League, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	l, exists := r.data[id]
+	if !exists {
+		return nil, fmt.Errorf("the league with the given ID '%s' is not found. Source: %w", id, svcerrors.ErrNotFound)
+	}
+	return clone(l), nil
+}
+
+// Create persists a new league instance to the in-memory repository and returns the league with an assigned ID.
+func (r *MemoryRepository) Create(_ context.Context, l *model.League) (*model.League, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if err := validateLeague(l); err != nil {
+		return nil, err
+	}
+
+	l.ID = leagues.LeagueID(strconv.Itoa(leagueCounter))
+	r.data[l.ID] = clone(l)
+	leagueCounter++
+
+	return l, nil
+}
+
+// Replace completely replaces an existing league instance with the provided one, using the ID from the provided
+// league to find which league to replace. This cannot be used to create a new League, and it is an idempotent
+// operation. If the league is missing or invalid, this returns the appropriate svcerror
+func (r *MemoryRepository) Replace(_ context.Context, l *model.League) (*model.League, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if err := validateLeague(l); err != nil {
+		return nil, err
+	} else if l.ID == "" {
+		return nil, fmt.Errorf("the league data sent with update is missing a league ID. Source: %w", svcerrors.ErrInvalidID)
+	} else if _, exists := r.data[l.ID]; !exists {
+		return nil, fmt.Errorf("the league with the given ID '%s' is not found. Source: %w", l.ID, svcerrors.ErrNotFound)
+	}
+
+	r.data[l.ID] = clone(l)
+	return l, nil
+}
+
+// DeleteByID deletes an existing league instance in the in-memory repository. Returns true if the league was found
+// and deleted, false otherwise. This is an idempotent operation.
+func (r *MemoryRepository) DeleteByID(_ context.Context, id leagues.LeagueID) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, exists := r.data[id]; exists {
+		delete(r.data, id)
+		return true, nil
+	}
+
+	return false, svcerrors.ErrNotFound
+}
+
+// List returns one page of the leagues matching the query, ordered by ascending ID.
+func (r *MemoryRepository) List(_ context.Context, q model.LeagueQuery) (*model.LeagueList, error) {
+	after, err := decodeCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	matches := []*model.League{}
+	for id, l := range r.data {
+		if (after != "" && compareLeagueIDs(id, after) <= 0) || !q.Matches(l) {
+			continue
+		}
+		matches = append(matches, l)
+	}
+	slices.SortFunc(matches, func(a, b *model.League) int {
+		return compareLeagueIDs(a.ID, b.ID)
+	})
+
+	limit := q.PageLimit()
+	result := &model.LeagueList{Leagues: []model.League{}}
+	for i, l := range matches {
+		if i == limit {
+			result.NextCursor = encodeCursor(result.Leagues[limit-1].ID)
+			break
+		}
+		result.Leagues = append(result.Leagues, *clone(l))
+	}
+
+	return result, nil
+}
+
+// clone copies a league on the way in and out of the repository, so callers changing a league they hold, such as
+// while generating its pairings or schedule, cannot change the stored one
+func clone(l *model.League) *model.League {
+	c := *l
+	c.Participants = slices.Clone(l.Participants)
+	for i, p := range c.Participants {
+		if p != nil {
+			cp := *p
+			cp.VictoryPointsByCategory = maps.Clone(p.VictoryPointsByCategory)
+			c.Participants[i] = &cp
+		}
+	}
+	c.RoundIDs = slices.Clone(l.RoundIDs)
+	c.Tiebreakers = slices.Clone(l.Tiebreakers)
+	if l.Scoring != nil {
+		scoring := *l.Scoring
+		c.Scoring = &scoring
+	}
+	if l.ArmyLists != nil {
+		rules := *l.ArmyLists
+		c.ArmyLists = &rules
+	}
+	return &c
+}
@@ -0,0 +1,144 @@
+package secondary
+
+import (
+	"context"
+	"errors"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"testing"
+)
+
+// Sort of in passing this also tests that the memory repository meets the Repository interface spec
+var _ Repository = (*MemoryRepository)(nil)
+
+func TestMemoryRepositoryCreateAndGet(t *testing.T) {
+	r := NewMemoryRepository()
+
+	l, err := r.Create(context.Background(), createFakeLeague())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if l.ID == "" {
+		t.Fatalf("Expected league ID to be assigned")
+	}
+
+	result, err := r.GetByID(context.Background(), l.ID)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	} else if result.Name != l.Name {
+		t.Errorf("Expected league name '%s', got '%s'", l.Name, result.Name)
+	}
+
+	if _, err = r.GetByID(context.Background(), "nope"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown ID, got %v", err)
+	}
+}
+
+func TestMemoryRepositoryReturnsCopies(t *testing.T) {
+	r := NewMemoryRepository()
+	l, err := r.Create(context.Background(), createFakeLeague())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	got, err := r.GetByID(context.Background(), l.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got.Name = "Changed without a Replace"
+	got.Tiebreakers = append(got.Tiebreakers, model.TiebreakerHeadToHead)
+	l.Name = "Changed after the Create"
+
+	stored, _ := r.GetByID(context.Background(), l.ID)
+	if stored.Name != createFakeLeague().Name || len(stored.Tiebreakers) != len(createFakeLeague().Tiebreakers) {
+		t.Errorf("Expected the stored league to be unchanged by changes to the copies, got %+v", stored)
+	}
+}
+
+func TestMemoryRepositoryRejectsInvalidLeague(t *testing.T) {
+	r := NewMemoryRepository()
+
+	l := createFakeLeague()
+	l.Name = ""
+	if _, err := r.Create(context.Background(), l); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for a league without a name, got %v", err)
+	}
+	if _, err := r.Create(context.Background(), nil); !errors.Is(err, svcerrors.ErrModelMissing) {
+		t.Errorf("Expected ErrModelMissing for a nil league, got %v", err)
+	}
+}
+
+func TestMemoryRepositoryReplaceAndDelete(t *testing.T) {
+	r := NewMemoryRepository()
+	l, err := r.Create(context.Background(), createFakeLeague())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	updated := *l
+	updated.Name = "Renamed"
+	if _, err = r.Replace(context.Background(), &updated); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result, _ := r.GetByID(context.Background(), l.ID); result.Name != "Renamed" {
+		t.Errorf("Expected the league to be replaced, got name '%s'", result.Name)
+	}
+
+	unknown := updated
+	unknown.ID = "nope"
+	if _, err = r.Replace(context.Background(), &unknown); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound replacing an unknown league, got %v", err)
+	}
+
+	if ok, err := r.DeleteByID(context.Background(), l.ID); !ok || err != nil {
+		t.Errorf("Expected the league to be deleted, got %v, %v", ok, err)
+	}
+	if _, err = r.GetByID(context.Background(), l.ID); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if ok, err := r.DeleteByID(context.Background(), l.ID); ok || !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected a second delete to find nothing, got %v, %v", ok, err)
+	}
+}
+
+func TestMemoryRepositoryList(t *testing.T) {
+	r := NewMemoryRepository()
+	for i := 0; i < 5; i++ {
+		l := createFakeLeague()
+		l.Active = i%2 == 0
+		if _, err := r.Create(context.Background(), l); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	active := true
+	page, err := r.List(context.Background(), model.LeagueQuery{Active: &active, Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(page.Leagues) != 2 || page.NextCursor == "" {
+		t.Fatalf("Expected a full first page with a cursor, got %d leagues and cursor '%s'", len(page.Leagues), page.NextCursor)
+	}
+
+	page, err = r.List(context.Background(), model.LeagueQuery{Active: &active, Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(page.Leagues) != 1 || page.NextCursor != "" {
+		t.Errorf("Expected the last active league on the second page, got %d leagues and cursor '%s'", len(page.Leagues), page.NextCursor)
+	}
+
+	if _, err = r.List(context.Background(), model.LeagueQuery{Cursor: "!!"}); !errors.Is(err, svcerrors.ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for a malformed cursor, got %v", err)
+	}
+}
+
+func createFakeLeague() *model.League {
+	return &model.League{
+		Name:              "Fall 2025 Acme Gaming League",
+		Active:            true,
+		StartDate:         "2025-09-01",
+		EndDate:           "2025-12-15",
+		ExpectedDayOfWeek: "Monday",
+	}
+}
@@ -0,0 +1,51 @@
+package secondary
+
+import (
+	"context"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"strings"
+)
+
+// Repository defines the port for writing Leagues to persistent storage
+type Repository interface {
+	// GetByID retrieves a league by ID from the repository, if no league with the given
+	// ID exists, it returns nil, svcerrors.ErrNotFound.
+	GetByID(ctx context.Context, id pkg.LeagueID) (*model.League, error)
+
+	// Create persists a new league instance to the repository and returns the league with an assigned ID.
+	Create(ctx context.Context, l *model.League) (*model.League, error)
+
+	// Replace completely replaces an existing league instance with the provided one, using the ID from the provided
+	// league to find which league to replace. This cannot be used to create a new League, and it is an idempotent
+	// operation.
+	Replace(ctx context.Context, l *model.League) (*model.League, error)
+
+	// DeleteByID deletes an existing league instance in the repository. Returns true if the league was found and
+	// deleted, false otherwise. This is an idempotent operation.
+	DeleteByID(ctx context.Context, id pkg.LeagueID) (bool, error)
+
+	// List returns one page of the leagues matching the filters in the query, ordered by ascending ID so that paging
+	// with the returned cursor is stable. A malformed cursor returns svcerrors.ErrInvalidQuery.
+	List(ctx context.Context, q model.LeagueQuery) (*model.LeagueList, error)
+}
+
+// NewDefaultRepository creates an instance of the default repository implementation, which is currently the
+// in-memory adapter
+func NewDefaultRepository() Repository {
+	return NewMemoryRepository()
+}
+
+// validateLeague checks the league before it is written by any adapter, returning an error wrapping
+// svcerrors.ErrModelInvalid or svcerrors.ErrModelMissing if it cannot be stored.
+func validateLeague(l *model.League) error {
+	v, f, err := l.IsValid()
+	if err != nil {
+		return err
+	} else if !v {
+		return fmt.Errorf("league %w: %s", svcerrors.ErrModelInvalid, strings.Join(f, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Package gateway contains clients for interacting with the leagues service from other services.
+// The package is meant to be public, and will commit to being backwards compatible within major versions.
+// The package primarily consists of the LeaguesGateway interface, with different implementations
+// of the interface for calling it in-memory or over HTTP.
+package gateway
+
+import (
+	"context"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+)
+
+// LeaguesGateway provides a set of methods for interacting with the Leagues service from outside the service.
+type LeaguesGateway interface {
+	// GetByID returns the league with the given id, or a svcerrors.ErrNotFound if no league with that id exists
+	GetByID(ctx context.Context, id leagues.LeagueID) (*model.League, error)
+
+	// Create persists a new league instance to the service and returns the league with an assigned ID.
+	// A generic error is returned if the league to create is missing, while specific validation errors are
+	// passed along from the service if the league is invalid in some way.
+	Create(ctx context.Context, l *model.League) (*model.League, error)
+
+	// Replace updates an existing league in the service with the provided league.
+	// A generic error is returned if the league to replace is not known to the service.
+	// This is an idempotent operation.
+	Replace(ctx context.Context, l *model.League) (*model.League, error)
+
+	// DeleteByID removes the league with the given id from the service. Returns true if the league was found and
+	// deleted, false otherwise. This is an idempotent operation.
+	DeleteByID(ctx context.Context, id leagues.LeagueID) (bool, error)
+
+	// List returns one page of the leagues matching the filters in the query, ordered by ID. Pass the NextCursor
+	// from the returned list back in the query to fetch the following page.
+	List(ctx context.Context, q model.LeagueQuery) (*model.LeagueList, error)
+
+	// Standings returns the ranked standings for the league with the given id
+	Standings(ctx context.Context, id leagues.LeagueID) (*model.Standings, error)
+}
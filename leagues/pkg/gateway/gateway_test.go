@@ -0,0 +1,225 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	gamesgateway "github.com/rpatton4/mesbg-league/games/pkg/gateway"
+	"github.com/rpatton4/mesbg-league/leagues/internal/primary"
+	"github.com/rpatton4/mesbg-league/leagues/internal/secondary"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	participants "github.com/rpatton4/mesbg-league/participants/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	roundsgateway "github.com/rpatton4/mesbg-league/rounds/pkg/gateway"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Both gateways have to satisfy the interface
+var _ LeaguesGateway = (*InProcessGateway)(nil)
+var _ LeaguesGateway = (*HTTPGateway)(nil)
+
+func TestInProcessGatewayContract(t *testing.T) {
+	runGatewayContract(t, func(t *testing.T) LeaguesGateway {
+		return NewInProcessGatewayWithController(newTestController(t))
+	})
+}
+
+func TestHTTPGatewayContract(t *testing.T) {
+	runGatewayContract(t, func(t *testing.T) LeaguesGateway {
+		srv := newTestLeaguesServer(t)
+		return New(srv.URL, WithHTTPClient(srv.Client()))
+	})
+}
+
+// runGatewayContract runs the same scenarios against any LeaguesGateway, so that callers get identical results and
+// errors whichever implementation they are given. newGateway is called for each case so every case starts empty.
+func runGatewayContract(t *testing.T, newGateway func(t *testing.T) LeaguesGateway) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, gw LeaguesGateway)
+	}{
+		{"CreateAndGet", testGatewayCreateAndGet},
+		{"CreateInvalid", testGatewayCreateInvalid},
+		{"GetUnknown", testGatewayGetUnknown},
+		{"Replace", testGatewayReplace},
+		{"Delete", testGatewayDelete},
+		{"List", testGatewayList},
+		{"Standings", testGatewayStandings},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, newGateway(t))
+		})
+	}
+}
+
+func testGatewayCreateAndGet(t *testing.T, gw LeaguesGateway) {
+	l, err := gw.Create(context.Background(), createFakeLeague())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if l.ID == "" {
+		t.Fatalf("Expected league ID to be assigned")
+	}
+
+	result, err := gw.GetByID(context.Background(), l.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.ID != l.ID || result.Name != l.Name || result.ExpectedDayOfWeek != l.ExpectedDayOfWeek {
+		t.Errorf("Expected the fetched league to match the created one, got %+v", result)
+	}
+}
+
+func testGatewayCreateInvalid(t *testing.T, gw LeaguesGateway) {
+	invalid := createFakeLeague()
+	invalid.EndDate = "2025-01-01"
+	if _, err := gw.Create(context.Background(), invalid); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error, got %v", err)
+	}
+
+	if _, err := gw.Create(context.Background(), nil); !errors.Is(err, svcerrors.ErrModelMissing) {
+		t.Errorf("Expected missing model error, got %v", err)
+	}
+}
+
+func testGatewayGetUnknown(t *testing.T, gw LeaguesGateway) {
+	if _, err := gw.GetByID(context.Background(), "9999"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if _, err := gw.GetByID(context.Background(), ""); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected invalid ID error, got %v", err)
+	}
+}
+
+func testGatewayReplace(t *testing.T, gw LeaguesGateway) {
+	l, err := gw.Create(context.Background(), createFakeLeague())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	l.Name = "Renamed"
+	result, err := gw.Replace(context.Background(), l)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Name != "Renamed" {
+		t.Errorf("Expected the name to be updated, got '%s'", result.Name)
+	}
+
+	unknown := *result
+	unknown.ID = "9999"
+	if _, err = gw.Replace(context.Background(), &unknown); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	missingID := *result
+	missingID.ID = ""
+	if _, err = gw.Replace(context.Background(), &missingID); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected invalid ID error, got %v", err)
+	}
+}
+
+func testGatewayDelete(t *testing.T, gw LeaguesGateway) {
+	l, err := gw.Create(context.Background(), createFakeLeague())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ok, err := gw.DeleteByID(context.Background(), l.ID)
+	if err != nil || !ok {
+		t.Fatalf("Expected delete to succeed, got %v, %v", ok, err)
+	}
+
+	ok, err = gw.DeleteByID(context.Background(), l.ID)
+	if !errors.Is(err, svcerrors.ErrNotFound) || ok {
+		t.Errorf("Expected not found deleting a second time, got %v, %v", ok, err)
+	}
+}
+
+func testGatewayList(t *testing.T, gw LeaguesGateway) {
+	for i := 0; i < 3; i++ {
+		l := createFakeLeague()
+		l.Active = i > 0
+		if _, err := gw.Create(context.Background(), l); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	active := true
+	l, err := gw.List(context.Background(), model.LeagueQuery{Active: &active, Limit: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(l.Leagues) != 1 || l.NextCursor == "" {
+		t.Fatalf("Expected one league and a cursor for the next page, got %+v", l)
+	}
+
+	l, err = gw.List(context.Background(), model.LeagueQuery{Active: &active, Limit: 1, Cursor: l.NextCursor})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(l.Leagues) != 1 || l.NextCursor != "" {
+		t.Errorf("Expected the last league and no cursor, got %+v", l)
+	}
+
+	if _, err = gw.List(context.Background(), model.LeagueQuery{Cursor: "!!"}); !errors.Is(err, svcerrors.ErrInvalidQuery) {
+		t.Errorf("Expected invalid query error, got %v", err)
+	}
+}
+
+func testGatewayStandings(t *testing.T, gw LeaguesGateway) {
+	l := createFakeLeague()
+	l.Participants = []*participants.Participant{{PlayerID: "a"}, {PlayerID: "b"}}
+	l, err := gw.Create(context.Background(), l)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	s, err := gw.Standings(context.Background(), l.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if s.LeagueID != l.ID || len(s.Rows) != 2 {
+		t.Errorf("Expected a row for each participant, got %+v", s)
+	}
+
+	if _, err = gw.Standings(context.Background(), "9999"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+// newTestController creates a leagues controller over a fresh memory repository, with the games and rounds kept in
+// process
+func newTestController(t *testing.T) primary.SingleController {
+	games, err := gamesgateway.NewDefaultInProcessGateway()
+	if err != nil {
+		t.Fatalf("Unable to set up the games gateway: %v", err)
+	}
+	return primary.NewTxnController(secondary.NewMemoryRepository(), games, roundsgateway.NewDefaultInProcessGateway())
+}
+
+// newTestLeaguesServer starts the Leagues service routes over a fresh memory repository, closed when the test ends
+func newTestLeaguesServer(t *testing.T) *httptest.Server {
+	router := http.NewServeMux()
+	api := humago.New(router, huma.DefaultConfig("Leagues Service", "1.0.0"))
+	primary.RegisterRoutes(api, primary.NewHumaHandler(newTestController(t)))
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func createFakeLeague() *model.League {
+	return &model.League{
+		Name:              "Fall 2025 Acme Gaming League",
+		Active:            true,
+		StartDate:         "2025-09-01",
+		EndDate:           "2025-12-15",
+		ExpectedDayOfWeek: "Monday",
+	}
+}
@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"context"
+	gamesgateway "github.com/rpatton4/mesbg-league/games/pkg/gateway"
+	"github.com/rpatton4/mesbg-league/leagues/internal/primary"
+	"github.com/rpatton4/mesbg-league/leagues/internal/secondary"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	roundsgateway "github.com/rpatton4/mesbg-league/rounds/pkg/gateway"
+)
+
+type InProcessGateway struct {
+	ctrl primary.SingleController
+}
+
+// NewInProcessGatewayWithController creates a new InProcessGateway with the provided controller.
+// This is intended primarily for use while testing, to provide a mock or stub controller.
+func NewInProcessGatewayWithController(ctrl primary.SingleController) *InProcessGateway {
+	return &InProcessGateway{ctrl}
+}
+
+// NewDefaultInProcessGateway creates a new InProcessGateway with a default repository and txncontroller, reading
+// and creating the games of the leagues through the given games gateway and their rounds through the given rounds
+// gateway.
+func NewDefaultInProcessGateway(games gamesgateway.GamesGateway, rounds roundsgateway.RoundsGateway) *InProcessGateway {
+	ctrl := primary.NewTxnController(secondary.NewDefaultRepository(), games, rounds)
+	return NewInProcessGatewayWithController(ctrl)
+}
+
+func (ipg *InProcessGateway) GetByID(ctx context.Context, id leagues.LeagueID) (*model.League, error) {
+	return ipg.ctrl.GetByID(ctx, id)
+}
+func (ipg *InProcessGateway) Create(ctx context.Context, l *model.League) (*model.League, error) {
+	return ipg.ctrl.Create(ctx, l)
+}
+func (ipg *InProcessGateway) Replace(ctx context.Context, l *model.League) (*model.League, error) {
+	return ipg.ctrl.Replace(ctx, l)
+}
+func (ipg *InProcessGateway) DeleteByID(ctx context.Context, id leagues.LeagueID) (bool, error) {
+	return ipg.ctrl.DeleteByID(ctx, id)
+}
+func (ipg *InProcessGateway) List(ctx context.Context, q model.LeagueQuery) (*model.LeagueList, error) {
+	return ipg.ctrl.List(ctx, q)
+}
+func (ipg *InProcessGateway) Standings(ctx context.Context, id leagues.LeagueID) (*model.Standings, error) {
+	return ipg.ctrl.Standings(ctx, id)
+}
@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPGatewayRetriesIdempotentCalls(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","name":"Test League"}`))
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithRetries(2, time.Millisecond))
+	l, err := gw.GetByID(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after retrying, got %v", err)
+	}
+	if l.ID != "1" || calls.Load() != 3 {
+		t.Errorf("Expected league 1 after 3 calls, got league %s after %d calls", l.ID, calls.Load())
+	}
+}
+
+func TestHTTPGatewayMapsConflict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "league already has games", http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()))
+	if _, err := gw.Standings(context.Background(), "1"); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected a conflict error, got %v", err)
+	}
+}
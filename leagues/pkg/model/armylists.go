@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+)
+
+// ArmyListRules holds the limits the army lists registered for a league have to keep to
+type ArmyListRules struct {
+	// PointsLimit is the most points an army list may cost, zero means there is no limit
+	PointsLimit int `json:"pointsLimit" example:"700" doc:"The most points an army list may cost, zero for no limit"`
+
+	// BowLimitPercent is the percentage of the warriors in an army list which may carry bows, rounding up, zero
+	// means there is no limit. The usual limit of a third is 33.
+	BowLimitPercent int `json:"bowLimitPercent" example:"33" doc:"The percentage of the warriors in an army list which may carry bows, rounding up, zero for no limit"`
+
+	// Alliances is the alliances allowed between the armies of a list with allied warbands, any alliance is
+	// allowed when it is empty
+	Alliances AlliancePolicy `json:"alliances,omitempty" enum:"any,noImpossible,historicalOnly" doc:"The alliances allowed between the armies of a list, any alliance is allowed when absent"`
+}
+
+// AlliancePolicy is the rule a league sets for the alliances between the armies of a list
+type AlliancePolicy string
+
+const (
+	// AlliancesAny allows Historical, Convenient and Impossible alliances
+	AlliancesAny AlliancePolicy = "any"
+
+	// AlliancesNoImpossible bans Impossible alliances, allowing Historical and Convenient ones
+	AlliancesNoImpossible AlliancePolicy = "noImpossible"
+
+	// AlliancesHistoricalOnly allows only Historical alliances
+	AlliancesHistoricalOnly AlliancePolicy = "historicalOnly"
+)
+
+// IsValid returns true if the policy is one of the known policies or is empty
+func (p AlliancePolicy) IsValid() bool {
+	switch p {
+	case "", AlliancesAny, AlliancesNoImpossible, AlliancesHistoricalOnly:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasStarted returns true once the start date of the league has been reached at the given time, after which the
+// army lists of the league are locked. A league without a start date has not started.
+func (l *League) HasStarted(now time.Time) bool {
+	start, err := time.Parse(DateLayout, l.StartDate)
+	return err == nil && !now.Before(start)
+}
@@ -3,36 +3,141 @@ package model
 import (
 	"github.com/rpatton4/mesbg-league/leagues/pkg"
 	participants "github.com/rpatton4/mesbg-league/participants/pkg/model"
-	rounds "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"log/slog"
+	"strings"
+	"time"
 )
 
+// DateLayout is the layout of the dates in a league, YYYY-MM-DD (ISO 8601), for use with the time package
+const DateLayout = "2006-01-02"
+
 // League is the model for a gaming league, which includes metadata about the league, its participants,
 // and the games played within it.
 type League struct {
 	// ID is the unique identifier for the league
-	ID pkg.LeagueID `json:"id"`
+	ID pkg.LeagueID `json:"id" example:"1" doc:"The unique identifier for the league, generated by the service"`
 
 	// Active indicates whether the league is currently either not yet started or in progress (true) or has ended (false)
-	Active bool `json:"active"`
+	Active bool `json:"active" example:"true" doc:"True while the league is upcoming or in progress, false once it has ended"`
 
 	// Name of the league, e.g. "Fall 2025 Acme Gaming League"
-	Name string `json:"name"`
+	Name string `json:"name" example:"Fall 2025 Acme Gaming League" doc:"The name of the league"`
 
 	// Participants is a slice of participants (players + metadata) in the league
-	Participants []*participants.Participant `json:"participants"`
+	Participants []*participants.Participant `json:"participants" doc:"The players taking part in the league"`
 
-	// Rounds is a slice of the rounds in the league, both those which have occurred and those which are upcoming
-	Rounds []*rounds.Round `json:"rounds"`
+	// RoundIDs is the slice of IDs for the rounds of the league, both those which have occurred and those which are
+	// upcoming. The rounds themselves, with their games, are owned by the rounds service.
+	RoundIDs []rounds.RoundID `json:"roundIds,omitempty" example:"[\"12\"]" doc:"The unique identifiers of the rounds of the league, both played and upcoming"`
 
 	// NumberOfGames is the total number of games in the league
-	NumberOfGames int `json:"numberOfGames"`
+	NumberOfGames int `json:"numberOfGames" example:"6" doc:"The total number of games in the league"`
 
 	// StartDate is the date of the first league game in YYYY-MM-DD format (ISO 8601)
-	StartDate string `json:"startDate"`
+	StartDate string `json:"startDate" example:"2025-09-01" doc:"The date of the first league game, in YYYY-MM-DD format"`
 
 	// EndDate is the date of the last league game in YYYY-MM-DD format (ISO 8601)
-	EndDate string `json:"endDate"`
+	EndDate string `json:"endDate" example:"2025-12-15" doc:"The date of the last league game, in YYYY-MM-DD format"`
 
 	// ExpectedDayOfWeek is the day of the week that games are generally expected to be played, e.g. "Monday", "Tuesday", etc.
-	ExpectedDayOfWeek string `json:"expectedDayOfWeek"`
+	ExpectedDayOfWeek string `json:"expectedDayOfWeek" example:"Monday" doc:"The day of the week games are generally played on"`
+
+	// Scoring holds the rules for turning game results into standings, DefaultScoringRules are used when it is nil
+	Scoring *ScoringRules `json:"scoring,omitempty" doc:"The rules for turning game results into standings, the defaults are used when absent"`
+
+	// Tiebreakers is the ordered list of tiebreakers used to rank players in the standings, each one only being
+	// used when all the ones before it leave players level. DefaultTiebreakers are used when it is empty.
+	Tiebreakers []TiebreakerName `json:"tiebreakers,omitempty" example:"[\"tournamentPoints\",\"headToHead\"]" doc:"The ordered tiebreakers used to rank the standings, the defaults are used when absent"`
+
+	// Format is how the league is run, a standard league when it is empty. In an escalation league the points
+	// limit rises from round to round, and the participants register an army list for each round.
+	Format Format `json:"format,omitempty" enum:"standard,escalation" doc:"How the league is run, standard when absent. In an escalation league the points limit rises each round and a list is registered for each round"`
+
+	// ArmyLists holds the limits the army lists of the participants have to keep to, lists are not limited when
+	// it is nil
+	ArmyLists *ArmyListRules `json:"armyLists,omitempty" doc:"The limits the army lists of the participants have to keep to, no limits apply when absent"`
+}
+
+// Format is how a league is run
+type Format string
+
+const (
+	// FormatStandard is a league where each participant registers one army list which is used in every round
+	FormatStandard Format = "standard"
+
+	// FormatEscalation is a league where the points limit of the rounds rises as the league goes on, and each
+	// participant registers an army list for each round to fit its limit
+	FormatEscalation Format = "escalation"
+)
+
+// IsEscalation returns true if the league is an escalation league
+func (l *League) IsEscalation() bool {
+	return l.Format == FormatEscalation
+}
+
+// ScoringOrDefault returns the scoring rules for the league, falling back to DefaultScoringRules if none are set
+func (l *League) ScoringOrDefault() ScoringRules {
+	if l.Scoring == nil {
+		return DefaultScoringRules()
+	}
+	return *l.Scoring
+}
+
+// IsValid checks if the league instance has all required fields set, and that the dates and day of the week can be
+// read, returning a boolean indicating validity. A slice of strings is returned containing information about any
+// invalid fields, one entry per field, and an error is returned if validity cannot be determined, for example if
+// the league instance is nil.
+func (l *League) IsValid() (bool, []string, error) {
+	invalidFields := []string{}
+	if l == nil {
+		return false, invalidFields, svcerrors.ErrModelMissing
+	}
+
+	if strings.TrimSpace(l.Name) == "" {
+		invalidFields = append(invalidFields, "Name is required")
+	}
+
+	start, startErr := time.Parse(DateLayout, l.StartDate)
+	if l.StartDate != "" && startErr != nil {
+		invalidFields = append(invalidFields, "StartDate='"+l.StartDate+"' is not in YYYY-MM-DD format")
+	}
+	end, endErr := time.Parse(DateLayout, l.EndDate)
+	if l.EndDate != "" && endErr != nil {
+		invalidFields = append(invalidFields, "EndDate='"+l.EndDate+"' is not in YYYY-MM-DD format")
+	}
+	if startErr == nil && endErr == nil && !start.Before(end) {
+		invalidFields = append(invalidFields, "StartDate='"+l.StartDate+"' is not before EndDate='"+l.EndDate+"'")
+	}
+
+	if _, ok := ParseWeekday(l.ExpectedDayOfWeek); l.ExpectedDayOfWeek != "" && !ok {
+		invalidFields = append(invalidFields, "ExpectedDayOfWeek='"+l.ExpectedDayOfWeek+"' is not a day of the week")
+	}
+
+	if l.ArmyLists != nil && (l.ArmyLists.PointsLimit < 0 || l.ArmyLists.BowLimitPercent < 0 || l.ArmyLists.BowLimitPercent > 100) {
+		invalidFields = append(invalidFields, "ArmyLists needs a PointsLimit of 0 or more and a BowLimitPercent from 0 to 100")
+	}
+	if l.Format != "" && l.Format != FormatStandard && l.Format != FormatEscalation {
+		invalidFields = append(invalidFields, "Format='"+string(l.Format)+"' is not one of standard or escalation")
+	}
+	if l.ArmyLists != nil && !l.ArmyLists.Alliances.IsValid() {
+		invalidFields = append(invalidFields, "ArmyLists.Alliances='"+string(l.ArmyLists.Alliances)+"' is not one of any, noImpossible or historicalOnly")
+	}
+
+	if len(invalidFields) > 0 {
+		slog.Warn("League is missing required fields or has invalid values", "leagueID", l.ID, "invalid", invalidFields)
+		return false, invalidFields, nil
+	}
+	return true, invalidFields, nil
+}
+
+// ParseWeekday reads the name of a day of the week such as "Monday", ignoring case
+func ParseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), strings.TrimSpace(s)) {
+			return d, true
+		}
+	}
+	return 0, false
 }
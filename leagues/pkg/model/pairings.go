@@ -0,0 +1,48 @@
+package model
+
+import (
+	games "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/leagues/pkg"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+)
+
+// Pairing is one match up in a round, either two players who will play each other or a single player with a bye
+type Pairing struct {
+	// Table numbers the pairings in the round from 1, the highest ranked players being on the first table
+	Table int `json:"table"`
+
+	// Side1ID is the player for the first side, the higher ranked of the two going into the round
+	Side1ID players.PlayerID `json:"side1Id"`
+
+	// Side2ID is the player for the second side, empty for a bye
+	Side2ID players.PlayerID `json:"side2Id,omitempty"`
+
+	// Bye is true when the player on the first side has no opponent this round
+	Bye bool `json:"bye"`
+
+	// Rematch is true when the two players have met before in the league, which only happens when there was no
+	// way to pair every player without one
+	Rematch bool `json:"rematch,omitempty"`
+}
+
+// RoundPairings holds the pairings generated for a round of a league, and the games created for them
+type RoundPairings struct {
+	// LeagueID is the league the round belongs to
+	LeagueID pkg.LeagueID `json:"leagueId"`
+
+	// RoundID is the round the games are created in, empty on a dry run for a round which has not been created yet
+	RoundID rounds.RoundID `json:"roundId,omitempty"`
+
+	// RoundNumber is the number of the round in the league
+	RoundNumber int `json:"roundNumber"`
+
+	// DryRun is true when the pairings are only proposed and no games were created for them
+	DryRun bool `json:"dryRun"`
+
+	// Pairings is the list of pairings, in table order
+	Pairings []Pairing `json:"pairings"`
+
+	// Games is the list of games created for the pairings, in the same order, empty for a dry run
+	Games []games.Game `json:"games,omitempty"`
+}
@@ -0,0 +1,49 @@
+package model
+
+// DefaultListLimit is the number of leagues returned in one page of a listing when the caller does not ask for a
+// specific page size
+const DefaultListLimit = 50
+
+// MaxListLimit is the largest page size which will be honoured when listing leagues, larger requests are capped
+const MaxListLimit = 200
+
+// LeagueQuery holds the filters and paging information used when listing leagues. Any filter left at its zero
+// value is not applied, so an empty LeagueQuery returns the first page of all leagues.
+type LeagueQuery struct {
+	// Active limits the results to leagues which are active (true) or have ended (false)
+	Active *bool
+
+	// Cursor is the opaque value returned as NextCursor by a previous page, used to fetch the page after it.
+	// Leave empty to start from the first page.
+	Cursor string
+
+	// Limit is the maximum number of leagues to return in the page, see DefaultListLimit and MaxListLimit
+	Limit int
+}
+
+// PageLimit returns the page size to use for the query, applying the default and the maximum
+func (q LeagueQuery) PageLimit() int {
+	if q.Limit <= 0 {
+		return DefaultListLimit
+	} else if q.Limit > MaxListLimit {
+		return MaxListLimit
+	}
+	return q.Limit
+}
+
+// Matches returns true if the given league passes all the filters set on the query. Paging fields are not considered.
+func (q LeagueQuery) Matches(l *League) bool {
+	if l == nil {
+		return false
+	}
+	return q.Active == nil || l.Active == *q.Active
+}
+
+// LeagueList is one page of leagues returned from a listing, in a stable order (ascending by ID).
+type LeagueList struct {
+	// Leagues holds the leagues in this page, it is empty rather than nil when nothing matches
+	Leagues []League `json:"leagues" doc:"The leagues in this page of results, ordered by ID"`
+
+	// NextCursor is set when there are more leagues after this page, pass it back as the cursor to fetch them
+	NextCursor string `json:"nextCursor,omitempty" example:"MTI" doc:"Opaque cursor for the next page, absent when this is the last page"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/rpatton4/mesbg-league/leagues/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+)
+
+// Schedule is the full set of rounds generated for a round-robin league, with the games for every round
+type Schedule struct {
+	// LeagueID is the league the schedule is for
+	LeagueID pkg.LeagueID `json:"leagueId"`
+
+	// Double is true when each pair of players meets twice, with the sides swapped the second time
+	Double bool `json:"double"`
+
+	// Rounds is the list of rounds, in order, each with its date and games
+	Rounds []*rounds.Round `json:"rounds"`
+}
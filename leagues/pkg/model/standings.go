@@ -0,0 +1,108 @@
+package model
+
+import (
+	"github.com/rpatton4/mesbg-league/leagues/pkg"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+)
+
+// ScoringRules sets how game results turn into tournament points for a league's standings, including how byes and
+// concessions are treated since no game was actually played for those.
+type ScoringRules struct {
+	// WinPoints is the number of tournament points awarded for winning a game on victory points
+	WinPoints int `json:"winPoints"`
+
+	// DrawPoints is the number of tournament points awarded to both sides when victory points are tied
+	DrawPoints int `json:"drawPoints"`
+
+	// LossPoints is the number of tournament points awarded for losing a game on victory points
+	LossPoints int `json:"lossPoints"`
+
+	// ByePoints is the number of tournament points awarded to a player receiving a bye
+	ByePoints int `json:"byePoints"`
+
+	// ByeVictoryPoints is the number of victory points credited to a player receiving a bye
+	ByeVictoryPoints int `json:"byeVictoryPoints"`
+
+	// ByeCountsAsWin records a bye in the player's wins as well as their byes
+	ByeCountsAsWin bool `json:"byeCountsAsWin"`
+
+	// ConcessionWinPoints is the number of tournament points awarded to the side which did not concede
+	ConcessionWinPoints int `json:"concessionWinPoints"`
+
+	// ConcessionLossPoints is the number of tournament points awarded to the side which conceded
+	ConcessionLossPoints int `json:"concessionLossPoints"`
+
+	// ConcessionVictoryPoints, when above zero, replaces the recorded victory points of a conceded game: the side
+	// which did not concede is credited with this many and the conceding side with none. At zero the recorded
+	// victory points are used as they are.
+	ConcessionVictoryPoints int `json:"concessionVictoryPoints"`
+}
+
+// DefaultScoringRules returns the rules used for leagues which have not set their own: 3 points for a win, 1 for a
+// draw, and byes and concessions scored as wins without any victory points
+func DefaultScoringRules() ScoringRules {
+	return ScoringRules{
+		WinPoints:            3,
+		DrawPoints:           1,
+		LossPoints:           0,
+		ByePoints:            3,
+		ByeCountsAsWin:       true,
+		ConcessionWinPoints:  3,
+		ConcessionLossPoints: 0,
+	}
+}
+
+// Standings is the ranked table of results for a league, computed from its completed games
+type Standings struct {
+	// LeagueID is the league the standings are for
+	LeagueID pkg.LeagueID `json:"leagueId"`
+
+	// Rows holds one entry per player, in rank order
+	Rows []StandingsRow `json:"rows"`
+}
+
+// StandingsRow is one player's line in the standings table
+type StandingsRow struct {
+	// Rank is the player's position in the table, starting at 1. Players who cannot be separated share a rank.
+	Rank int `json:"rank"`
+
+	// PlayerID identifies the player the row is for
+	PlayerID players.PlayerID `json:"playerId"`
+
+	// Played is the number of games counted for the player, including byes and concessions
+	Played int `json:"played"`
+
+	// Wins is the number of games won, including concessions by the opponent and, depending on the rules, byes
+	Wins int `json:"wins"`
+
+	// Draws is the number of games drawn
+	Draws int `json:"draws"`
+
+	// Losses is the number of games lost, including games the player conceded
+	Losses int `json:"losses"`
+
+	// Byes is the number of byes received
+	Byes int `json:"byes"`
+
+	// TournamentPoints is the total of the points awarded by the league's scoring rules
+	TournamentPoints int `json:"tournamentPoints"`
+
+	// VictoryPointsScored is the total victory points scored by the player
+	VictoryPointsScored int `json:"victoryPointsScored"`
+
+	// VictoryPointsConceded is the total victory points scored against the player
+	VictoryPointsConceded int `json:"victoryPointsConceded"`
+
+	// VictoryPointDifferential is VictoryPointsScored less VictoryPointsConceded
+	VictoryPointDifferential int `json:"victoryPointDifferential"`
+
+	// GeneralsKilled is the number of games in which the player killed the opposing general
+	GeneralsKilled int `json:"generalsKilled"`
+
+	// StrengthOfSchedule is the total tournament points of every opponent the player has faced, once per game
+	StrengthOfSchedule int `json:"strengthOfSchedule"`
+
+	// DecidedBy is the tiebreaker which separated the player from the one in the next row, empty for the last row
+	// and when the two players are level on every tiebreaker
+	DecidedBy TiebreakerName `json:"decidedBy,omitempty"`
+}
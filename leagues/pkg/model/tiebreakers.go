@@ -0,0 +1,37 @@
+package model
+
+// TiebreakerName identifies one of the tiebreakers used to order players in the standings
+type TiebreakerName string
+
+const (
+	// TiebreakerTournamentPoints ranks players with more tournament points higher
+	TiebreakerTournamentPoints TiebreakerName = "tournamentPoints"
+
+	// TiebreakerVictoryPointDifferential ranks players with a higher victory point differential higher
+	TiebreakerVictoryPointDifferential TiebreakerName = "victoryPointDifferential"
+
+	// TiebreakerGeneralsKilled ranks players who killed more opposing generals higher
+	TiebreakerGeneralsKilled TiebreakerName = "generalsKilled"
+
+	// TiebreakerHeadToHead ranks the player who earned more tournament points in the games between the two players
+	// higher. Players who have not played each other are level.
+	TiebreakerHeadToHead TiebreakerName = "headToHead"
+
+	// TiebreakerStrengthOfSchedule ranks players whose opponents earned more tournament points in total higher
+	TiebreakerStrengthOfSchedule TiebreakerName = "strengthOfSchedule"
+)
+
+// DefaultTiebreakers returns the tiebreakers used for leagues which have not set their own: tournament points and
+// then victory point differential
+func DefaultTiebreakers() []TiebreakerName {
+	return []TiebreakerName{TiebreakerTournamentPoints, TiebreakerVictoryPointDifferential}
+}
+
+// TiebreakersOrDefault returns the ordered tiebreakers for the league, falling back to DefaultTiebreakers if none
+// are set
+func (l *League) TiebreakersOrDefault() []TiebreakerName {
+	if len(l.Tiebreakers) == 0 {
+		return DefaultTiebreakers()
+	}
+	return l.Tiebreakers
+}
@@ -1,12 +1,18 @@
 package main
 
 import (
+	gamesgateway "github.com/rpatton4/mesbg-league/games/pkg/gateway"
+	leaguesgateway "github.com/rpatton4/mesbg-league/leagues/pkg/gateway"
 	"github.com/rpatton4/mesbg-league/participants/internal/controller/participants"
 	handlerhttp "github.com/rpatton4/mesbg-league/participants/internal/handler/http"
 	"github.com/rpatton4/mesbg-league/participants/internal/repository/memory"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	roundsgateway "github.com/rpatton4/mesbg-league/rounds/pkg/gateway"
 	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 )
 
 func main() {
@@ -14,14 +20,60 @@ func main() {
 	slog.SetDefault(slog.New(logHandler))
 
 	slog.Info("Starting the Participants service...")
+	leaguesAddr := os.Getenv("LEAGUES_SERVICE_ADDR")
+	if leaguesAddr == "" {
+		leaguesAddr = "http://localhost:8082"
+	}
+	gamesAddr := os.Getenv("GAMES_SERVICE_ADDR")
+	if gamesAddr == "" {
+		gamesAddr = "http://localhost:8081"
+	}
+	roundsAddr := os.Getenv("ROUNDS_SERVICE_ADDR")
+	if roundsAddr == "" {
+		roundsAddr = "http://localhost:8085"
+	}
+
+	// Events are sent to the webhooks of the services reacting to participants, PARTICIPANTS_EVENT_WEBHOOKS is a comma
+	// separated list of their URLs
+	bus := events.NewWebhookBus(nil)
+	for _, url := range strings.Split(os.Getenv("PARTICIPANTS_EVENT_WEBHOOKS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			bus.AddWebhook(url)
+		}
+	}
+
 	repo := memory.New()
-	ctrl := participants.New(repo)
+	ctrl := participants.New(repo, leaguesgateway.New(leaguesAddr), gamesgateway.New(gamesAddr), roundsgateway.New(roundsAddr), bus)
 	handler := handlerhttp.New(ctrl)
 
+	// Events from the other services arrive as webhooks, the games service sends them here when its
+	// GAMES_EVENT_WEBHOOKS includes http://<this service>/events
+	receiver := events.NewWebhookReceiver()
+	ctrl.Subscribe(receiver)
+
+	// Unlocking army lists is kept for the organizers, PARTICIPANTS_ADMINS is a comma separated list of the actors who
+	// are
+	admins := []string{}
+	for _, admin := range strings.Split(os.Getenv("PARTICIPANTS_ADMINS"), ",") {
+		if admin = strings.TrimSpace(admin); admin != "" {
+			admins = append(admins, admin)
+		}
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle("/participants/{id}", http.HandlerFunc(handler.DemuxWithID))
 	mux.Handle("/participants", http.HandlerFunc(handler.Demux))
-	if err := http.ListenAndServe(":8083", nil); err != nil {
+	mux.Handle("POST /participants/{id}/recompute", http.HandlerFunc(handler.Recompute))
+	mux.Handle("POST /leagues/{leagueId}/participants/recompute", http.HandlerFunc(handler.RecomputeLeague))
+	mux.Handle("GET /participants/{id}/army-list", http.HandlerFunc(handler.GetArmyList))
+	mux.Handle("PUT /participants/{id}/army-list", http.HandlerFunc(handler.PutArmyList))
+	mux.Handle("POST /participants/{id}/army-list/unlock", auth.RequireAdminHandler(admins, http.HandlerFunc(handler.UnlockArmyList)))
+	mux.Handle("POST /participants/{id}/army-list/import", http.HandlerFunc(handler.ImportArmyList))
+	mux.Handle("GET /participants/{id}/army-list/export", http.HandlerFunc(handler.ExportArmyList))
+	mux.Handle("GET /games/{gameId}/army-lists", http.HandlerFunc(handler.GameArmyLists))
+	mux.Handle("POST /events", receiver)
+	// The actor making each request is recorded on the changes it makes, such as the organizer unlocking an army list
+	if err := http.ListenAndServe(":8083", auth.ActorHandler(mux)); err != nil {
 		slog.Error("Failed to start HTTP server", "error", err.Error())
 		panic(err)
 	}
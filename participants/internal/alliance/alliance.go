@@ -0,0 +1,179 @@
+// Package alliance classifies the alliances between the armies of a list. The matrix of which armies are allies
+// comes from the army books and never changes while the service is running, so it is seeded from a data file
+// embedded in the binary rather than kept in a repository.
+//
+// Two armies are Historical allies if either of them lists the other as a historical ally. Otherwise two armies on
+// the same side, good or evil, are Convenient allies and two armies on opposite sides are Impossible allies.
+package alliance
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Level is the kind of alliance between two armies, from the most to the least trusting
+type Level string
+
+const (
+	Historical Level = "historical"
+	Convenient Level = "convenient"
+	Impossible Level = "impossible"
+)
+
+// rank orders the levels so the worst of them can be found
+func (l Level) rank() int {
+	return slices.Index([]Level{Historical, Convenient, Impossible}, l)
+}
+
+// The sides an army can fight for
+const (
+	sideGood = "good"
+	sideEvil = "evil"
+)
+
+// seed is the data file holding the alliance matrix, as a JSON array of entry
+//
+//go:embed alliances.json
+var seed []byte
+
+// entry is one army in the alliance matrix data file
+type entry struct {
+	Army       string   `json:"army"`
+	Side       string   `json:"side"`
+	Historical []string `json:"historical"`
+}
+
+// Matrix is the read only alliance matrix, safe for use by any number of goroutines
+type Matrix struct {
+	// armies holds each army by its name in lower case
+	armies map[string]*entry
+}
+
+// Pairing is the alliance between two of the armies of a list
+type Pairing struct {
+	First  string `json:"first"`
+	Second string `json:"second"`
+	Level  Level  `json:"level"`
+}
+
+// Classification is the alliance of a whole list, which is as bad as the worst pairing of its armies
+type Classification struct {
+	// Level is the worst level of all the pairings, Historical for a list chosen from a single army
+	Level Level `json:"level"`
+
+	// Pairings holds the alliance between each pair of armies in the list, in the order the armies appear
+	Pairings []Pairing `json:"pairings"`
+}
+
+// defaultMatrix is loaded from the embedded seed the first time it is asked for
+var defaultMatrix = sync.OnceValues(func() (*Matrix, error) {
+	return Load(seed)
+})
+
+// Default returns the alliance matrix of the army books, loaded from the embedded data file
+func Default() (*Matrix, error) {
+	return defaultMatrix()
+}
+
+// Load creates a matrix from a JSON array of armies. Every army has to be named once, be on the good or evil side,
+// and only list armies in the matrix as historical allies, otherwise an error wrapping svcerrors.ErrModelInvalid is
+// returned.
+func Load(data []byte) (*Matrix, error) {
+	var entries []entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("unable to read the alliance matrix: %w", err)
+	}
+
+	m := &Matrix{armies: make(map[string]*entry, len(entries))}
+	for i := range entries {
+		e := &entries[i]
+		if strings.TrimSpace(e.Army) == "" {
+			return nil, fmt.Errorf("alliance matrix army %d is %w: the army is required", i+1, svcerrors.ErrModelInvalid)
+		} else if e.Side != sideGood && e.Side != sideEvil {
+			return nil, fmt.Errorf("alliance matrix army '%s' is %w: the side '%s' must be %s or %s", e.Army, svcerrors.ErrModelInvalid, e.Side, sideGood, sideEvil)
+		}
+		if _, dup := m.armies[key(e.Army)]; dup {
+			return nil, fmt.Errorf("alliance matrix army '%s' is %w: the army is named more than once", e.Army, svcerrors.ErrModelInvalid)
+		}
+		m.armies[key(e.Army)] = e
+	}
+	for _, e := range entries {
+		for _, ally := range e.Historical {
+			if _, ok := m.armies[key(ally)]; !ok {
+				return nil, fmt.Errorf("alliance matrix army '%s' is %w: the historical ally '%s' is not in the matrix", e.Army, svcerrors.ErrModelInvalid, ally)
+			}
+		}
+	}
+	return m, nil
+}
+
+// Classify works out the alliance between every pair of the given armies, and so the alliance of a list chosen
+// from them. The names of the armies are matched ignoring case. A list chosen from a single army is not an alliance
+// and is always Historical, otherwise every army has to be in the matrix or an error wrapping
+// svcerrors.ErrModelInvalid is returned naming those which are not.
+func (m *Matrix) Classify(armies []string) (*Classification, error) {
+	c := &Classification{Level: Historical, Pairings: []Pairing{}}
+	if len(armies) < 2 {
+		return c, nil
+	}
+
+	unknown := []string{}
+	for _, army := range armies {
+		if _, ok := m.armies[key(army)]; !ok {
+			unknown = append(unknown, army)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("the alliance cannot be worked out as %w armies are not in the alliance matrix: %s", svcerrors.ErrModelInvalid, strings.Join(unknown, ", "))
+	}
+
+	for i, first := range armies {
+		for _, second := range armies[i+1:] {
+			p := Pairing{First: first, Second: second, Level: m.level(first, second)}
+			c.Pairings = append(c.Pairings, p)
+			if p.Level.rank() > c.Level.rank() {
+				c.Level = p.Level
+			}
+		}
+	}
+	return c, nil
+}
+
+// Breaking returns the pairings which are worse than the given level, and so break a rule allowing alliances up to
+// that level
+func (c *Classification) Breaking(allowed Level) []Pairing {
+	broken := []Pairing{}
+	for _, p := range c.Pairings {
+		if p.Level.rank() > allowed.rank() {
+			broken = append(broken, p)
+		}
+	}
+	return broken
+}
+
+// level returns the alliance between two armies which are both known to be in the matrix
+func (m *Matrix) level(first, second string) Level {
+	a, b := m.armies[key(first)], m.armies[key(second)]
+	historical := func(e *entry, ally string) bool {
+		return slices.ContainsFunc(e.Historical, func(s string) bool { return key(s) == key(ally) })
+	}
+
+	switch {
+	case historical(a, b.Army) || historical(b, a.Army):
+		return Historical
+	case a.Side == b.Side:
+		return Convenient
+	default:
+		return Impossible
+	}
+}
+
+// key is the name of an army as it is held in the matrix
+func key(army string) string {
+	return strings.ToLower(strings.TrimSpace(army))
+}
@@ -0,0 +1,88 @@
+package alliance
+
+import (
+	"errors"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	m, err := Default()
+	if err != nil {
+		t.Fatalf("Expected the embedded alliance matrix to load, got %v", err)
+	}
+
+	cases := []struct {
+		armies []string
+		want   Level
+	}{
+		{[]string{"Minas Tirith"}, Historical},
+		{[]string{"Minas Tirith", "rohan"}, Historical},
+		{[]string{"Rohan", "Minas Tirith"}, Historical},
+		{[]string{"Minas Tirith", "Rivendell"}, Convenient},
+		{[]string{"Minas Tirith", "Rohan", "Mordor"}, Impossible},
+		{[]string{"Not An Army"}, Historical},
+	}
+	for _, tc := range cases {
+		c, err := m.Classify(tc.armies)
+		if err != nil {
+			t.Fatalf("Expected %v to be classified, got %v", tc.armies, err)
+		}
+		if c.Level != tc.want {
+			t.Errorf("Expected %v to be a %s alliance, got %s", tc.armies, tc.want, c.Level)
+		}
+	}
+}
+
+func TestClassifyReportsPairings(t *testing.T) {
+	m, err := Default()
+	if err != nil {
+		t.Fatalf("Expected the embedded alliance matrix to load, got %v", err)
+	}
+
+	c, err := m.Classify([]string{"Minas Tirith", "Rohan", "Rivendell", "Mordor"})
+	if err != nil {
+		t.Fatalf("Expected the armies to be classified, got %v", err)
+	}
+	if len(c.Pairings) != 6 {
+		t.Fatalf("Expected a pairing for each of the 6 pairs of armies, got %+v", c.Pairings)
+	}
+
+	broken := c.Breaking(Convenient)
+	if len(broken) != 3 {
+		t.Fatalf("Expected the 3 pairings with Mordor to break a rule allowing Convenient alliances, got %+v", broken)
+	}
+	for _, p := range broken {
+		if p.Second != "Mordor" || p.Level != Impossible {
+			t.Errorf("Expected an Impossible pairing with Mordor, got %+v", p)
+		}
+	}
+	if broken = c.Breaking(Historical); len(broken) != 5 {
+		t.Errorf("Expected every pairing but Minas Tirith and Rohan to break a rule allowing only Historical alliances, got %+v", broken)
+	}
+}
+
+func TestClassifyUnknownArmy(t *testing.T) {
+	m, err := Default()
+	if err != nil {
+		t.Fatalf("Expected the embedded alliance matrix to load, got %v", err)
+	}
+
+	if _, err = m.Classify([]string{"Minas Tirith", "Not An Army"}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for an army not in the matrix, got %v", err)
+	}
+}
+
+func TestLoadRejectsInvalidMatrix(t *testing.T) {
+	cases := map[string]string{
+		"no army":      `[{"army": "", "side": "good"}]`,
+		"bad side":     `[{"army": "Rohan", "side": "neutral"}]`,
+		"duplicate":    `[{"army": "Rohan", "side": "good"}, {"army": "rohan", "side": "good"}]`,
+		"unknown ally": `[{"army": "Rohan", "side": "good", "historical": ["Gondor"]}]`,
+	}
+	for name, data := range cases {
+		if _, err := Load([]byte(data)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+			t.Errorf("%s: expected ErrModelInvalid, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package participants
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	leaguesmodel "github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/participants/internal/alliance"
+	"github.com/rpatton4/mesbg-league/participants/pkg/armylist"
+	"github.com/rpatton4/mesbg-league/participants/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	roundsmodel "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// roundSource is the part of the rounds gateway needed to find the league and points limit of a round
+type roundSource interface {
+	GetByID(ctx context.Context, id rounds.RoundID) (*roundsmodel.Round, error)
+}
+
+// GetArmyList returns the army list registered by the participant with the given id for the given round, or the
+// list used in every round when the round is empty. svcerrors.ErrNotFound is returned if they have not registered
+// one.
+func (c *Controller) GetArmyList(ctx context.Context, id model.ParticipantID, roundID string) (*model.ArmyList, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+	return c.repo.GetArmyList(ctx, id, roundID)
+}
+
+// RegisterArmyList registers the army list for the participant with the given id, replacing any list registered
+// before. A list for a single round, given by roundID, can only be registered in an escalation league, otherwise
+// the list is used in every round. The totals of the list are worked out here, and the list has to keep to the
+// limits of the participant's league and the points limit of the round or svcerrors.ErrModelInvalid is returned
+// listing what is wrong. Once the league has started a registered list is locked and svcerrors.ErrConflict is
+// returned, unless an organizer has unlocked it for one more change.
+func (c *Controller) RegisterArmyList(ctx context.Context, id model.ParticipantID, roundID string, a *model.ArmyList) (*model.ArmyList, error) {
+	if a == nil {
+		return nil, fmt.Errorf("the army list for participant '%s' is missing. Source: %w", id, svcerrors.ErrModelMissing)
+	} else if a.ParticipantID != "" && a.ParticipantID != id {
+		return nil, fmt.Errorf("the army list is for participant '%s', not '%s'. Source: %w", a.ParticipantID, id, svcerrors.ErrModelInvalid)
+	} else if a.RoundID != "" && a.RoundID != roundID {
+		return nil, fmt.Errorf("the army list is for round '%s', not '%s'. Source: %w", a.RoundID, roundID, svcerrors.ErrModelInvalid)
+	}
+
+	p, l, err := c.participantAndLeague(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var pointsLimit, bowLimitPercent int
+	if rules := l.ArmyLists; rules != nil {
+		pointsLimit, bowLimitPercent = rules.PointsLimit, rules.BowLimitPercent
+	}
+	if roundID != "" {
+		r, err := c.escalationRound(ctx, l, roundID)
+		if err != nil {
+			return nil, err
+		}
+		pointsLimit = cmp.Or(r.PointsLimit, pointsLimit)
+	}
+
+	existing, err := c.repo.GetArmyList(ctx, id, roundID)
+	if err != nil && !errors.Is(err, svcerrors.ErrNotFound) {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if existing != nil && !existing.Unlocked && l.HasStarted(now) {
+		return nil, fmt.Errorf("the army list of participant '%s' is locked as league '%s' has started, an organizer has to unlock it. Source: %w", id, l.ID, svcerrors.ErrConflict)
+	}
+
+	list := *a
+	list.ParticipantID, list.RoundID = p.ID, roundID
+	list.ComputeTotals()
+	if ok, invalid := list.IsValid(); !ok {
+		return nil, fmt.Errorf("army list %w: %s", svcerrors.ErrModelInvalid, strings.Join(invalid, ", "))
+	}
+	if broken := list.CheckLimits(pointsLimit, bowLimitPercent); len(broken) > 0 {
+		return nil, fmt.Errorf("army list %w for league '%s': %s", svcerrors.ErrModelInvalid, l.ID, strings.Join(broken, ", "))
+	}
+	if err = checkAlliance(&list, l); err != nil {
+		return nil, err
+	}
+
+	// An unlock only lasts for one change
+	list.Unlocked, list.UnlockedBy = false, ""
+	list.UpdatedAt = now
+	return c.repo.ReplaceArmyList(ctx, &list)
+}
+
+// escalationRound reads the round of the escalation league with the given id from the rounds service,
+// svcerrors.ErrModelInvalid is returned if the league is not an escalation league or does not have the round
+func (c *Controller) escalationRound(ctx context.Context, l *leaguesmodel.League, roundID string) (*roundsmodel.Round, error) {
+	if !l.IsEscalation() {
+		return nil, fmt.Errorf("an army list for round '%s' cannot be registered as league '%s' is not an escalation league. Source: %w", roundID, l.ID, svcerrors.ErrModelInvalid)
+	}
+
+	r, err := c.rounds.GetByID(ctx, rounds.RoundID(roundID))
+	if errors.Is(err, svcerrors.ErrNotFound) || (err == nil && r.LeagueID != l.ID) {
+		return nil, fmt.Errorf("round '%s' is not in league '%s'. Source: %w", roundID, l.ID, svcerrors.ErrModelInvalid)
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read round '%s': %w", roundID, err)
+	}
+	return r, nil
+}
+
+// checkAlliance works out the alliance between the armies of the list, and checks it is allowed by the alliance
+// policy of the league. svcerrors.ErrModelInvalid is returned naming each pairing of armies which breaks the policy,
+// or naming the armies which are not in the alliance matrix when the league restricts alliances. Without a
+// restriction a list with an army missing from the matrix is accepted, it is just not classified.
+func checkAlliance(list *model.ArmyList, l *leaguesmodel.League) error {
+	list.Alliance = ""
+	armies := list.Armies()
+	if len(armies) < 2 {
+		return nil
+	}
+
+	allowed := alliance.Impossible
+	if l.ArmyLists != nil {
+		switch l.ArmyLists.Alliances {
+		case leaguesmodel.AlliancesNoImpossible:
+			allowed = alliance.Convenient
+		case leaguesmodel.AlliancesHistoricalOnly:
+			allowed = alliance.Historical
+		}
+	}
+
+	m, err := alliance.Default()
+	if err != nil {
+		return err
+	}
+	c, err := m.Classify(armies)
+	if err != nil {
+		if allowed == alliance.Impossible {
+			return nil
+		}
+		return fmt.Errorf("army list for league '%s': %w", l.ID, err)
+	}
+	list.Alliance = string(c.Level)
+
+	broken := []string{}
+	for _, p := range c.Breaking(allowed) {
+		broken = append(broken, fmt.Sprintf("%s and %s are %s allies", p.First, p.Second, p.Level))
+	}
+	if len(broken) > 0 {
+		return fmt.Errorf("army list %w for league '%s' with the %s alliance policy: %s", svcerrors.ErrModelInvalid, l.ID, l.ArmyLists.Alliances, strings.Join(broken, ", "))
+	}
+	return nil
+}
+
+// UnlockArmyList lets the participant with the given id change their army list for the given round, or the list
+// used in every round when the round is empty, once more after their league has started, recording the organizer
+// who unlocked it, which is the actor making the request. svcerrors.ErrNotFound is returned if the participant has
+// not registered the list, and svcerrors.ErrModelInvalid if the organizer is not given.
+func (c *Controller) UnlockArmyList(ctx context.Context, id model.ParticipantID, roundID, by string) (*model.ArmyList, error) {
+	if strings.TrimSpace(by) == "" {
+		return nil, fmt.Errorf("the organizer unlocking the army list of participant '%s' is required. Source: %w", id, svcerrors.ErrModelInvalid)
+	}
+
+	existing, err := c.GetArmyList(ctx, id, roundID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := *existing
+	list.Unlocked, list.UnlockedBy = true, by
+	slog.Info("Army list unlocked", "participantID", id, "roundID", roundID, "unlockedBy", by)
+	return c.repo.ReplaceArmyList(ctx, &list)
+}
+
+// Formats an army list can be imported from or exported to
+const (
+	// FormatText is the plain text format army builders export, see armylist.ParseText
+	FormatText = "text"
+
+	// FormatJSON is the documented JSON format, see armylist.ParseJSON
+	FormatJSON = "json"
+)
+
+// ImportArmyList reads an army list in the given format and registers it for the participant with the given id,
+// and the given round in an escalation league, in the same way as RegisterArmyList. The list is only registered if
+// every part of it could be read and dryRun is false, otherwise the list is returned along with what could not be
+// read so the player can fix it. A svcerrors.ErrInvalidQuery is returned for an unknown format, and
+// svcerrors.ErrModelInvalid if the list cannot be read at all.
+func (c *Controller) ImportArmyList(ctx context.Context, id model.ParticipantID, roundID, format string, data []byte, dryRun bool) (*model.ArmyListImport, error) {
+	var (
+		list     *model.ArmyList
+		unparsed []model.UnparsedLine
+		err      error
+	)
+	switch format {
+	case "", FormatText:
+		list, unparsed = armylist.ParseText(string(data))
+	case FormatJSON:
+		if list, unparsed, err = armylist.ParseJSON(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("the army list format '%s' is not known, use %s or %s. Source: %w", format, FormatText, FormatJSON, svcerrors.ErrInvalidQuery)
+	}
+
+	list.ParticipantID, list.RoundID = id, roundID
+	imported := &model.ArmyListImport{ArmyList: list, Unparsed: unparsed}
+	if dryRun || len(unparsed) > 0 {
+		return imported, nil
+	}
+
+	if imported.ArmyList, err = c.RegisterArmyList(ctx, id, roundID, list); err != nil {
+		return nil, err
+	}
+	imported.Registered = true
+	return imported, nil
+}
+
+// ExportArmyList writes the army list of the participant with the given id for the given round, or the list used
+// in every round when the round is empty, in the plain text format which can be imported again.
+// svcerrors.ErrNotFound is returned if they have not registered the list.
+func (c *Controller) ExportArmyList(ctx context.Context, id model.ParticipantID, roundID string) (string, error) {
+	a, err := c.GetArmyList(ctx, id, roundID)
+	if err != nil {
+		return "", err
+	}
+	return armylist.FormatText(a), nil
+}
+
+// ArmyListsForGame returns the army lists of both sides of the game with the given id, so each player can see what
+// they face. The participant for each side is the one in the league the game's round belongs to, and in an
+// escalation league the list they registered for the round is used if they registered one. A side without a
+// registered list is left out. Each list is checked against the points limit of the round, which it may no longer
+// fit if the limit changed after it was registered. svcerrors.ErrNotFound is returned if there is no such game.
+func (c *Controller) ArmyListsForGame(ctx context.Context, id games.GameID) (*model.GameArmyLists, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+
+	g, err := c.games.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := &model.GameArmyLists{GameID: string(g.ID)}
+	r, l, err := c.roundAndLeague(ctx, g)
+	if err != nil || r == nil {
+		return lists, err
+	}
+	if lists.Side1, err = c.sideArmyList(ctx, l, r, g.Side1ID); err != nil {
+		return nil, err
+	}
+	if lists.Side2, err = c.sideArmyList(ctx, l, r, g.Side2ID); err != nil {
+		return nil, err
+	}
+
+	if r.PointsLimit == 0 {
+		return lists, nil
+	}
+	lists.PointsLimit = r.PointsLimit
+	for i, a := range []*model.ArmyList{lists.Side1, lists.Side2} {
+		if a != nil && a.TotalPoints > r.PointsLimit {
+			lists.OverLimit = append(lists.OverLimit, fmt.Sprintf("the list of side %d costs %d points, over the limit of %d for round '%s'", i+1, a.TotalPoints, r.PointsLimit, r.ID))
+		}
+	}
+	return lists, nil
+}
+
+// roundAndLeague reads the round of the game from the rounds service along with the league it belongs to, nil is
+// returned for both if the game has no round or either of them no longer exists
+func (c *Controller) roundAndLeague(ctx context.Context, g *gamesmodel.Game) (*roundsmodel.Round, *leaguesmodel.League, error) {
+	if g.RoundID == "" {
+		return nil, nil, nil
+	}
+
+	r, err := c.rounds.GetByID(ctx, g.RoundID)
+	if errors.Is(err, svcerrors.ErrNotFound) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("unable to read round '%s' of game '%s': %w", g.RoundID, g.ID, err)
+	}
+
+	l, err := c.leagues.GetByID(ctx, r.LeagueID)
+	if errors.Is(err, svcerrors.ErrNotFound) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("unable to read league '%s': %w", r.LeagueID, err)
+	}
+	return r, l, nil
+}
+
+// sideArmyList returns the army list the player's participant in the league uses in the round, or nil if there is
+// no such participant or they have not registered a list
+func (c *Controller) sideArmyList(ctx context.Context, l *leaguesmodel.League, r *roundsmodel.Round, playerID players.PlayerID) (*model.ArmyList, error) {
+	if playerID == "" {
+		return nil, nil
+	}
+
+	ps, err := c.repo.ListByPlayer(ctx, string(playerID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the participants of player '%s': %w", playerID, err)
+	}
+	for _, p := range ps {
+		if p.LeagueID != string(l.ID) {
+			continue
+		}
+
+		if l.IsEscalation() {
+			a, err := c.repo.GetArmyList(ctx, p.ID, string(r.ID))
+			if !errors.Is(err, svcerrors.ErrNotFound) {
+				return a, err
+			}
+		}
+		a, err := c.repo.GetArmyList(ctx, p.ID, "")
+		if errors.Is(err, svcerrors.ErrNotFound) {
+			return nil, nil
+		}
+		return a, err
+	}
+	return nil, nil
+}
+
+// participantAndLeague reads the participant with the given id along with their league
+func (c *Controller) participantAndLeague(ctx context.Context, id model.ParticipantID) (*model.Participant, *leaguesmodel.League, error) {
+	if id == "" {
+		return nil, nil, svcerrors.ErrInvalidID
+	}
+
+	p, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, err := c.leagues.GetByID(ctx, leagues.LeagueID(p.LeagueID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read league '%s' of participant '%s': %w", p.LeagueID, id, err)
+	}
+	return p, l, nil
+}
@@ -0,0 +1,281 @@
+package participants
+
+import (
+	"context"
+	"errors"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	leaguesmodel "github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/participants/internal/repository/memory"
+	"github.com/rpatton4/mesbg-league/participants/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	roundsmodel "github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"strings"
+	"testing"
+)
+
+// stubRounds is a roundSource holding the rounds of the leagues
+type stubRounds struct {
+	rounds []roundsmodel.Round
+}
+
+func (s *stubRounds) GetByID(_ context.Context, id rounds.RoundID) (*roundsmodel.Round, error) {
+	for i := range s.rounds {
+		if s.rounds[i].ID == id {
+			return &s.rounds[i], nil
+		}
+	}
+	return nil, svcerrors.ErrNotFound
+}
+
+func TestRegisterArmyListChecksLimits(t *testing.T) {
+	c, l, p := newArmyListController(t)
+
+	a, err := c.RegisterArmyList(context.Background(), p.ID, "", createFakeArmyList(8, 3))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Boromir at 100 plus 8 warriors at 9 points, 3 of them with bows
+	if a.ParticipantID != p.ID || a.TotalPoints != 172 || a.BowCount != 3 || a.WarriorCount != 8 || a.UpdatedAt.IsZero() {
+		t.Errorf("Expected the totals to be worked out for the participant, got %+v", a)
+	}
+
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "", createFakeArmyList(20, 3)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error for a list over the points limit, got %v", err)
+	}
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "", createFakeArmyList(8, 4)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error for a list over the bow limit, got %v", err)
+	}
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "", &model.ArmyList{Army: "Minas Tirith"}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error for a list without warbands, got %v", err)
+	}
+
+	// Without limits the same lists are fine
+	l.ArmyLists = nil
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "", createFakeArmyList(20, 10)); err != nil {
+		t.Errorf("Expected no error without limits, got %v", err)
+	}
+}
+
+func TestRegisterArmyListChecksAlliance(t *testing.T) {
+	c, l, p := newArmyListController(t)
+	l.ArmyLists.Alliances = leaguesmodel.AlliancesNoImpossible
+	allied := func(army string) *model.ArmyList {
+		a := createFakeArmyList(3, 1)
+		a.Warbands = append(a.Warbands, model.Warband{Army: army, Hero: model.HeroProfile{Name: "Ally", Points: 50}})
+		return a
+	}
+
+	a, err := c.RegisterArmyList(context.Background(), p.ID, "", allied("Rivendell"))
+	if err != nil {
+		t.Fatalf("Expected a Convenient alliance to be allowed, got %v", err)
+	}
+	if a.Alliance != "convenient" {
+		t.Errorf("Expected the list to be classified as a convenient alliance, got '%s'", a.Alliance)
+	}
+
+	_, err = c.RegisterArmyList(context.Background(), p.ID, "", allied("Mordor"))
+	if !errors.Is(err, svcerrors.ErrModelInvalid) || !strings.Contains(err.Error(), "Minas Tirith and Mordor are impossible allies") {
+		t.Errorf("Expected ErrModelInvalid naming the Impossible pairing, got %v", err)
+	}
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "", allied("Not An Army")); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for an army not in the alliance matrix, got %v", err)
+	}
+
+	l.ArmyLists.Alliances = leaguesmodel.AlliancesHistoricalOnly
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "", allied("Rivendell")); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for a Convenient alliance when only Historical ones are allowed, got %v", err)
+	}
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "", allied("Rohan")); err != nil {
+		t.Errorf("Expected a Historical alliance to be allowed, got %v", err)
+	}
+
+	l.ArmyLists.Alliances = ""
+	if a, err = c.RegisterArmyList(context.Background(), p.ID, "", allied("Not An Army")); err != nil || a.Alliance != "" {
+		t.Errorf("Expected any list to be allowed, unclassified, without an alliance policy, got %+v and %v", a, err)
+	}
+}
+
+func TestArmyListLocksWhenLeagueStarts(t *testing.T) {
+	c, l, p := newArmyListController(t)
+	if _, err := c.RegisterArmyList(context.Background(), p.ID, "", createFakeArmyList(8, 3)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	l.StartDate = "2020-01-01"
+	if _, err := c.RegisterArmyList(context.Background(), p.ID, "", createFakeArmyList(6, 2)); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected a conflict changing a list once the league has started, got %v", err)
+	}
+
+	if _, err := c.UnlockArmyList(context.Background(), p.ID, "", ""); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error unlocking without an organizer, got %v", err)
+	}
+	unlocked, err := c.UnlockArmyList(context.Background(), p.ID, "", "organizer")
+	if err != nil || !unlocked.Unlocked || unlocked.UnlockedBy != "organizer" {
+		t.Fatalf("Expected the list to be unlocked by the organizer, got %+v, %v", unlocked, err)
+	}
+
+	// An unlock allows one change only
+	changed, err := c.RegisterArmyList(context.Background(), p.ID, "", createFakeArmyList(6, 2))
+	if err != nil {
+		t.Fatalf("Expected no error changing an unlocked list, got %v", err)
+	}
+	if changed.WarriorCount != 6 || changed.Unlocked {
+		t.Errorf("Expected the list changed and locked again, got %+v", changed)
+	}
+	if _, err := c.RegisterArmyList(context.Background(), p.ID, "", createFakeArmyList(8, 3)); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected a conflict changing the list again, got %v", err)
+	}
+}
+
+func TestArmyListsForGame(t *testing.T) {
+	c, _, p := newArmyListController(t)
+	other, err := c.Create(context.Background(), &model.Participant{PlayerID: "b", LeagueID: "1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "", createFakeArmyList(8, 3)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	lists, err := c.ArmyListsForGame(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lists.Side1 == nil || lists.Side1.ParticipantID != p.ID || lists.Side2 != nil {
+		t.Errorf("Expected only the list of side 1, got %+v", lists)
+	}
+
+	if _, err = c.RegisterArmyList(context.Background(), other.ID, "", createFakeArmyList(4, 1)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lists, _ = c.ArmyListsForGame(context.Background(), "1"); lists.Side2 == nil || lists.Side2.ParticipantID != other.ID {
+		t.Errorf("Expected the list of side 2 once registered, got %+v", lists)
+	}
+
+	if _, err = c.ArmyListsForGame(context.Background(), "999"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown game, got %v", err)
+	}
+}
+
+func TestEscalationArmyListsPerRound(t *testing.T) {
+	c, l, p := newArmyListController(t)
+	other, err := c.Create(context.Background(), &model.Participant{PlayerID: "b", LeagueID: "1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "1-1", createFakeArmyList(5, 1)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for a list for a round outside an escalation league, got %v", err)
+	}
+
+	l.Format = leaguesmodel.FormatEscalation
+	c.rounds.(*stubRounds).rounds[0].PointsLimit = 150
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "1-1", createFakeArmyList(8, 2)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for a list over the points limit of the round, got %v", err)
+	}
+	if _, err = c.RegisterArmyList(context.Background(), p.ID, "9-9", createFakeArmyList(5, 1)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for a round not in the league, got %v", err)
+	}
+	a, err := c.RegisterArmyList(context.Background(), p.ID, "1-1", createFakeArmyList(5, 1))
+	if err != nil {
+		t.Fatalf("Expected a list within the round limit to be registered, got %v", err)
+	}
+	if a.RoundID != "1-1" || a.TotalPoints != 145 {
+		t.Errorf("Expected the 145 point list for round 1-1, got %+v", a)
+	}
+	if _, err = c.GetArmyList(context.Background(), p.ID, ""); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected the list for the round to be kept apart from the list used in every round, got %v", err)
+	}
+
+	// The other side only has a list for every round, which fits the league but not the round
+	if _, err = c.RegisterArmyList(context.Background(), other.ID, "", createFakeArmyList(8, 2)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lists, err := c.ArmyListsForGame(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lists.Side1 == nil || lists.Side1.RoundID != "1-1" || lists.Side2 == nil || lists.Side2.RoundID != "" {
+		t.Fatalf("Expected the round list of side 1 and the list for every round of side 2, got %+v", lists)
+	}
+	if lists.PointsLimit != 150 || len(lists.OverLimit) != 1 || !strings.Contains(lists.OverLimit[0], "side 2 costs 172 points") {
+		t.Errorf("Expected only side 2 to be reported over the round limit of 150, got %+v", lists)
+	}
+}
+
+func TestImportAndExportArmyList(t *testing.T) {
+	c, _, p := newArmyListController(t)
+	valid := `Minas Tirith
+Boromir, Captain of the White Tower [6/3/3] - 100 pts
+  6x Warrior of Minas Tirith - 54 pts: shield`
+	text := valid + "\n  not a list line"
+
+	// Anything which cannot be read stops the list being registered
+	imported, err := c.ImportArmyList(context.Background(), p.ID, "", FormatText, []byte(text), false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if imported.Registered || len(imported.Unparsed) != 1 || imported.Unparsed[0].Line != 4 {
+		t.Errorf("Expected line 4 to be reported and the list not registered, got %+v", imported)
+	}
+	if _, err = c.GetArmyList(context.Background(), p.ID, ""); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected no list to be registered, got %v", err)
+	}
+
+	imported, err = c.ImportArmyList(context.Background(), p.ID, "", FormatText, []byte(valid), false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !imported.Registered || imported.ArmyList.TotalPoints != 154 {
+		t.Errorf("Expected the list to be registered, got %+v", imported)
+	}
+
+	exported, err := c.ExportArmyList(context.Background(), p.ID, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	again, err := c.ImportArmyList(context.Background(), p.ID, "", FormatText, []byte(exported), true)
+	if err != nil || len(again.Unparsed) != 0 || again.Registered || again.ArmyList.TotalPoints != 154 {
+		t.Errorf("Expected the export to read back as the same list without registering it, got %+v, %v", again, err)
+	}
+
+	if _, err = c.ImportArmyList(context.Background(), p.ID, "", "xml", []byte(text), false); !errors.Is(err, svcerrors.ErrInvalidQuery) {
+		t.Errorf("Expected invalid query error for an unknown format, got %v", err)
+	}
+	if _, err = c.ImportArmyList(context.Background(), p.ID, "", FormatJSON, []byte(`{"army": [}`), false); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error for broken JSON, got %v", err)
+	}
+}
+
+// newArmyListController creates a controller over a league which has not started, with a 200 point and one third
+// bow limit, returning it along with the league and a participant for player a
+func newArmyListController(t *testing.T) (*Controller, *leaguesmodel.League, *model.Participant) {
+	l := &leaguesmodel.League{ID: "1", StartDate: "2999-01-01",
+		ArmyLists: &leaguesmodel.ArmyListRules{PointsLimit: 200, BowLimitPercent: 33}}
+	gs := &stubGames{games: []gamesmodel.Game{{ID: "1", RoundID: "1-1", Side1ID: "a", Side2ID: "b"}}}
+	rs := &stubRounds{rounds: []roundsmodel.Round{{ID: "1-1", LeagueID: "1", Number: 1}, {ID: "9-9", LeagueID: "9", Number: 1}}}
+	c := New(memory.New(), &stubLeagues{league: l}, gs, rs, nil)
+
+	p, err := c.Create(context.Background(), &model.Participant{PlayerID: "a", LeagueID: "1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return c, l, p
+}
+
+// createFakeArmyList creates a list of Boromir leading the given number of 9 point warriors, with bows on some of
+// them
+func createFakeArmyList(warriors, bows int) *model.ArmyList {
+	return &model.ArmyList{
+		Army: "Minas Tirith",
+		Warbands: []model.Warband{{
+			Hero: model.HeroProfile{Name: "Boromir, Captain of the White Tower", Points: 100, Might: 6, Will: 3, Fate: 3},
+			Units: []model.UnitProfile{
+				{Name: "Warrior of Minas Tirith", Count: warriors - bows, PointsEach: 9, Options: []string{"shield"}},
+				{Name: "Warrior of Minas Tirith", Count: bows, PointsEach: 9, Bow: true},
+			},
+		}},
+		TotalPoints: 1,
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"github.com/rpatton4/mesbg-league/participants/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"log/slog"
 )
 
 type participantRepository interface {
@@ -11,16 +14,29 @@ type participantRepository interface {
 	Create(ctx context.Context, p *model.Participant) (*model.Participant, error)
 	Replace(ctx context.Context, p *model.Participant) (*model.Participant, error)
 	DeleteByID(ctx context.Context, id model.ParticipantID) bool
+	ListByLeague(ctx context.Context, leagueID string) ([]*model.Participant, error)
+	ListByPlayer(ctx context.Context, playerID string) ([]*model.Participant, error)
+	GetArmyList(ctx context.Context, id model.ParticipantID, roundID string) (*model.ArmyList, error)
+	ReplaceArmyList(ctx context.Context, a *model.ArmyList) (*model.ArmyList, error)
 }
 
 // Controller defines the simple controller for participant operations.
 type Controller struct {
-	repo participantRepository
+	repo      participantRepository
+	leagues   leagueSource
+	games     gamesSource
+	rounds    roundSource
+	publisher events.Publisher
 }
 
-// New creates a new instance of the participant controller.
-func New(r participantRepository) *Controller {
-	return &Controller{repo: r}
+// New creates a new instance of the participant controller. The leagues and games are read to recompute the totals
+// of participants from the results of their games, and the leagues and rounds to check army lists against their
+// limits. A ParticipantJoined event is published for each participant created, nil publishes nothing.
+func New(r participantRepository, leagues leagueSource, games gamesSource, rounds roundSource, publisher events.Publisher) *Controller {
+	if publisher == nil {
+		publisher = events.Discard
+	}
+	return &Controller{repo: r, leagues: leagues, games: games, rounds: rounds, publisher: publisher}
 }
 
 // GetByID returns the participant with the given id, or svcerrors.NotFound if no participant with that id exists
@@ -30,20 +46,39 @@ func (c *Controller) GetByID(ctx context.Context, id model.ParticipantID) (*mode
 
 // Create persists a new participant instance to the repository and returns the participant with an assigned ID.
 // A generic error is returned if the participant to created is missing, while specific validation errors are
-// passed along from the repository if the participant is invalid in some way.
+// passed along from the repository if the participant is invalid in some way. The totals of a new participant
+// always start at zero, whatever was sent. Once it is stored a ParticipantJoined event is published, a failure to
+// publish it is logged rather than failing the creation.
 func (c *Controller) Create(ctx context.Context, p *model.Participant) (*model.Participant, error) {
 	if p == nil {
 		return nil, errors.New("the participant to be created cannot be nil")
 	}
-	return c.repo.Create(ctx, p)
+	p.ClearTotals()
+	created, err := c.repo.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	joined := events.ParticipantJoined{ParticipantID: string(created.ID), PlayerID: players.PlayerID(created.PlayerID), LeagueID: created.LeagueID}
+	if err := c.publisher.Publish(ctx, joined); err != nil {
+		slog.Warn("Unable to publish that a participant joined", "participantID", created.ID, "error", err.Error())
+	}
+	return created, nil
 }
 
 // Replace updates an existing participant in the repository with the provided participant.
-// A generic error is returned if the participant to replaced is not present in the data store.
+// A generic error is returned if the participant to replaced is not present in the data store. The totals are
+// kept as they were, they can only be changed by recomputing them.
 func (c *Controller) Replace(ctx context.Context, p *model.Participant) (*model.Participant, error) {
 	if p == nil {
 		return nil, errors.New("the participant to be created cannot be nil")
 	}
+
+	existing, err := c.repo.GetByID(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	p.CopyTotals(existing)
 	return c.repo.Replace(ctx, p)
 }
 
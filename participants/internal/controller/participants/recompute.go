@@ -0,0 +1,157 @@
+package participants
+
+import (
+	"context"
+	"fmt"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	leaguesmodel "github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/participants/pkg/model"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"log/slog"
+)
+
+// leagueSource is the part of the leagues gateway needed to find the rounds of a participant's league
+type leagueSource interface {
+	GetByID(ctx context.Context, id leagues.LeagueID) (*leaguesmodel.League, error)
+}
+
+// gamesSource is the part of the games gateway needed to read the games of those rounds, and to find the sides of a
+// game when showing their army lists
+type gamesSource interface {
+	GetByID(ctx context.Context, id games.GameID) (*gamesmodel.Game, error)
+	List(ctx context.Context, q gamesmodel.GameQuery) (*gamesmodel.GameList, error)
+}
+
+// Recompute rebuilds the totals of the participant with the given id from all the games they have played in their
+// league which count towards it, the same games as its standings, storing and returning the updated participant.
+// svcerrors.ErrNotFound is returned if there is no such participant.
+func (c *Controller) Recompute(ctx context.Context, id model.ParticipantID) (*model.Participant, error) {
+	p, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	gs, err := c.completedGames(ctx, p.LeagueID, players.PlayerID(p.PlayerID))
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *p
+	updated.CopyTotals(totalsFor(p.PlayerID, gs))
+	return c.repo.Replace(ctx, &updated)
+}
+
+// RecomputeLeague rebuilds the totals of every participant in the league with the given id, reading the games of
+// the league once for all of them. The updated participants are returned, svcerrors.ErrNotFound is returned if
+// there is no such league.
+func (c *Controller) RecomputeLeague(ctx context.Context, leagueID string) ([]*model.Participant, error) {
+	gs, err := c.completedGames(ctx, leagueID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ps, err := c.repo.ListByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make([]*model.Participant, 0, len(ps))
+	for _, p := range ps {
+		u := *p
+		u.CopyTotals(totalsFor(p.PlayerID, gs))
+		r, err := c.repo.Replace(ctx, &u)
+		if err != nil {
+			return updated, fmt.Errorf("unable to store the totals of participant '%s': %w", p.ID, err)
+		}
+		updated = append(updated, r)
+	}
+
+	slog.Info("Recomputed the participant totals of a league", "leagueID", leagueID, "participants", len(updated), "games", len(gs))
+	return updated, nil
+}
+
+// completedGames reads the games counting towards the league from every round of it, limited to the games of one side when
+// sideID is not empty
+func (c *Controller) completedGames(ctx context.Context, leagueID string, sideID players.PlayerID) ([]gamesmodel.Game, error) {
+	l, err := c.leagues.GetByID(ctx, leagues.LeagueID(leagueID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read league '%s': %w", leagueID, err)
+	}
+
+	gs := []gamesmodel.Game{}
+	for _, roundID := range l.RoundIDs {
+		if roundID == "" {
+			continue
+		}
+
+		q := gamesmodel.GameQuery{RoundID: roundID, SideID: sideID, Statuses: games.CountedGameStates(), Limit: gamesmodel.MaxListLimit}
+		for {
+			page, err := c.games.List(ctx, q)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the games for round '%s' of league '%s': %w", roundID, leagueID, err)
+			}
+			gs = append(gs, page.Games...)
+			if page.NextCursor == "" {
+				break
+			}
+			q.Cursor = page.NextCursor
+		}
+	}
+	return gs, nil
+}
+
+// totalsFor adds up the totals of the player from the given games, games the player was not a side of are skipped
+// so the same games can be used for every participant of a league
+func totalsFor(playerID string, gs []gamesmodel.Game) *model.Participant {
+	t := &model.Participant{}
+	id := players.PlayerID(playerID)
+	for i := range gs {
+		g := &gs[i]
+		switch id {
+		case g.Side1ID:
+			t.VictoryPointsScored += g.Side1TotalVictoryPoints
+			t.VictoryPointsConceded += g.Side2TotalVictoryPoints
+			if g.Side1KilledGeneral {
+				t.GeneralsKilled++
+			}
+			addBreakdown(t, g.Side1Breakdown)
+		case g.Side2ID:
+			t.VictoryPointsScored += g.Side2TotalVictoryPoints
+			t.VictoryPointsConceded += g.Side1TotalVictoryPoints
+			if g.Side2KilledGeneral {
+				t.GeneralsKilled++
+			}
+			addBreakdown(t, g.Side2Breakdown)
+		default:
+			continue
+		}
+		t.GamesCounted++
+	}
+	return t
+}
+
+// addBreakdown adds the breakdown of the participant's side of a game to their totals, a game without one is
+// left out of the breakdown totals
+func addBreakdown(t *model.Participant, b *gamesmodel.SideBreakdown) {
+	if b == nil {
+		return
+	}
+
+	for category, vp := range b.VictoryPoints {
+		if t.VictoryPointsByCategory == nil {
+			t.VictoryPointsByCategory = map[string]int{}
+		}
+		t.VictoryPointsByCategory[category] += vp
+	}
+	t.HeroesSlain += len(b.HeroesSlain)
+	t.ModelsRemoved += b.ModelsRemoved
+	if b.Broken {
+		t.TimesBroken++
+	}
+	if b.Quartered {
+		t.TimesQuartered++
+	}
+	t.BreakdownsCounted++
+}
@@ -0,0 +1,233 @@
+package participants
+
+import (
+	"context"
+	"errors"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	leaguesmodel "github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/participants/internal/repository/memory"
+	"github.com/rpatton4/mesbg-league/participants/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"maps"
+	"slices"
+	"testing"
+)
+
+// stubLeagues is a leagueSource holding a single league
+type stubLeagues struct {
+	league *leaguesmodel.League
+}
+
+func (s *stubLeagues) GetByID(_ context.Context, id leagues.LeagueID) (*leaguesmodel.League, error) {
+	if s.league == nil || s.league.ID != id {
+		return nil, svcerrors.ErrNotFound
+	}
+	return s.league, nil
+}
+
+// stubGames is a gamesSource which filters its games like the games service does, one game per page so the
+// cursor is followed
+type stubGames struct {
+	games []gamesmodel.Game
+}
+
+func (s *stubGames) GetByID(_ context.Context, id games.GameID) (*gamesmodel.Game, error) {
+	for i := range s.games {
+		if s.games[i].ID == id {
+			return &s.games[i], nil
+		}
+	}
+	return nil, svcerrors.ErrNotFound
+}
+
+func (s *stubGames) List(_ context.Context, q gamesmodel.GameQuery) (*gamesmodel.GameList, error) {
+	l := &gamesmodel.GameList{Games: []gamesmodel.Game{}}
+	for i, g := range s.games {
+		if (q.Cursor != "" && string(g.ID) <= q.Cursor) || !q.Matches(&s.games[i]) {
+			continue
+		}
+		if len(l.Games) == 1 {
+			l.NextCursor = string(l.Games[0].ID)
+			break
+		}
+		l.Games = append(l.Games, g)
+	}
+	return l, nil
+}
+
+func TestRecomputeParticipant(t *testing.T) {
+	c, p := newTestController(t)
+
+	updated, err := c.Recompute(context.Background(), p.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Games 1 and 2 are completed, game 3 is not finished and game 4 is in another league
+	if updated.VictoryPointsScored != 14 || updated.VictoryPointsConceded != 9 || updated.GeneralsKilled != 1 || updated.GamesCounted != 2 {
+		t.Errorf("Expected 14 scored, 9 conceded, 1 general from 2 games, got %+v", updated)
+	}
+
+	stored, _ := c.GetByID(context.Background(), p.ID)
+	if stored.VictoryPointsScored != 14 {
+		t.Errorf("Expected the recomputed totals to be stored, got %+v", stored)
+	}
+
+	if _, err = c.Recompute(context.Background(), "9999"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown participant, got %v", err)
+	}
+}
+
+func TestRecomputeLeague(t *testing.T) {
+	c, _ := newTestController(t)
+	if _, err := c.Create(context.Background(), &model.Participant{PlayerID: "b", LeagueID: "1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ps, err := c.RecomputeLeague(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ps) != 2 {
+		t.Fatalf("Expected both participants of the league, got %d", len(ps))
+	}
+	i := slices.IndexFunc(ps, func(p *model.Participant) bool { return p.PlayerID == "b" })
+	// Game 5 was conceded to player b so it counts, as it does for the standings, game 6 was cancelled
+	if i < 0 || ps[i].VictoryPointsScored != 7 || ps[i].VictoryPointsConceded != 8 || ps[i].GamesCounted != 2 {
+		t.Errorf("Expected player b to have 7 scored and 8 conceded from 2 games, got %+v", ps[i])
+	}
+
+	if _, err = c.RecomputeLeague(context.Background(), "2"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown league, got %v", err)
+	}
+}
+
+func TestTotalsIncludeBreakdowns(t *testing.T) {
+	gs := []gamesmodel.Game{
+		{ID: "1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 9, Side2TotalVictoryPoints: 3,
+			Side1Breakdown: &gamesmodel.SideBreakdown{VictoryPoints: map[string]int{"objectives": 6, "heroes": 3}, HeroesSlain: []string{"Gothmog"}, ModelsRemoved: 12},
+			Side2Breakdown: &gamesmodel.SideBreakdown{VictoryPoints: map[string]int{"objectives": 3}, ModelsRemoved: 4, Broken: true, Quartered: true}},
+		{ID: "2", Side1ID: "b", Side2ID: "a", Side1TotalVictoryPoints: 5, Side2TotalVictoryPoints: 7,
+			Side2Breakdown: &gamesmodel.SideBreakdown{VictoryPoints: map[string]int{"objectives": 4, "breaking": 3}, HeroesSlain: []string{"Shagrat", "Gorbag"}, ModelsRemoved: 9, Broken: true}},
+		{ID: "3", Side1ID: "a", Side2ID: "c", Side1TotalVictoryPoints: 2, Side2TotalVictoryPoints: 10},
+	}
+
+	a := totalsFor("a", gs)
+	if a.GamesCounted != 3 || a.BreakdownsCounted != 2 || a.VictoryPointsScored != 18 {
+		t.Errorf("Expected 3 games of which 2 had a breakdown, got %+v", a)
+	}
+	if a.HeroesSlain != 3 || a.ModelsRemoved != 21 || a.TimesBroken != 1 || a.TimesQuartered != 0 {
+		t.Errorf("Expected 3 heroes slain, 21 models removed and broken once, got %+v", a)
+	}
+	want := map[string]int{"objectives": 10, "heroes": 3, "breaking": 3}
+	if !maps.Equal(a.VictoryPointsByCategory, want) {
+		t.Errorf("Expected victory points by category of %v, got %v", want, a.VictoryPointsByCategory)
+	}
+
+	b := totalsFor("b", gs)
+	if b.BreakdownsCounted != 1 || b.TimesBroken != 1 || b.TimesQuartered != 1 || b.ModelsRemoved != 4 {
+		t.Errorf("Expected the one breakdown of player b to be counted, got %+v", b)
+	}
+}
+
+func TestGameCompletedEventRecomputesSides(t *testing.T) {
+	c, p := newTestController(t)
+	bus := events.NewInProcessBus()
+	c.Subscribe(bus)
+
+	if err := bus.Publish(context.Background(), events.GameCompleted{Game: gamesmodel.Game{ID: "1", Side1ID: "a", Side2ID: "b"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Wait()
+
+	if stored, _ := c.GetByID(context.Background(), p.ID); stored.VictoryPointsScored != 14 || stored.GamesCounted != 2 {
+		t.Errorf("Expected the totals of the side to be recomputed, got %+v", stored)
+	}
+	if dls, _ := bus.DeadLetters().List(context.Background()); len(dls) != 0 {
+		t.Errorf("Expected the event to be handled, got dead letters %+v", dls)
+	}
+}
+
+func TestGameDeletedEventRecomputesSides(t *testing.T) {
+	c, p := newTestController(t)
+	bus := events.NewInProcessBus()
+	c.Subscribe(bus)
+	if _, err := c.Recompute(context.Background(), p.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The games service no longer lists a deleted game
+	gs := c.games.(*stubGames)
+	gs.games = slices.DeleteFunc(gs.games, func(g gamesmodel.Game) bool { return g.ID == "1" })
+	if err := bus.Publish(context.Background(), events.GameDeleted{GameID: "1", RoundID: "1-1", Side1ID: "a", Side2ID: "b"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Wait()
+
+	if stored, _ := c.GetByID(context.Background(), p.ID); stored.VictoryPointsScored != 6 || stored.GamesCounted != 1 {
+		t.Errorf("Expected the deleted game to be taken out of the totals of the side, got %+v", stored)
+	}
+}
+
+func TestCreatePublishesParticipantJoined(t *testing.T) {
+	bus := events.NewInProcessBus()
+	var joined []events.ParticipantJoined
+	events.On(bus, "test", func(_ context.Context, e events.ParticipantJoined) error {
+		joined = append(joined, e)
+		return nil
+	})
+
+	c := New(memory.New(), &stubLeagues{}, &stubGames{}, &stubRounds{}, bus)
+	p, err := c.Create(context.Background(), &model.Participant{PlayerID: "a", LeagueID: "1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Wait()
+
+	if len(joined) != 1 || joined[0].ParticipantID != string(p.ID) || joined[0].PlayerID != "a" || joined[0].LeagueID != "1" {
+		t.Errorf("Expected one ParticipantJoined event for participant %s, got %+v", p.ID, joined)
+	}
+}
+
+func TestTotalsAreReadOnly(t *testing.T) {
+	c, p := newTestController(t)
+	if p.VictoryPointsScored != 0 {
+		t.Errorf("Expected the totals sent on create to be ignored, got %+v", p)
+	}
+
+	if _, err := c.Recompute(context.Background(), p.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	edited := &model.Participant{ID: p.ID, PlayerID: "a", LeagueID: "1", VictoryPointsScored: 100, GeneralsKilled: 50}
+	replaced, err := c.Replace(context.Background(), edited)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if replaced.VictoryPointsScored != 14 || replaced.GeneralsKilled != 1 {
+		t.Errorf("Expected the totals to be kept on replace, got %+v", replaced)
+	}
+}
+
+// newTestController creates a controller over a league with two rounds and some games, returning it along with a
+// participant for player a, created with totals which should be ignored
+func newTestController(t *testing.T) (*Controller, *model.Participant) {
+	l := &stubLeagues{league: &leaguesmodel.League{ID: "1", RoundIDs: []rounds.RoundID{"1-1", "1-2"}}}
+	gs := &stubGames{games: []gamesmodel.Game{
+		{ID: "1", RoundID: "1-1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 8, Side2TotalVictoryPoints: 3, Side1KilledGeneral: true, Status: games.GameStatePlayCompleted},
+		{ID: "2", RoundID: "1-2", Side1ID: "c", Side2ID: "a", Side1TotalVictoryPoints: 6, Side2TotalVictoryPoints: 6, Side1KilledGeneral: true, Status: games.GameStatePlayCompleted},
+		{ID: "3", RoundID: "1-2", Side1ID: "a", Side2ID: "d", Side1TotalVictoryPoints: 5, Status: games.GameStateInProgress},
+		{ID: "4", RoundID: "2-1", Side1ID: "a", Side2ID: "b", Side1TotalVictoryPoints: 10, Status: games.GameStatePlayCompleted},
+		{ID: "5", RoundID: "1-1", Side1ID: "b", Side2ID: "d", Side1TotalVictoryPoints: 4, Status: games.GameStateConceded, ConcedingSideID: "d"},
+		{ID: "6", RoundID: "1-2", Side1ID: "b", Side2ID: "c", Side1TotalVictoryPoints: 7, Status: games.GameStateCancelled},
+	}}
+	c := New(memory.New(), l, gs, &stubRounds{}, nil)
+
+	p, err := c.Create(context.Background(), &model.Participant{PlayerID: "a", LeagueID: "1", VictoryPointsScored: 99})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return c, p
+}
@@ -0,0 +1,59 @@
+package participants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/pkg/events"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	"log/slog"
+)
+
+// Subscribe registers the reactions of the participants to events published by other services. The totals of the
+// sides of a game are recomputed whenever a change to the game may have changed them: when it is completed,
+// updated, deleted or restored.
+func (c *Controller) Subscribe(s events.Subscriber) {
+	events.On(s, "participants.recompute-sides", func(ctx context.Context, e events.GameCompleted) error {
+		return c.RecomputeSides(ctx, e.Game.ID, e.Game.Side1ID, e.Game.Side2ID)
+	})
+	events.On(s, "participants.recompute-sides", func(ctx context.Context, e events.GameUpdated) error {
+		// A side replaced by the update loses the game from its totals, so it is recomputed as well
+		return c.RecomputeSides(ctx, e.Game.ID, append([]players.PlayerID{e.Game.Side1ID, e.Game.Side2ID}, e.ReplacedSides...)...)
+	})
+	events.On(s, "participants.recompute-sides", func(ctx context.Context, e events.GameDeleted) error {
+		return c.RecomputeSides(ctx, e.GameID, e.Side1ID, e.Side2ID)
+	})
+	events.On(s, "participants.recompute-sides", func(ctx context.Context, e events.GameRestored) error {
+		return c.RecomputeSides(ctx, e.Game.ID, e.Game.Side1ID, e.Game.Side2ID)
+	})
+}
+
+// RecomputeSides recomputes the totals of every participant played by the given sides of the game. The league of
+// the game is not known from the game itself, so a player in several leagues has each of their participants
+// recomputed. Recomputing is idempotent, so a repeated delivery of the same event does no harm.
+func (c *Controller) RecomputeSides(ctx context.Context, gameID games.GameID, sides ...players.PlayerID) error {
+	var errs []error
+	for _, playerID := range sides {
+		if playerID == "" {
+			continue
+		}
+
+		ps, err := c.repo.ListByPlayer(ctx, string(playerID))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to list the participants of player '%s': %w", playerID, err))
+			continue
+		}
+		for _, p := range ps {
+			if _, err := c.Recompute(ctx, p.ID); err != nil {
+				errs = append(errs, fmt.Errorf("unable to recompute participant '%s': %w", p.ID, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	slog.Debug("Recomputed the participants of a changed game", "gameID", gameID)
+	return nil
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/participants/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/auth"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// GetArmyList responds with the army list of the participant with the ID from the path. Every army list endpoint
+// takes an optional roundId query parameter for the list of a single round in an escalation league, without it
+// the list used in every round is meant.
+func (h *Handler) GetArmyList(w http.ResponseWriter, r *http.Request) {
+	id, roundID := model.ParticipantID(r.PathValue("id")), r.URL.Query().Get("roundId")
+	slog.Debug("GetArmyList called", "participantID", id, "roundID", roundID)
+
+	a, err := h.ctrl.GetArmyList(r.Context(), id, roundID)
+	if err != nil {
+		armyListError(w, "get", id, err)
+		return
+	}
+	writeJSON(w, a)
+}
+
+// PutArmyList registers the army list in the body for the participant with the ID from the path, responding with
+// the list and its totals. 400 is returned if the list is invalid or breaks the limits of the league or round, and
+// 409 if the list is locked because the league has started.
+func (h *Handler) PutArmyList(w http.ResponseWriter, r *http.Request) {
+	id, roundID := model.ParticipantID(r.PathValue("id")), r.URL.Query().Get("roundId")
+	slog.Debug("PutArmyList called", "participantID", id, "roundID", roundID)
+
+	var a model.ArmyList
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		slog.Error("Failed to decode army list JSON", "error", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	registered, err := h.ctrl.RegisterArmyList(r.Context(), id, roundID, &a)
+	if err != nil {
+		armyListError(w, "register", id, err)
+		return
+	}
+	writeJSON(w, registered)
+}
+
+// UnlockArmyList unlocks the army list of the participant with the ID from the path for one more change. The
+// organizer is the actor from the auth.ActorHeader of the request, 400 is returned without one. The route is meant
+// to be wrapped in auth.RequireAdminHandler, so only organizers get this far.
+func (h *Handler) UnlockArmyList(w http.ResponseWriter, r *http.Request) {
+	id, roundID := model.ParticipantID(r.PathValue("id")), r.URL.Query().Get("roundId")
+	organizer := auth.Actor(r.Context())
+	slog.Debug("UnlockArmyList called", "participantID", id, "roundID", roundID, "unlockedBy", organizer)
+
+	a, err := h.ctrl.UnlockArmyList(r.Context(), id, roundID, organizer)
+	if err != nil {
+		armyListError(w, "unlock", id, err)
+		return
+	}
+	writeJSON(w, a)
+}
+
+// ImportArmyList reads an army list exported by an army builder from the body and registers it for the participant
+// with the ID from the path. The format query parameter is "text" (the default) or "json", and dryRun=true only
+// reads the list without registering it. The response holds the list along with anything which could not be read,
+// with a 400 if that stopped the list being registered.
+func (h *Handler) ImportArmyList(w http.ResponseWriter, r *http.Request) {
+	id, roundID := model.ParticipantID(r.PathValue("id")), r.URL.Query().Get("roundId")
+	format, dryRun := r.URL.Query().Get("format"), r.URL.Query().Get("dryRun") == "true"
+	slog.Debug("ImportArmyList called", "participantID", id, "roundID", roundID, "format", format, "dryRun", dryRun)
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Error("Failed to read request body", "error", err)
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	imported, err := h.ctrl.ImportArmyList(r.Context(), id, roundID, format, data, dryRun)
+	if err != nil {
+		armyListError(w, "import", id, err)
+		return
+	}
+	if !imported.Registered && !dryRun {
+		slog.Warn("Army list import has lines which could not be read", "participantID", id, "unparsed", len(imported.Unparsed))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	writeJSON(w, imported)
+}
+
+// ExportArmyList responds with the army list of the participant with the ID from the path in the plain text format
+func (h *Handler) ExportArmyList(w http.ResponseWriter, r *http.Request) {
+	id, roundID := model.ParticipantID(r.PathValue("id")), r.URL.Query().Get("roundId")
+	slog.Debug("ExportArmyList called", "participantID", id, "roundID", roundID)
+
+	text, err := h.ctrl.ExportArmyList(r.Context(), id, roundID)
+	if err != nil {
+		armyListError(w, "export", id, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := io.WriteString(w, text); err != nil {
+		slog.Error("Failed to write army list export", "error", err)
+	}
+}
+
+// GameArmyLists responds with the army lists of both sides of the game with the ID from the path, reporting any
+// list which does not fit the points limit of the game's round
+func (h *Handler) GameArmyLists(w http.ResponseWriter, r *http.Request) {
+	id := games.GameID(r.PathValue("gameId"))
+	slog.Debug("GameArmyLists called", "gameID", id)
+
+	lists, err := h.ctrl.ArmyListsForGame(r.Context(), id)
+	if err != nil && (errors.Is(err, svcerrors.ErrNotFound) || errors.Is(err, svcerrors.ErrInvalidID)) {
+		slog.Warn("Game not found while reading its army lists", "gameID", id, "error", err)
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.Error("Error reading the army lists of a game", "gameID", id, "error", err)
+		http.Error(w, "Error reading the army lists of the game", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, lists)
+}
+
+// armyListError maps an error from the controller to the HTTP response, so that every army list operation reports
+// errors with the same status codes
+func armyListError(w http.ResponseWriter, action string, id model.ParticipantID, err error) {
+	switch {
+	case errors.Is(err, svcerrors.ErrNotFound) || errors.Is(err, svcerrors.ErrInvalidID):
+		slog.Warn("Participant or army list not found", "action", action, "participantID", id, "error", err)
+		http.Error(w, "Participant or army list not found", http.StatusNotFound)
+	case errors.Is(err, svcerrors.ErrModelMissing) || errors.Is(err, svcerrors.ErrModelInvalid) || errors.Is(err, svcerrors.ErrInvalidQuery):
+		slog.Warn("Invalid army list request", "action", action, "participantID", id, "error", err)
+		http.Error(w, "Unable to "+action+" the army list: "+err.Error(), http.StatusBadRequest)
+	case errors.Is(err, svcerrors.ErrConflict):
+		slog.Warn("Army list is locked", "action", action, "participantID", id, "error", err)
+		http.Error(w, "Unable to "+action+" the army list: "+err.Error(), http.StatusConflict)
+	default:
+		slog.Error("Error handling army list", "action", action, "participantID", id, "error", err)
+		http.Error(w, "Error trying to "+action+" the army list", http.StatusInternalServerError)
+	}
+}
@@ -157,3 +157,51 @@ func httpDeleteByID(h *Handler, w http.ResponseWriter, r *http.Request, id model
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Recompute rebuilds the totals of the participant with the ID from the path from the results of their games,
+// responding with the updated participant
+func (h *Handler) Recompute(w http.ResponseWriter, r *http.Request) {
+	id := model.ParticipantID(r.PathValue("id"))
+	slog.Debug("Recompute called", "participantID", id)
+
+	p, err := h.ctrl.Recompute(r.Context(), id)
+	if err != nil && errors.Is(err, svcerrors.ErrNotFound) {
+		slog.Warn("Participant or league not found while recomputing", "participantID", id, "error", err)
+		http.Error(w, "Participant or its league not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.Error("Error recomputing participant", "participantID", id, "error", err)
+		http.Error(w, "Error recomputing participant", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, p)
+}
+
+// RecomputeLeague rebuilds the totals of every participant in the league with the ID from the path, responding
+// with the updated participants
+func (h *Handler) RecomputeLeague(w http.ResponseWriter, r *http.Request) {
+	leagueID := r.PathValue("leagueId")
+	slog.Debug("RecomputeLeague called", "leagueID", leagueID)
+
+	ps, err := h.ctrl.RecomputeLeague(r.Context(), leagueID)
+	if err != nil && errors.Is(err, svcerrors.ErrNotFound) {
+		slog.Warn("League not found while recomputing", "leagueID", leagueID, "error", err)
+		http.Error(w, "League not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.Error("Error recomputing the participants of a league", "leagueID", leagueID, "error", err)
+		http.Error(w, "Error recomputing the participants of the league", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, ps)
+}
+
+// writeJSON sends the value as the JSON body of a 200 response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
@@ -1,24 +1,35 @@
 package memory
 
 import (
+	"cmp"
 	"context"
 	"github.com/rpatton4/mesbg-league/participants/pkg/model"
 	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"maps"
+	"slices"
 	"strconv"
 	"sync"
 )
 
 var participantCounter = 1
 
-// Repository defines an in-memory repository for participant data
+// Repository defines an in-memory repository for participant data, along with the army lists of each participant
 type Repository struct {
 	sync.RWMutex
-	data map[model.ParticipantID]*model.Participant
+	data  map[model.ParticipantID]*model.Participant
+	lists map[armyListKey]*model.ArmyList
+}
+
+// armyListKey identifies an army list by its participant and the round it is for, the round being empty for the
+// list used in every round
+type armyListKey struct {
+	participantID model.ParticipantID
+	roundID       string
 }
 
 // New creates a new instance of the in-memory participant repository.
 func New() *Repository {
-	return &Repository{data: map[model.ParticipantID]*model.Participant{}}
+	return &Repository{data: map[model.ParticipantID]*model.Participant{}, lists: map[armyListKey]*model.ArmyList{}}
 }
 
 // Get retrieves a participant by ID from the in-memory repository, if no participant with the given
@@ -42,7 +53,7 @@ func (r *Repository) Create(_ context.Context, p *model.Participant) (*model.Par
 	r.data[p.ID] = p
 	participantCounter++
 
-	return p, svcerrors.ErrNotFound
+	return p, nil
 }
 
 // Replace completely replaces an existing participant instance with the provided one, using the ID from the provided participant
@@ -67,9 +78,72 @@ func (r *Repository) DeleteByID(_ context.Context, id model.ParticipantID) bool
 	defer r.Unlock()
 
 	if r.data[id] != nil {
-		r.data[id] = nil
+		delete(r.data, id)
+		maps.DeleteFunc(r.lists, func(k armyListKey, _ *model.ArmyList) bool { return k.participantID == id })
 		return true
 	}
 
 	return false
 }
+
+// ListByLeague returns every participant in the league with the given ID, ordered by participant ID
+func (r *Repository) ListByLeague(_ context.Context, leagueID string) ([]*model.Participant, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	ps := []*model.Participant{}
+	for _, p := range r.data {
+		if p.LeagueID == leagueID {
+			ps = append(ps, p)
+		}
+	}
+	slices.SortFunc(ps, func(a, b *model.Participant) int {
+		return cmp.Or(cmp.Compare(len(a.ID), len(b.ID)), cmp.Compare(a.ID, b.ID))
+	})
+	return ps, nil
+}
+
+// ListByPlayer returns every participant played by the player with the given ID, one for each league they have
+// joined, ordered by participant ID
+func (r *Repository) ListByPlayer(_ context.Context, playerID string) ([]*model.Participant, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	ps := []*model.Participant{}
+	for _, p := range r.data {
+		if p.PlayerID == playerID {
+			ps = append(ps, p)
+		}
+	}
+	slices.SortFunc(ps, func(a, b *model.Participant) int {
+		return cmp.Or(cmp.Compare(len(a.ID), len(b.ID)), cmp.Compare(a.ID, b.ID))
+	})
+	return ps, nil
+}
+
+// GetArmyList retrieves the army list of the participant with the given ID for the given round, or the list used
+// in every round when the round is empty. If the participant has not registered such a list it returns ErrNotFound.
+func (r *Repository) GetArmyList(_ context.Context, id model.ParticipantID, roundID string) (*model.ArmyList, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	a, exists := r.lists[armyListKey{participantID: id, roundID: roundID}]
+	if !exists {
+		return nil, svcerrors.ErrNotFound
+	}
+	return a, nil
+}
+
+// ReplaceArmyList stores the army list of the participant and round it names, replacing any list they registered
+// before for that round.
+// The participant has to exist, otherwise ErrInvalidID is returned.
+func (r *Repository) ReplaceArmyList(_ context.Context, a *model.ArmyList) (*model.ArmyList, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if a.ParticipantID == "" || r.data[a.ParticipantID] == nil {
+		return nil, svcerrors.ErrInvalidID
+	}
+	r.lists[armyListKey{participantID: a.ParticipantID, roundID: a.RoundID}] = a
+	return a, nil
+}
@@ -0,0 +1,128 @@
+package armylist
+
+import (
+	"errors"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"slices"
+	"testing"
+)
+
+const minasTirith = `Minas Tirith - 172 pts
+
+Boromir, Captain of the White Tower [6/3/3] - 100 pts: horse
+  5x Warrior of Minas Tirith - 45 pts: shield
+  3x Warrior of Minas Tirith - 27 pts: bow
+
+Total: 172 pts | 3 bows | 8 warriors
+`
+
+func TestParseText(t *testing.T) {
+	a, unparsed := ParseText(minasTirith)
+	if len(unparsed) != 0 {
+		t.Fatalf("Expected every line to be read, got %+v", unparsed)
+	}
+	if a.Army != "Minas Tirith" || len(a.Warbands) != 1 || a.TotalPoints != 172 || a.BowCount != 3 || a.WarriorCount != 8 {
+		t.Errorf("Expected Minas Tirith with one warband, 172 points and 3 bows, got %+v", a)
+	}
+
+	h := a.Warbands[0].Hero
+	if h.Name != "Boromir, Captain of the White Tower" || h.Points != 100 || h.Might != 6 || h.Will != 3 || h.Fate != 3 || !slices.Equal(h.Options, []string{"horse"}) {
+		t.Errorf("Expected Boromir with 6/3/3 and a horse, got %+v", h)
+	}
+	if u := a.Warbands[0].Units[1]; u.Count != 3 || u.PointsEach != 9 || !u.Bow {
+		t.Errorf("Expected 3 warriors at 9 points with bows, got %+v", u)
+	}
+}
+
+func TestParseTextReportsUnparsedLines(t *testing.T) {
+	a, unparsed := ParseText(`Mordor
+  4x Orc Warrior - 32 pts
+Gothmog - 70 points
+  3x Orc Warrior - 25 pts: Orc bow
+  some notes about the list
+  6x Orc Warrior - 48 pts: shield, spear`)
+
+	lines := []int{}
+	for _, u := range unparsed {
+		lines = append(lines, u.Line)
+	}
+	if !slices.Equal(lines, []int{2, 4, 5}) {
+		t.Errorf("Expected lines 2, 4 and 5 to be reported, got %+v", unparsed)
+	}
+	if a.Army != "Mordor" || len(a.Warbands) != 1 || a.TotalPoints != 118 || a.WarriorCount != 6 {
+		t.Errorf("Expected the lines which could be read, got %+v", a)
+	}
+}
+
+func TestFormatTextRoundTrip(t *testing.T) {
+	a, _ := ParseText(minasTirith)
+	text := FormatText(a)
+	if text != minasTirith {
+		t.Errorf("Expected the list to be written back as it was read, got\n%s", text)
+	}
+
+	again, unparsed := ParseText(text)
+	if len(unparsed) != 0 || FormatText(again) != text {
+		t.Errorf("Expected the exported list to read back the same, got %+v and %+v", again, unparsed)
+	}
+}
+
+func TestTextAlliedWarbands(t *testing.T) {
+	text := `Minas Tirith - 270 pts
+
+Boromir, Captain of the White Tower [6/3/3] - 100 pts
+
+Ally: Rohan
+
+Théoden, King of Rohan [3/3/3] - 85 pts: horse
+
+Ally: Minas Tirith
+
+Faramir, Captain of Gondor [4/4/4] - 85 pts
+
+Total: 270 pts | 0 bows | 0 warriors
+`
+	a, unparsed := ParseText(text)
+	if len(unparsed) != 0 {
+		t.Fatalf("Expected every line to be read, got %+v", unparsed)
+	}
+	if len(a.Warbands) != 3 || a.Warbands[0].Army != "" || a.Warbands[1].Army != "Rohan" || a.Warbands[2].Army != "" {
+		t.Fatalf("Expected only the second warband to be allied, got %+v", a.Warbands)
+	}
+	if armies := a.Armies(); len(armies) != 2 || armies[1] != "Rohan" {
+		t.Errorf("Expected the list to be chosen from Minas Tirith and Rohan, got %v", armies)
+	}
+	if FormatText(a) != text {
+		t.Errorf("Expected the list to be written back as it was read, got\n%s", FormatText(a))
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	a, unparsed, err := ParseJSON([]byte(`{
+		"army": "Minas Tirith",
+		"totalPoints": 5,
+		"colour": "white",
+		"warbands": [{
+			"hero": {"name": "Boromir", "points": 100, "might": 6, "will": 3, "fate": 3, "banner": true},
+			"units": [{"name": "Warrior of Minas Tirith", "count": 3, "pointsEach": 9, "bow": true, "rank": 2}]
+		}]
+	}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.Army != "Minas Tirith" || a.TotalPoints != 127 || a.BowCount != 3 {
+		t.Errorf("Expected the list with its totals worked out, got %+v", a)
+	}
+
+	fields := []string{}
+	for _, u := range unparsed {
+		fields = append(fields, u.Text)
+	}
+	if !slices.Equal(fields, []string{"colour", "warbands[0].hero.banner", "warbands[0].units[0].rank"}) {
+		t.Errorf("Expected the unknown fields to be reported, got %+v", unparsed)
+	}
+
+	if _, _, err = ParseJSON([]byte(`{"army": 7}`)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error for a field of the wrong type, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Package armylist reads army lists from the formats exported by army builders, and writes them back out, so that
+// players can register the lists they have already built. Two formats are understood.
+//
+// # Text format
+//
+// The plain text format is the block of text army builders produce for sharing a list. The first line holds the
+// name of the army, optionally followed by its points. Each warband starts with a line for its hero, followed by a
+// line for each group of warriors in it:
+//
+//	Minas Tirith - 172 pts
+//
+//	Boromir, Captain of the White Tower [6/3/3] - 100 pts: horse
+//	  5x Warrior of Minas Tirith - 45 pts: shield
+//	  3x Warrior of Minas Tirith - 27 pts: bow
+//
+//	Total: 172 pts | 3 bows | 8 warriors
+//
+// Warbands chosen from an allied army follow a line naming the army, and the warbands after an "Ally:" line naming
+// the army of the list are chosen from it again:
+//
+//	Ally: Rohan
+//
+//	Théoden, King of Rohan [3/3/3] - 85 pts: horse
+//
+// A hero line is the name of the hero, their Might/Will/Fate in square brackets if known, and their points after a
+// dash. A warrior line starts with the number of warriors followed by "x", and gives the points of the whole group.
+// Either kind of line may end with a colon and a comma separated list of the wargear and options taken. Warriors
+// with an option ending in "bow", such as "bow", "Elf bow" or "crossbow", count towards the bow limit. "pts",
+// "pt", "points" and "point" are all accepted for points. Blank lines, lines starting with "#" and the "Total:" line
+// are skipped, the totals are always worked out from the warbands. Any other line is reported as unparsed.
+//
+// # JSON format
+//
+// The JSON format is a single object with the same fields as model.ArmyList, of which only the army and warbands
+// are read. A warband chosen from an allied army names the army in its own "army" field:
+//
+//	{
+//	  "army": "Minas Tirith",
+//	  "warbands": [
+//	    {
+//	      "hero": {"name": "Boromir, Captain of the White Tower", "points": 100, "might": 6, "will": 3, "fate": 3, "options": ["horse"]},
+//	      "units": [
+//	        {"name": "Warrior of Minas Tirith", "count": 5, "pointsEach": 9, "options": ["shield"]},
+//	        {"name": "Warrior of Minas Tirith", "count": 3, "pointsEach": 9, "bow": true}
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// Fields which are not part of the format are reported as unparsed, by their path such as "warbands[0].hero.colour".
+package armylist
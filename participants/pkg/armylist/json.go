@@ -0,0 +1,79 @@
+package armylist
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/participants/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"slices"
+	"strings"
+)
+
+// The fields of each object in the JSON format, anything else is reported as unparsed. The participant, round,
+// totals, alliance and lock of a list are accepted but ignored, so that a list fetched from the service can be
+// imported again as it is.
+var (
+	listFields    = []string{"participantId", "roundId", "army", "warbands", "totalPoints", "bowCount", "warriorCount", "alliance", "unlocked", "unlockedBy", "updatedAt"}
+	warbandFields = []string{"army", "hero", "units"}
+	heroFields    = []string{"name", "points", "might", "will", "fate", "options"}
+	unitFields    = []string{"name", "count", "pointsEach", "bow", "options"}
+)
+
+// ParseJSON reads an army list from the JSON format described in the package documentation. Fields which are not
+// part of the format are reported rather than failing the list, but a list which is not valid JSON, or has a field
+// of the wrong type, cannot be read at all and an error wrapping svcerrors.ErrModelInvalid is returned. Only the army
+// and warbands are taken from the JSON, the totals of the list are worked out from what was read.
+func ParseJSON(data []byte) (*model.ArmyList, []model.UnparsedLine, error) {
+	var read model.ArmyList
+	if err := json.Unmarshal(data, &read); err != nil {
+		return nil, nil, fmt.Errorf("army list %w: unable to read the JSON: %s", svcerrors.ErrModelInvalid, err.Error())
+	}
+
+	// The JSON has already been read once, so it is known to hold an object with an array of warbands
+	var fields map[string]json.RawMessage
+	var warbands []json.RawMessage
+	_ = json.Unmarshal(data, &fields)
+	_ = json.Unmarshal(fields["warbands"], &warbands)
+
+	unparsed := unknownFields([]model.UnparsedLine{}, "", fields, listFields)
+	for i, w := range warbands {
+		var warband map[string]json.RawMessage
+		_ = json.Unmarshal(w, &warband)
+		path := fmt.Sprintf("warbands[%d]", i)
+		unparsed = unknownFields(unparsed, path+".", warband, warbandFields)
+
+		var hero map[string]json.RawMessage
+		_ = json.Unmarshal(warband["hero"], &hero)
+		unparsed = unknownFields(unparsed, path+".hero.", hero, heroFields)
+
+		var units []map[string]json.RawMessage
+		_ = json.Unmarshal(warband["units"], &units)
+		for j, u := range units {
+			unparsed = unknownFields(unparsed, fmt.Sprintf("%s.units[%d].", path, j), u, unitFields)
+		}
+	}
+
+	a := &model.ArmyList{Army: read.Army, Warbands: read.Warbands}
+	if a.Warbands == nil {
+		a.Warbands = []model.Warband{}
+	}
+	a.ComputeTotals()
+	return a, unparsed, nil
+}
+
+// unknownFields adds a report for each of the fields of an object which is not one of the known fields, in the
+// order of their names so the report is the same every time
+func unknownFields(unparsed []model.UnparsedLine, path string, fields map[string]json.RawMessage, known []string) []model.UnparsedLine {
+	names := []string{}
+	for name := range fields {
+		if !slices.Contains(known, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		unparsed = append(unparsed, model.UnparsedLine{Text: path + name, Reason: "not a field of the army list format, expected one of " + strings.Join(known, ", ")})
+	}
+	return unparsed
+}
@@ -0,0 +1,154 @@
+package armylist
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/participants/pkg/model"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// points matches the ways the points of a line are written
+const points = `(\d+)\s*(?:pts?|points?)`
+
+var (
+	// armyLine matches the first line of a list, the army name with its points optionally after a dash
+	armyLine = regexp.MustCompile(`^(.+?)(?:\s+-\s+` + points + `)?$`)
+
+	// heroLine matches a hero, e.g. "Boromir [6/3/3] - 100 pts: horse"
+	heroLine = regexp.MustCompile(`^([^\s\[].*?)(?:\s*\[(\d+)/(\d+)/(\d+)\])?\s+-\s+` + points + `(?:\s*:\s*(.*))?$`)
+
+	// warriorLine matches a group of warriors, e.g. "5x Warrior of Minas Tirith - 45 pts: shield"
+	warriorLine = regexp.MustCompile(`^\s*(\d+)\s*x\s+(.+?)\s+-\s+` + points + `(?:\s*:\s*(.*))?$`)
+
+	// allyLine matches the start of the warbands chosen from an allied army, e.g. "Ally: Rohan"
+	allyLine = regexp.MustCompile(`(?i)^\s*ally\s*:\s*(.+)$`)
+
+	// totalLine matches the totals at the end of a list, which are skipped as they are worked out instead
+	totalLine = regexp.MustCompile(`(?i)^\s*total\s*:`)
+)
+
+// ParseText reads an army list from the plain text format described in the package documentation. Every line
+// which cannot be read is reported rather than failing the whole list, so the list returned may be incomplete when
+// anything is reported. The totals of the list are worked out from what was read.
+func ParseText(text string) (*model.ArmyList, []model.UnparsedLine) {
+	a := &model.ArmyList{Warbands: []model.Warband{}}
+	unparsed := []model.UnparsedLine{}
+	report := func(n int, line, reason string) {
+		unparsed = append(unparsed, model.UnparsedLine{Line: n, Text: line, Reason: reason})
+	}
+
+	ally := ""
+	s := bufio.NewScanner(strings.NewReader(text))
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimRight(s.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#") || totalLine.MatchString(line):
+			continue
+
+		case a.Army == "":
+			a.Army = armyLine.FindStringSubmatch(trimmed)[1]
+
+		case allyLine.MatchString(line):
+			ally = allyArmy(a, allyLine.FindStringSubmatch(line)[1])
+
+		case warriorLine.MatchString(line):
+			m := warriorLine.FindStringSubmatch(line)
+			count, _ := strconv.Atoi(m[1])
+			total, _ := strconv.Atoi(m[3])
+			if len(a.Warbands) == 0 {
+				report(n, line, "warriors have to follow the hero leading their warband")
+			} else if count < 1 || total%count != 0 {
+				report(n, line, fmt.Sprintf("%d points cannot be shared evenly between %d warriors", total, count))
+			} else {
+				w := &a.Warbands[len(a.Warbands)-1]
+				options := splitOptions(m[4])
+				w.Units = append(w.Units, model.UnitProfile{Name: m[2], Count: count, PointsEach: total / count, Bow: hasBow(options), Options: options})
+			}
+
+		case heroLine.MatchString(line):
+			m := heroLine.FindStringSubmatch(line)
+			h := model.HeroProfile{Name: m[1], Options: splitOptions(m[6])}
+			h.Might, _ = strconv.Atoi(m[2])
+			h.Will, _ = strconv.Atoi(m[3])
+			h.Fate, _ = strconv.Atoi(m[4])
+			h.Points, _ = strconv.Atoi(m[5])
+			a.Warbands = append(a.Warbands, model.Warband{Army: ally, Hero: h})
+
+		default:
+			report(n, line, "not a hero or warrior line, expected e.g. \"Name - 100 pts\" or \"5x Name - 45 pts\"")
+		}
+	}
+
+	a.ComputeTotals()
+	return a, unparsed
+}
+
+// FormatText writes the army list in the plain text format described in the package documentation, which
+// ParseText reads back to the same list. The totals of the list are worked out afresh for the Total line.
+func FormatText(a *model.ArmyList) string {
+	list := *a
+	list.ComputeTotals()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s - %d pts\n", list.Army, list.TotalPoints)
+	ally := ""
+	for _, w := range list.Warbands {
+		if army := allyArmy(&list, w.Army); army != ally {
+			ally = army
+			fmt.Fprintf(&b, "\nAlly: %s\n", cmp.Or(ally, list.Army))
+		}
+		h := w.Hero
+		fmt.Fprintf(&b, "\n%s [%d/%d/%d] - %d pts%s\n", h.Name, h.Might, h.Will, h.Fate, h.Points, joinOptions(h.Options))
+		for _, u := range w.Units {
+			options := u.Options
+			if u.Bow && !hasBow(options) {
+				options = append(options[:len(options):len(options)], "bow")
+			}
+			fmt.Fprintf(&b, "  %dx %s - %d pts%s\n", u.Count, u.Name, u.Count*u.PointsEach, joinOptions(options))
+		}
+	}
+	fmt.Fprintf(&b, "\nTotal: %d pts | %d bows | %d warriors\n", list.TotalPoints, list.BowCount, list.WarriorCount)
+	return b.String()
+}
+
+// allyArmy returns the army of a warband as it is held in the list, which is empty for a warband chosen from the
+// army of the list itself
+func allyArmy(a *model.ArmyList, army string) string {
+	if army = strings.TrimSpace(army); strings.EqualFold(army, a.Army) {
+		return ""
+	}
+	return army
+}
+
+// splitOptions reads the comma separated options at the end of a line
+func splitOptions(s string) []string {
+	var options []string
+	for _, o := range strings.Split(s, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			options = append(options, o)
+		}
+	}
+	return options
+}
+
+// joinOptions writes the options for the end of a line, nothing at all if there are none
+func joinOptions(options []string) string {
+	if len(options) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(options, ", ")
+}
+
+// hasBow returns true if one of the options is a bow of any kind, which counts towards the bow limit
+func hasBow(options []string) bool {
+	for _, o := range options {
+		if strings.HasSuffix(strings.ToLower(o), "bow") {
+			return true
+		}
+	}
+	return false
+}
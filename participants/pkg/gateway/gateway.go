@@ -0,0 +1,20 @@
+// Package gateway contains clients for interacting with the participants service from other services.
+// The package is meant to be public, and will commit to being backwards compatible within major versions.
+// The package primarily consists of the ParticipantsGateway interface, covering the parts of the service other
+// services call, with an implementation of the interface for calling it over HTTP.
+package gateway
+
+import (
+	"context"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	"github.com/rpatton4/mesbg-league/participants/pkg/model"
+)
+
+// ParticipantsGateway provides a set of methods for interacting with the Participants service from outside the
+// service.
+type ParticipantsGateway interface {
+	// ArmyListsForGame returns the army lists of both sides of the game with the given id, describing in OverLimit
+	// each list which does not fit the points limit of the game's round. A svcerrors.ErrNotFound is returned if no
+	// game with that id exists.
+	ArmyListsForGame(ctx context.Context, id games.GameID) (*model.GameArmyLists, error)
+}
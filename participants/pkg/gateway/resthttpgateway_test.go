@@ -0,0 +1,44 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPGatewayArmyListsForGame(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		} else if r.URL.Path != "/games/1/army-lists" {
+			http.Error(w, "Game not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"gameId":"1","pointsLimit":150,"overLimit":["the list of side 2 costs 172 points"]}`))
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithRetries(2, time.Millisecond))
+	lists, err := gw.ArmyListsForGame(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after retrying, got %v", err)
+	}
+	if lists.GameID != "1" || lists.PointsLimit != 150 || len(lists.OverLimit) != 1 || calls.Load() != 3 {
+		t.Errorf("Expected the lists of game 1 after 3 calls, got %+v after %d calls", lists, calls.Load())
+	}
+
+	if _, err = gw.ArmyListsForGame(context.Background(), "2"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown game, got %v", err)
+	}
+	if _, err = gw.ArmyListsForGame(context.Background(), ""); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID for an empty ID, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package model
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ArmyList is the army a participant has registered to play in their league, made up of warbands each led by a
+// hero. The totals are read-only, they are worked out from the warbands by the service and any values sent by a
+// client are ignored.
+type ArmyList struct {
+	// ParticipantID is the participant who registered the list
+	ParticipantID ParticipantID `json:"participantId"`
+
+	// RoundID is the round the list is registered for in an escalation league, empty for the list used in every
+	// round the participant has not registered a list of its own for
+	RoundID string `json:"roundId,omitempty"`
+
+	// Army is the faction or army the list is chosen from, e.g. "Minas Tirith"
+	Army string `json:"army"`
+
+	// Warbands holds every warband in the list, the first is led by the general
+	Warbands []Warband `json:"warbands"`
+
+	// TotalPoints is the cost of every hero and unit in the list
+	TotalPoints int `json:"totalPoints"`
+
+	// BowCount is the number of warriors in the list carrying a bow, heroes do not count towards the bow limit
+	BowCount int `json:"bowCount"`
+
+	// WarriorCount is the number of warriors in the list, not including the heroes
+	WarriorCount int `json:"warriorCount"`
+
+	// Alliance is the kind of alliance between the armies of a list with warbands from more than one army, one of
+	// "historical", "convenient" or "impossible", worked out by the service when the list is registered
+	Alliance string `json:"alliance,omitempty"`
+
+	// Unlocked is set when an organizer has unlocked the list for one more change after the league has started
+	Unlocked bool `json:"unlocked,omitempty"`
+
+	// UnlockedBy is the organizer who unlocked the list
+	UnlockedBy string `json:"unlockedBy,omitempty"`
+
+	// UpdatedAt is when the list was last registered or changed
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+}
+
+// Warband is a hero along with the warriors they lead
+type Warband struct {
+	// Army is the army the warband is chosen from when it is allied to the army of the list, empty for a warband
+	// chosen from the army of the list
+	Army string `json:"army,omitempty"`
+
+	// Hero leads the warband
+	Hero HeroProfile `json:"hero"`
+
+	// Units are the warriors in the warband, grouped by profile
+	Units []UnitProfile `json:"units,omitempty"`
+}
+
+// HeroProfile is one hero, with their cost and their store of Might, Will and Fate
+type HeroProfile struct {
+	// Name is the name of the hero, e.g. "Boromir, Captain of the White Tower"
+	Name string `json:"name"`
+
+	// Points is the cost of the hero including any options taken
+	Points int `json:"points"`
+
+	// Might is the hero's starting Might
+	Might int `json:"might"`
+
+	// Will is the hero's starting Will
+	Will int `json:"will"`
+
+	// Fate is the hero's starting Fate
+	Fate int `json:"fate"`
+
+	// Options lists the wargear and other options taken for the hero, e.g. "horse"
+	Options []string `json:"options,omitempty"`
+}
+
+// UnitProfile is a number of identical warriors within a warband
+type UnitProfile struct {
+	// Name is the name of the warrior profile, e.g. "Warrior of Minas Tirith"
+	Name string `json:"name"`
+
+	// Count is how many of the warrior are in the warband
+	Count int `json:"count"`
+
+	// PointsEach is the cost of one of the warriors including any options taken
+	PointsEach int `json:"pointsEach"`
+
+	// Bow is true if the warriors carry a bow, which counts them towards the bow limit
+	Bow bool `json:"bow,omitempty"`
+
+	// Options lists the wargear and other options taken for the warriors, e.g. "shield"
+	Options []string `json:"options,omitempty"`
+}
+
+// GameArmyLists holds the army lists of both sides of a game, so each player can see what they face. A side
+// without a registered list is left out.
+type GameArmyLists struct {
+	// GameID is the game the lists are for
+	GameID string `json:"gameId"`
+
+	// PointsLimit is the points limit of the game's round, zero if the round has no limit of its own
+	PointsLimit int `json:"pointsLimit,omitempty"`
+
+	// OverLimit describes each side whose list costs more than the points limit of the round, empty when both
+	// lists fit
+	OverLimit []string `json:"overLimit,omitempty"`
+
+	// Side1 is the list of the first side of the game
+	Side1 *ArmyList `json:"side1,omitempty"`
+
+	// Side2 is the list of the second side of the game
+	Side2 *ArmyList `json:"side2,omitempty"`
+}
+
+// ComputeTotals works out the read-only totals of the list from its warbands
+func (a *ArmyList) ComputeTotals() {
+	a.TotalPoints, a.BowCount, a.WarriorCount = 0, 0, 0
+	for _, w := range a.Warbands {
+		a.TotalPoints += w.Hero.Points
+		for _, u := range w.Units {
+			a.TotalPoints += u.Count * u.PointsEach
+			a.WarriorCount += u.Count
+			if u.Bow {
+				a.BowCount += u.Count
+			}
+		}
+	}
+}
+
+// Armies returns every army the list is chosen from, the army of the list first and then the army of each allied
+// warband in the order they appear, naming each army once
+func (a *ArmyList) Armies() []string {
+	armies := []string{a.Army}
+	for _, w := range a.Warbands {
+		if army := strings.TrimSpace(w.Army); army != "" && !slices.ContainsFunc(armies, func(s string) bool { return strings.EqualFold(s, army) }) {
+			armies = append(armies, army)
+		}
+	}
+	return armies
+}
+
+// IsValid checks if the army list has all required fields set and returns a boolean indicating validity. A slice of
+// strings is returned containing information about any invalid fields, one entry per field. The limits of the
+// league are checked separately by CheckLimits.
+func (a *ArmyList) IsValid() (bool, []string) {
+	invalidFields := []string{}
+	if a == nil {
+		return false, []string{"the army list is missing"}
+	}
+
+	if strings.TrimSpace(a.Army) == "" {
+		invalidFields = append(invalidFields, "Army is required")
+	}
+	if len(a.Warbands) == 0 {
+		invalidFields = append(invalidFields, "Warbands must have at least one warband")
+	}
+	for i, w := range a.Warbands {
+		h := w.Hero
+		if strings.TrimSpace(h.Name) == "" {
+			invalidFields = append(invalidFields, fmt.Sprintf("Warbands[%d] hero Name is required", i))
+		}
+		if h.Points < 0 || h.Might < 0 || h.Will < 0 || h.Fate < 0 {
+			invalidFields = append(invalidFields, fmt.Sprintf("Warbands[%d] hero '%s' cannot have negative points, Might, Will or Fate", i, h.Name))
+		}
+		for j, u := range w.Units {
+			if strings.TrimSpace(u.Name) == "" {
+				invalidFields = append(invalidFields, fmt.Sprintf("Warbands[%d].Units[%d] Name is required", i, j))
+			}
+			if u.Count < 1 || u.PointsEach < 0 {
+				invalidFields = append(invalidFields, fmt.Sprintf("Warbands[%d].Units[%d] '%s' needs a Count of 1 or more and PointsEach of 0 or more", i, j, u.Name))
+			}
+		}
+	}
+
+	if len(invalidFields) > 0 {
+		slog.Warn("Army list is missing required fields or has invalid values", "participantID", a.ParticipantID, "invalid", invalidFields)
+		return false, invalidFields
+	}
+	return true, invalidFields
+}
+
+// CheckLimits returns a description of every way the list breaks the given limits, empty if it keeps to them. The
+// totals must have been computed first. A zero points limit or bow limit is not checked. The bow limit is the
+// percentage of the warriors which may carry bows, rounding up, so 33 allows a third of them.
+func (a *ArmyList) CheckLimits(pointsLimit, bowLimitPercent int) []string {
+	broken := []string{}
+	if pointsLimit > 0 && a.TotalPoints > pointsLimit {
+		broken = append(broken, fmt.Sprintf("TotalPoints=%d is over the points limit of %d", a.TotalPoints, pointsLimit))
+	}
+	if most := MaxBows(a.WarriorCount, bowLimitPercent); bowLimitPercent > 0 && a.BowCount > most {
+		broken = append(broken, fmt.Sprintf("BowCount=%d is over the bow limit of %d for %d warriors", a.BowCount, most, a.WarriorCount))
+	}
+	return broken
+}
+
+// MaxBows returns how many of the given number of warriors may carry bows under a bow limit of the given
+// percentage, rounding up
+func MaxBows(warriors, bowLimitPercent int) int {
+	return (warriors*bowLimitPercent + 99) / 100
+}
+
+// UnparsedLine reports part of an imported army list which could not be read, and so is missing from the list
+type UnparsedLine struct {
+	// Line is the number of the line in a text list, starting at 1, or zero for a JSON list
+	Line int `json:"line,omitempty"`
+
+	// Text is the line as it was given, or the path of the field in a JSON list
+	Text string `json:"text"`
+
+	// Reason says why the line could not be read
+	Reason string `json:"reason"`
+}
+
+// ArmyListImport is the outcome of importing an army list from an export format
+type ArmyListImport struct {
+	// ArmyList is the list as it was read, with its totals worked out
+	ArmyList *ArmyList `json:"armyList"`
+
+	// Unparsed lists every part of the import which could not be read, the list is only registered when it is empty
+	Unparsed []UnparsedLine `json:"unparsed"`
+
+	// Registered is true if the list was registered for the participant, rather than only being read
+	Registered bool `json:"registered"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "maps"
+
 type ParticipantID string
 
 // Participant represents a player in a gaming league, linking the player to the league along with
@@ -14,6 +16,9 @@ type Participant struct {
 	// LeagueID is the unique identifier for the league in which the participant is playing
 	LeagueID string `json:"leagueId"`
 
+	// The totals below are read-only, they are rebuilt from the completed games of the league by the service and
+	// any values sent by a client are ignored.
+
 	// VictoryPointsScored tracks the current total victory points scored by the participant in the league
 	VictoryPointsScored int `json:"victoryPointsScored,omitempty"`
 
@@ -22,4 +27,47 @@ type Participant struct {
 
 	// GeneralsKilled records the current total number of opposing generals killed by the participant in the league
 	GeneralsKilled int `json:"generalsKilled,omitempty"`
+
+	// GamesCounted is the number of completed games the totals were last rebuilt from
+	GamesCounted int `json:"gamesCounted,omitempty"`
+
+	// The totals below come from the optional breakdowns of the games' results, so they only cover the games whose
+	// result was given with a breakdown for the participant's side.
+
+	// VictoryPointsByCategory records the victory points scored by the participant for each objective category
+	VictoryPointsByCategory map[string]int `json:"victoryPointsByCategory,omitempty"`
+
+	// HeroesSlain records the number of enemy heroes killed by the participant in the league
+	HeroesSlain int `json:"heroesSlain,omitempty"`
+
+	// ModelsRemoved records the number of enemy models removed as casualties by the participant in the league
+	ModelsRemoved int `json:"modelsRemoved,omitempty"`
+
+	// TimesBroken records the number of games in which the participant's side was broken
+	TimesBroken int `json:"timesBroken,omitempty"`
+
+	// TimesQuartered records the number of games in which the participant's side was quartered
+	TimesQuartered int `json:"timesQuartered,omitempty"`
+
+	// BreakdownsCounted is the number of completed games with a breakdown for the participant's side
+	BreakdownsCounted int `json:"breakdownsCounted,omitempty"`
+}
+
+// ClearTotals sets the read-only totals of the participant back to zero
+func (p *Participant) ClearTotals() {
+	p.CopyTotals(&Participant{})
+}
+
+// CopyTotals sets the read-only totals of the participant to those of the other participant
+func (p *Participant) CopyTotals(other *Participant) {
+	p.VictoryPointsScored = other.VictoryPointsScored
+	p.VictoryPointsConceded = other.VictoryPointsConceded
+	p.GeneralsKilled = other.GeneralsKilled
+	p.GamesCounted = other.GamesCounted
+	p.VictoryPointsByCategory = maps.Clone(other.VictoryPointsByCategory)
+	p.HeroesSlain = other.HeroesSlain
+	p.ModelsRemoved = other.ModelsRemoved
+	p.TimesBroken = other.TimesBroken
+	p.TimesQuartered = other.TimesQuartered
+	p.BreakdownsCounted = other.BreakdownsCounted
 }
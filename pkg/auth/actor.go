@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"github.com/danielgtaylor/huma/v2"
+	"net/http"
+	"strings"
+)
+
+// ActorHeader is the header a caller identifies itself with. Requests are not authenticated yet, so the value is
+// taken on trust and only used to record who made a change.
+const ActorHeader = "X-Actor"
+
+// actorKey is the context key for the actor, unexported so only this package can set it
+type actorKey struct{}
+
+// WithActor returns a copy of the context carrying the identifier of whoever is making the request
+func WithActor(ctx context.Context, actor string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the identifier of whoever is making the request, or an empty string if the caller did not say
+func Actor(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// ActorMiddleware is a Huma middleware which puts the actor from the ActorHeader of each request into its context
+func ActorMiddleware(ctx huma.Context, next func(huma.Context)) {
+	if actor := strings.TrimSpace(ctx.Header(ActorHeader)); actor != "" {
+		ctx = huma.WithValue(ctx, actorKey{}, actor)
+	}
+	next(ctx)
+}
+
+// ActorHandler is the net/http equivalent of ActorMiddleware, putting the actor from the ActorHeader of each request
+// into its context before passing it on to next
+func ActorHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := strings.TrimSpace(r.Header.Get(ActorHeader)); actor != "" {
+			r = r.WithContext(WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
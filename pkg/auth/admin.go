@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+	"net/http"
+	"slices"
+)
+
+// RequireAdmin returns a Huma middleware which only lets a request through when its actor, put in the context by
+// ActorMiddleware, is one of the given administrators. Other requests are refused with a 403, as are all of them when
+// there are no administrators.
+func RequireAdmin(api huma.API, admins []string) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if actor := Actor(ctx.Context()); actor == "" || !slices.Contains(admins, actor) {
+			_ = huma.WriteErr(api, ctx, http.StatusForbidden, "only an administrator can do this, identified by the "+ActorHeader+" header")
+			return
+		}
+		next(ctx)
+	}
+}
+
+// RequireAdminHandler is the net/http equivalent of RequireAdmin, only passing a request on to next when its actor,
+// put in the context by ActorHandler, is one of the given administrators
+func RequireAdminHandler(admins []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := Actor(r.Context()); actor == "" || !slices.Contains(admins, actor) {
+			http.Error(w, "only an administrator can do this, identified by the "+ActorHeader+" header", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAdminHandler(t *testing.T) {
+	handler := ActorHandler(RequireAdminHandler([]string{"organizer"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	status := func(actor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/participants/1/army-list/unlock", nil)
+		if actor != "" {
+			req.Header.Set(ActorHeader, actor)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if got := status(""); got != http.StatusForbidden {
+		t.Errorf("Expected a request without an actor to be forbidden, got %d", got)
+	}
+	if got := status("player"); got != http.StatusForbidden {
+		t.Errorf("Expected a request by a player to be forbidden, got %d", got)
+	}
+	if got := status("organizer"); got != http.StatusNoContent {
+		t.Errorf("Expected a request by an administrator to be passed on, got %d", got)
+	}
+}
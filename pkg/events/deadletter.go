@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DeadLetter records an event which could not be delivered to one subscriber, so it can be looked at and replayed
+type DeadLetter struct {
+	// Envelope is the event as it was published
+	Envelope Envelope `json:"envelope"`
+
+	// Target identifies who the event could not be delivered to, the subscription name for in-process delivery or
+	// the URL for webhook delivery
+	Target string `json:"target"`
+
+	// Attempts is the number of delivery attempts made before giving up
+	Attempts int `json:"attempts"`
+
+	// Error is the error from the last attempt
+	Error string `json:"error"`
+
+	// FailedAt is when delivery was given up on
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// Key identifies the dead letter in its store, an event can fail for more than one target
+func (d DeadLetter) Key() string {
+	return d.Envelope.ID + "/" + d.Target
+}
+
+// DeadLetterStore keeps the events which could not be delivered until they are replayed
+type DeadLetterStore interface {
+	// Add stores the dead letter, replacing any earlier one with the same key
+	Add(ctx context.Context, d DeadLetter) error
+
+	// List returns every stored dead letter, oldest first
+	List(ctx context.Context) ([]DeadLetter, error)
+
+	// Remove deletes the dead letter with the given key, returning svcerrors.ErrNotFound if there is none
+	Remove(ctx context.Context, key string) error
+}
+
+// MemoryDeadLetterStore is a DeadLetterStore which only lasts as long as the process
+type MemoryDeadLetterStore struct {
+	sync.Mutex
+	letters []DeadLetter
+}
+
+// NewMemoryDeadLetterStore creates an empty in-memory dead-letter store
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{}
+}
+
+func (s *MemoryDeadLetterStore) Add(_ context.Context, d DeadLetter) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.letters = slices.DeleteFunc(s.letters, func(o DeadLetter) bool { return o.Key() == d.Key() })
+	s.letters = append(s.letters, d)
+	return nil
+}
+
+func (s *MemoryDeadLetterStore) List(_ context.Context) ([]DeadLetter, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	return slices.Clone(s.letters), nil
+}
+
+func (s *MemoryDeadLetterStore) Remove(_ context.Context, key string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	i := slices.IndexFunc(s.letters, func(d DeadLetter) bool { return d.Key() == key })
+	if i < 0 {
+		return fmt.Errorf("no dead letter with key '%s'. Source: %w", key, svcerrors.ErrNotFound)
+	}
+	s.letters = slices.Delete(s.letters, i, i+1)
+	return nil
+}
+
+// replay delivers every dead letter in the store again with the given function, removing the ones which are
+// delivered this time. The number replayed successfully is returned.
+func replay(ctx context.Context, store DeadLetterStore, redeliver func(ctx context.Context, d DeadLetter) error) (int, error) {
+	letters, err := store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, d := range letters {
+		if err := redeliver(ctx, d); err != nil {
+			continue
+		}
+		if err := store.Remove(ctx, d.Key()); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
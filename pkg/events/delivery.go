@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// delivery holds what both buses share: delivering an envelope to one target in the background with retries,
+// dead-lettering it when the retries run out
+type delivery struct {
+	policy RetryPolicy
+	store  DeadLetterStore
+	wg     sync.WaitGroup
+}
+
+// Option configures a bus when it is created
+type Option func(*delivery)
+
+// WithRetryPolicy sets how delivery to a subscriber is retried, DefaultRetryPolicy is used otherwise
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(d *delivery) {
+		d.policy = p
+	}
+}
+
+// WithDeadLetterStore sets where undeliverable events are kept, a new MemoryDeadLetterStore is used otherwise
+func WithDeadLetterStore(s DeadLetterStore) Option {
+	return func(d *delivery) {
+		d.store = s
+	}
+}
+
+// configure applies the defaults and then the options, it is called once when a bus is created
+func (d *delivery) configure(opts []Option) {
+	d.policy = DefaultRetryPolicy
+	d.store = NewMemoryDeadLetterStore()
+	for _, opt := range opts {
+		opt(d)
+	}
+}
+
+// DeadLetters returns the store holding the events which could not be delivered
+func (d *delivery) DeadLetters() DeadLetterStore {
+	return d.store
+}
+
+// Wait blocks until every delivery started so far has either succeeded or been dead-lettered, for shutting down
+// cleanly and for tests
+func (d *delivery) Wait() {
+	d.wg.Wait()
+}
+
+// deliverAsync starts delivering the envelope to the target in the background. The publisher's context is only
+// used for its values, the delivery carries on after the call which published the event has returned.
+func (d *delivery) deliverAsync(ctx context.Context, env Envelope, target string, fn func(ctx context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(ctx, env, target, fn)
+	}()
+}
+
+// deliver delivers the envelope to the target with retries, dead-lettering it if every attempt fails
+func (d *delivery) deliver(ctx context.Context, env Envelope, target string, fn func(ctx context.Context) error) error {
+	attempts, err := d.policy.deliver(ctx, fn)
+	if err == nil {
+		return nil
+	}
+
+	slog.Error("Giving up on delivering event", "eventID", env.ID, "type", env.Type, "target", target, "attempts", attempts, "error", err)
+	dl := DeadLetter{Envelope: env, Target: target, Attempts: attempts, Error: err.Error(), FailedAt: time.Now().UTC()}
+	if serr := d.store.Add(ctx, dl); serr != nil {
+		slog.Error("Unable to store undeliverable event, it is lost", "eventID", env.ID, "target", target, "error", serr)
+	}
+	return err
+}
@@ -0,0 +1,192 @@
+// Package events is a small publish/subscribe abstraction which lets the services react to changes made in other
+// services. Events are typed structs wrapped in an Envelope for delivery, and delivery is at-least-once: a handler
+// may see the same envelope more than once (after a retry or a replay from the dead-letter store) and should use
+// the envelope ID to ignore repeats when that matters.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	games "github.com/rpatton4/mesbg-league/games/pkg"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	players "github.com/rpatton4/mesbg-league/players/pkg"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"time"
+)
+
+// Type names a kind of event, it is carried in the envelope so the payload can be decoded into the right struct
+type Type string
+
+const (
+	// TypeGameCreated is the type of GameCreated events
+	TypeGameCreated Type = "game.created"
+
+	// TypeGameUpdated is the type of GameUpdated events
+	TypeGameUpdated Type = "game.updated"
+
+	// TypeGameCompleted is the type of GameCompleted events
+	TypeGameCompleted Type = "game.completed"
+
+	// TypeGameDeleted is the type of GameDeleted events
+	TypeGameDeleted Type = "game.deleted"
+
+	// TypeGameRestored is the type of GameRestored events
+	TypeGameRestored Type = "game.restored"
+
+	// TypeGamePurged is the type of GamePurged events
+	TypeGamePurged Type = "game.purged"
+
+	// TypeParticipantJoined is the type of ParticipantJoined events
+	TypeParticipantJoined Type = "participant.joined"
+)
+
+// Event is implemented by every typed event, so it can be published without saying its type separately
+type Event interface {
+	EventType() Type
+}
+
+// GameCreated is published after a new game has been stored
+type GameCreated struct {
+	Game gamesmodel.Game `json:"game"`
+}
+
+// GameUpdated is published after a game has been replaced, unless the change completed the game, which is published
+// as GameCompleted instead
+type GameUpdated struct {
+	Game gamesmodel.Game `json:"game"`
+
+	// ReplacedSides lists the players who were a side of the game before the change but are not any more
+	ReplacedSides []players.PlayerID `json:"replacedSides,omitempty"`
+}
+
+// GameCompleted is published after a game has been moved to the played state with its result recorded
+type GameCompleted struct {
+	Game gamesmodel.Game `json:"game"`
+}
+
+// GameDeleted is published after a game has been removed, it can still be restored until it is purged. The sides
+// are included so the totals of their players can be recomputed without the game.
+type GameDeleted struct {
+	GameID  games.GameID     `json:"gameId"`
+	RoundID rounds.RoundID   `json:"roundId,omitempty"`
+	Side1ID players.PlayerID `json:"side1Id,omitempty"`
+	Side2ID players.PlayerID `json:"side2Id,omitempty"`
+}
+
+// GameRestored is published after a deleted game has been restored, the game is as it was before it was deleted
+type GameRestored struct {
+	Game gamesmodel.Game `json:"game"`
+}
+
+// GamePurged is published after a deleted game has been removed for good, it can no longer be restored
+type GamePurged struct {
+	GameID  games.GameID   `json:"gameId"`
+	RoundID rounds.RoundID `json:"roundId,omitempty"`
+}
+
+// ParticipantJoined is published after a player has joined a league as a participant
+type ParticipantJoined struct {
+	ParticipantID string           `json:"participantId"`
+	PlayerID      players.PlayerID `json:"playerId"`
+	LeagueID      string           `json:"leagueId"`
+}
+
+func (GameCreated) EventType() Type       { return TypeGameCreated }
+func (GameUpdated) EventType() Type       { return TypeGameUpdated }
+func (GameCompleted) EventType() Type     { return TypeGameCompleted }
+func (GameDeleted) EventType() Type       { return TypeGameDeleted }
+func (GameRestored) EventType() Type      { return TypeGameRestored }
+func (GamePurged) EventType() Type        { return TypeGamePurged }
+func (ParticipantJoined) EventType() Type { return TypeParticipantJoined }
+
+// Envelope is the form an event is delivered in, the payload holding the JSON of the typed event
+type Envelope struct {
+	// ID uniquely identifies the published event, it is the same on every delivery of it
+	ID string `json:"id"`
+
+	// Type says which typed event the payload holds
+	Type Type `json:"type"`
+
+	// OccurredAt is when the event was published
+	OccurredAt time.Time `json:"occurredAt"`
+
+	// Payload is the JSON of the typed event
+	Payload json.RawMessage `json:"payload"`
+}
+
+// NewEnvelope wraps the event for delivery, giving it a new ID
+func NewEnvelope(e Event) (Envelope, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("unable to encode %s event: %w", e.EventType(), err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Envelope{}, fmt.Errorf("unable to create an event ID: %w", err)
+	}
+
+	return Envelope{ID: hex.EncodeToString(id), Type: e.EventType(), OccurredAt: time.Now().UTC(), Payload: payload}, nil
+}
+
+// Decode reads the payload of the envelope into the given typed event, which must be of the envelope's type
+func Decode[T Event](env Envelope) (T, error) {
+	var e T
+	if e.EventType() != env.Type {
+		return e, fmt.Errorf("cannot decode a %s event as %s", env.Type, e.EventType())
+	}
+	if err := json.Unmarshal(env.Payload, &e); err != nil {
+		return e, fmt.Errorf("unable to decode %s event '%s': %w", env.Type, env.ID, err)
+	}
+	return e, nil
+}
+
+// Handler reacts to a delivered event. Returning an error asks for the event to be delivered again later, after
+// the last retry it is put in the dead-letter store.
+type Handler func(ctx context.Context, env Envelope) error
+
+// Publisher sends events to whoever has subscribed to them
+type Publisher interface {
+	// Publish hands the event over for delivery. It returns once the event has been accepted, delivery itself
+	// happens in the background so a slow subscriber does not hold up the publisher.
+	Publish(ctx context.Context, e Event) error
+}
+
+// Deliverer delivers an envelope which has already been built, such as one recorded in an outbox, keeping its ID
+type Deliverer interface {
+	// Deliver makes a single attempt at delivering the envelope to every subscriber of its type before returning,
+	// the errors of the subscribers which failed are returned joined together. Retrying is left to the caller, and
+	// nothing is dead-lettered.
+	Deliver(ctx context.Context, env Envelope) error
+}
+
+// Subscriber registers the handlers events are delivered to
+type Subscriber interface {
+	// Subscribe registers the handler for events of the given type. The name identifies the subscription in the
+	// dead-letter store, so it has to be unique for the type and stay the same between restarts.
+	Subscribe(t Type, name string, h Handler)
+}
+
+// On subscribes a handler taking the typed event rather than the envelope, e.g.
+//
+//	events.On(bus, "recompute-participants", func(ctx context.Context, e events.GameCompleted) error {...})
+func On[T Event](s Subscriber, name string, h func(ctx context.Context, e T) error) {
+	var zero T
+	s.Subscribe(zero.EventType(), name, func(ctx context.Context, env Envelope) error {
+		e, err := Decode[T](env)
+		if err != nil {
+			return err
+		}
+		return h(ctx, e)
+	})
+}
+
+// Discard is a Publisher which drops every event, for when nothing is listening
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(context.Context, Event) error { return nil }
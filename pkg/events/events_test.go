@@ -0,0 +1,187 @@
+package events
+
+import (
+	"context"
+	"errors"
+	gamesmodel "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetries keeps the tests quick while still going through the backoff
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+func TestInProcessBusDeliversTypedEvents(t *testing.T) {
+	bus := NewInProcessBus(fastRetries)
+
+	var mu sync.Mutex
+	received := []GameCompleted{}
+	On(bus, "record", func(_ context.Context, e GameCompleted) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, e)
+		return nil
+	})
+
+	if err := bus.Publish(context.Background(), GameCompleted{Game: gamesmodel.Game{ID: "1", Side1TotalVictoryPoints: 7}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Nobody is subscribed to deleted games, so it goes nowhere
+	if err := bus.Publish(context.Background(), GameDeleted{GameID: "2"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Wait()
+
+	if len(received) != 1 || received[0].Game.ID != "1" || received[0].Game.Side1TotalVictoryPoints != 7 {
+		t.Errorf("Expected the completed game to be delivered once, got %+v", received)
+	}
+}
+
+func TestInProcessBusRetriesThenDeadLetters(t *testing.T) {
+	bus := NewInProcessBus(fastRetries)
+
+	var calls atomic.Int32
+	failing := atomic.Bool{}
+	failing.Store(true)
+	bus.Subscribe(TypeGameCreated, "flaky", func(context.Context, Envelope) error {
+		calls.Add(1)
+		if failing.Load() {
+			return errors.New("subscriber is down")
+		}
+		return nil
+	})
+
+	if err := bus.Publish(context.Background(), GameCreated{Game: gamesmodel.Game{ID: "1"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Wait()
+
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls.Load())
+	}
+	dls, _ := bus.DeadLetters().List(context.Background())
+	if len(dls) != 1 || dls[0].Target != "flaky" || dls[0].Attempts != 3 || dls[0].Error != "subscriber is down" {
+		t.Fatalf("Expected one dead letter for the flaky subscriber, got %+v", dls)
+	}
+
+	// Replaying while the subscriber still fails keeps the dead letter
+	if n, err := bus.Replay(context.Background()); n != 0 || err != nil {
+		t.Errorf("Expected nothing replayed, got %d, %v", n, err)
+	}
+	failing.Store(false)
+	if n, err := bus.Replay(context.Background()); n != 1 || err != nil {
+		t.Errorf("Expected the dead letter to be replayed, got %d, %v", n, err)
+	}
+	if dls, _ = bus.DeadLetters().List(context.Background()); len(dls) != 0 {
+		t.Errorf("Expected no dead letters after a successful replay, got %+v", dls)
+	}
+}
+
+func TestWebhookBusDeliversToReceiver(t *testing.T) {
+	receiver := NewWebhookReceiver()
+	var attempts atomic.Int32
+	got := make(chan GameDeleted, 1)
+	On(receiver, "record", func(_ context.Context, e GameDeleted) error {
+		// The first delivery fails, so the sender has to retry it
+		if attempts.Add(1) == 1 {
+			return errors.New("not ready yet")
+		}
+		got <- e
+		return nil
+	})
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	bus := NewWebhookBus(srv.Client(), fastRetries)
+	bus.AddWebhook(srv.URL, TypeGameDeleted)
+	if err := bus.Publish(context.Background(), GameDeleted{GameID: "9", RoundID: "3"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Only deleted games were asked for
+	if err := bus.Publish(context.Background(), GameCreated{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Wait()
+
+	select {
+	case e := <-got:
+		if e.GameID != "9" || e.RoundID != "3" {
+			t.Errorf("Expected the deleted game to be received, got %+v", e)
+		}
+	default:
+		t.Fatal("Expected the event to be received")
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", attempts.Load())
+	}
+	if dls, _ := bus.DeadLetters().List(context.Background()); len(dls) != 0 {
+		t.Errorf("Expected no dead letters, got %+v", dls)
+	}
+}
+
+func TestWebhookBusDeadLettersUnreachableURL(t *testing.T) {
+	srv := httptest.NewServer(NewWebhookReceiver())
+	url := srv.URL
+	srv.Close()
+
+	bus := NewWebhookBus(nil, fastRetries)
+	bus.AddWebhook(url)
+	if err := bus.Publish(context.Background(), ParticipantJoined{ParticipantID: "1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Wait()
+
+	dls, _ := bus.DeadLetters().List(context.Background())
+	if len(dls) != 1 || dls[0].Target != url || dls[0].Envelope.Type != TypeParticipantJoined {
+		t.Fatalf("Expected one dead letter for the unreachable URL, got %+v", dls)
+	}
+	if n, err := bus.Replay(context.Background()); n != 0 || err != nil {
+		t.Errorf("Expected nothing replayed while the URL is unreachable, got %d, %v", n, err)
+	}
+}
+
+func TestDecodeRejectsOtherTypes(t *testing.T) {
+	env, err := NewEnvelope(GameCreated{Game: gamesmodel.Game{ID: "1"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = Decode[GameDeleted](env); err == nil {
+		t.Error("Expected an error decoding a created event as a deleted one")
+	}
+	if e, err := Decode[GameCreated](env); err != nil || e.Game.ID != "1" {
+		t.Errorf("Expected the created event back, got %+v, %v", e, err)
+	}
+}
+
+func TestDeliverMakesOneAttemptPerSubscriber(t *testing.T) {
+	bus := NewInProcessBus(fastRetries)
+	var calls atomic.Int32
+	bus.Subscribe(TypeGameUpdated, "ok", func(context.Context, Envelope) error { return nil })
+	bus.Subscribe(TypeGameUpdated, "down", func(context.Context, Envelope) error {
+		calls.Add(1)
+		return errors.New("subscriber is down")
+	})
+
+	env, _ := NewEnvelope(GameUpdated{Game: gamesmodel.Game{ID: "1"}})
+	if err := bus.Deliver(context.Background(), env); err == nil {
+		t.Error("Expected the failing subscriber to be reported")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls.Load())
+	}
+	if dls, _ := bus.DeadLetters().List(context.Background()); len(dls) != 0 {
+		t.Errorf("Expected nothing to be dead-lettered by Deliver, got %+v", dls)
+	}
+
+	receiver := NewWebhookReceiver()
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+	hooks := NewWebhookBus(srv.Client())
+	hooks.AddWebhook(srv.URL, TypeGameUpdated)
+	if err := hooks.Deliver(context.Background(), env); err != nil {
+		t.Errorf("Expected the webhook delivery to succeed, got %v", err)
+	}
+}
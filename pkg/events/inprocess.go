@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// InProcessBus delivers events to handlers subscribed in the same process
+type InProcessBus struct {
+	delivery
+
+	mu   sync.RWMutex
+	subs map[Type]map[string]Handler
+}
+
+// NewInProcessBus creates a bus with no subscribers
+func NewInProcessBus(opts ...Option) *InProcessBus {
+	b := &InProcessBus{subs: map[Type]map[string]Handler{}}
+	b.configure(opts)
+	return b
+}
+
+// Subscribe registers the handler for events of the given type, replacing any handler with the same name
+func (b *InProcessBus) Subscribe(t Type, name string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subs[t] == nil {
+		b.subs[t] = map[string]Handler{}
+	}
+	b.subs[t][name] = h
+}
+
+// Publish starts delivering the event to every handler subscribed to its type
+func (b *InProcessBus) Publish(ctx context.Context, e Event) error {
+	env, err := NewEnvelope(e)
+	if err != nil {
+		return err
+	}
+	b.dispatch(ctx, env)
+	return nil
+}
+
+// dispatch starts delivering an envelope which has already been built, to every handler subscribed to its type
+func (b *InProcessBus) dispatch(ctx context.Context, env Envelope) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for name, h := range b.subs[env.Type] {
+		b.deliverAsync(ctx, env, name, func(ctx context.Context) error {
+			return h(ctx, env)
+		})
+	}
+}
+
+// Replay delivers every dead letter again to the handler it failed for, removing those delivered this time. The
+// number replayed successfully is returned.
+func (b *InProcessBus) Replay(ctx context.Context) (int, error) {
+	return replay(ctx, b.store, func(ctx context.Context, d DeadLetter) error {
+		b.mu.RLock()
+		h, ok := b.subs[d.Envelope.Type][d.Target]
+		b.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("no handler named '%s' is subscribed to %s events", d.Target, d.Envelope.Type)
+		}
+		return b.deliver(ctx, d.Envelope, d.Target, func(ctx context.Context) error {
+			return h(ctx, d.Envelope)
+		})
+	})
+}
+
+// Deliver calls every handler subscribed to the envelope's type once, in turn, returning the errors of those which
+// failed
+func (b *InProcessBus) Deliver(ctx context.Context, env Envelope) error {
+	b.mu.RLock()
+	handlers := make(map[string]Handler, len(b.subs[env.Type]))
+	for name, h := range b.subs[env.Type] {
+		handlers[name] = h
+	}
+	b.mu.RUnlock()
+
+	var errs []error
+	for name, h := range handlers {
+		if err := h(ctx, env); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
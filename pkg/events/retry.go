@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy controls how often delivery of an event to one subscriber is attempted before it is given up on
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+
+	// Backoff is the wait before the first retry, doubling for each retry after that
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy is used when a bus is not given a policy of its own
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, Backoff: 200 * time.Millisecond}
+
+// deliver calls the delivery function until it succeeds or the attempts run out, returning the number of attempts
+// made and the last error. The context ending stops any further attempts.
+func (p RetryPolicy) deliver(ctx context.Context, fn func(ctx context.Context) error) (int, error) {
+	attempts := max(p.MaxAttempts, 1)
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return attempt, ctx.Err()
+			case <-time.After(p.Backoff << (attempt - 1)):
+			}
+		}
+		if err = fn(ctx); err == nil {
+			return attempt + 1, nil
+		}
+	}
+	return attempts, err
+}
@@ -0,0 +1,201 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultWebhookTimeout is how long a single webhook call may take before it is abandoned and retried
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookBus delivers events by POSTing their envelopes as JSON to the URLs registered for them, normally a
+// WebhookReceiver in another service. Any 2xx response counts as delivered.
+type WebhookBus struct {
+	delivery
+
+	client  *http.Client
+	timeout time.Duration
+
+	mu    sync.RWMutex
+	hooks map[string][]Type
+}
+
+// NewWebhookBus creates a bus with no webhooks registered, calls are made with the given client, or
+// http.DefaultClient if it is nil
+func NewWebhookBus(client *http.Client, opts ...Option) *WebhookBus {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	b := &WebhookBus{client: client, timeout: DefaultWebhookTimeout, hooks: map[string][]Type{}}
+	b.configure(opts)
+	return b
+}
+
+// AddWebhook registers the URL to receive events of the given types, or every type if none are given
+func (b *WebhookBus) AddWebhook(url string, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.hooks[url] = slices.Clone(types)
+}
+
+// Publish starts delivering the event to every webhook registered for its type
+func (b *WebhookBus) Publish(ctx context.Context, e Event) error {
+	env, err := NewEnvelope(e)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("unable to encode %s event '%s': %w", env.Type, env.ID, err)
+	}
+
+	for _, url := range b.urlsFor(env.Type) {
+		b.deliverAsync(ctx, env, url, func(ctx context.Context) error {
+			return b.post(ctx, url, env, body)
+		})
+	}
+	return nil
+}
+
+// Replay delivers every dead letter again to the URL it failed for, removing those delivered this time. The
+// number replayed successfully is returned.
+func (b *WebhookBus) Replay(ctx context.Context) (int, error) {
+	return replay(ctx, b.store, func(ctx context.Context, d DeadLetter) error {
+		body, err := json.Marshal(d.Envelope)
+		if err != nil {
+			return err
+		}
+		return b.deliver(ctx, d.Envelope, d.Target, func(ctx context.Context) error {
+			return b.post(ctx, d.Target, d.Envelope, body)
+		})
+	})
+}
+
+// Deliver posts the envelope once to every webhook registered for its type, in turn, returning the errors of those
+// which failed
+func (b *WebhookBus) Deliver(ctx context.Context, env Envelope) error {
+	body, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("unable to encode %s event '%s': %w", env.Type, env.ID, err)
+	}
+
+	var errs []error
+	for _, url := range b.urlsFor(env.Type) {
+		if err := b.post(ctx, url, env, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// urlsFor returns the URLs of the webhooks registered for events of the given type
+func (b *WebhookBus) urlsFor(t Type) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	urls := []string{}
+	for url, types := range b.hooks {
+		if len(types) == 0 || slices.Contains(types, t) {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// post makes a single attempt at delivering the envelope to the URL
+func (b *WebhookBus) post(ctx context.Context, url string, env Envelope, body []byte) error {
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", env.ID)
+	req.Header.Set("X-Event-Type", string(env.Type))
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook responded %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// WebhookReceiver is the http.Handler receiving the events sent by a WebhookBus in another service, handing each
+// to the handlers subscribed to its type. The handlers are called before responding, and any of them failing
+// makes the response a 500 so the sender delivers the event again.
+type WebhookReceiver struct {
+	mu   sync.RWMutex
+	subs map[Type]map[string]Handler
+}
+
+// NewWebhookReceiver creates a receiver with no subscribers, events of types nobody subscribed to are accepted
+// and ignored
+func NewWebhookReceiver() *WebhookReceiver {
+	return &WebhookReceiver{subs: map[Type]map[string]Handler{}}
+}
+
+// Subscribe registers the handler for events of the given type, replacing any handler with the same name
+func (r *WebhookReceiver) Subscribe(t Type, name string, h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.subs[t] == nil {
+		r.subs[t] = map[string]Handler{}
+	}
+	r.subs[t][name] = h
+}
+
+func (r *WebhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var env Envelope
+	if err := json.NewDecoder(req.Body).Decode(&env); err != nil || env.ID == "" || env.Type == "" {
+		slog.Warn("Received a malformed event", "error", err)
+		http.Error(w, "Invalid event envelope", http.StatusBadRequest)
+		return
+	}
+
+	r.mu.RLock()
+	handlers := make(map[string]Handler, len(r.subs[env.Type]))
+	for name, h := range r.subs[env.Type] {
+		handlers[name] = h
+	}
+	r.mu.RUnlock()
+
+	var errs []error
+	for name, h := range handlers {
+		if err := h(req.Context(), env); err != nil {
+			slog.Error("Event handler failed, asking for redelivery", "eventID", env.ID, "type", env.Type, "handler", name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		http.Error(w, errors.Join(errs...).Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -15,3 +15,18 @@ var ErrModelMissing = errors.New("model is missing, nil was provided instead")
 
 // ErrModelInvalid is returned when a model is invalid, as in expected or required values are not present
 var ErrModelInvalid = errors.New("model is invalid")
+
+// ErrInvalidQuery is returned when the parameters for a query or listing cannot be used, such as a malformed cursor
+var ErrInvalidQuery = errors.New("query is invalid")
+
+// ErrIllegalStateTransition is returned when a change would move a resource between two states which its lifecycle
+// does not allow, such as reopening a game which has already been completed
+var ErrIllegalStateTransition = errors.New("state transition is not allowed")
+
+// ErrConflict is returned when a change cannot be made because of the current state of the resource, such as
+// generating the games for a round which already has games
+var ErrConflict = errors.New("conflicts with the current state of the resource")
+
+// ErrVersionConflict is returned when a write names the version of the resource it was based on, and the resource
+// has been changed since then, such as two players submitting the result of the same game at once
+var ErrVersionConflict = errors.New("the resource has been changed since the given version")
@@ -1,22 +1,70 @@
 package main
 
 import (
+	"fmt"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	gamesgateway "github.com/rpatton4/mesbg-league/games/pkg/gateway"
+	leaguesgateway "github.com/rpatton4/mesbg-league/leagues/pkg/gateway"
 	"github.com/rpatton4/mesbg-league/rounds/internal/domain"
 	handlerhttp "github.com/rpatton4/mesbg-league/rounds/internal/handler/http"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository"
 	"github.com/rpatton4/mesbg-league/rounds/internal/repository/memory"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository/sqlite"
+	scenariosgateway "github.com/rpatton4/mesbg-league/scenarios/pkg/gateway"
 	"log/slog"
 	"net/http"
+	"os"
 )
 
+var port = "8085"
+
 func main() {
-	slog.Info("Starting the Rounds service...")
-	repo := memory.New()
-	ctrl := domain.New(repo)
-	handler := handlerhttp.New(ctrl)
+	logHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
+	slog.SetDefault(slog.New(logHandler))
+
+	slog.Info("Starting the Rounds service on port " + port)
+	leaguesAddr := os.Getenv("LEAGUES_SERVICE_ADDR")
+	if leaguesAddr == "" {
+		leaguesAddr = "http://localhost:8082"
+	}
+	gamesAddr := os.Getenv("GAMES_SERVICE_ADDR")
+	if gamesAddr == "" {
+		gamesAddr = "http://localhost:8081"
+	}
+	scenariosAddr := os.Getenv("SCENARIOS_SERVICE_ADDR")
+	if scenariosAddr == "" {
+		scenariosAddr = "http://localhost:8086"
+	}
+
+	repo, err := newRepository(repository.ConfigFromEnv())
+	if err != nil {
+		slog.Error("Failed to set up the rounds repository", "error", err.Error())
+		panic(err)
+	}
+	ctrl := domain.New(repo, leaguesgateway.New(leaguesAddr), gamesgateway.New(gamesAddr), scenariosgateway.New(scenariosAddr))
+	handler := handlerhttp.NewHumaHandler(ctrl)
+
+	router := http.NewServeMux()
+
+	api := humago.New(router, huma.DefaultConfig("Rounds Service", "1.0.0"))
+
+	handlerhttp.RegisterRoutes(api, handler)
 
-	http.Handle("/players", http.HandlerFunc(handler.GetRound))
-	if err := http.ListenAndServe(":8081", nil); err != nil {
+	if err := http.ListenAndServe(":"+port, router); err != nil {
 		slog.Error("Failed to start HTTP server", "error", err.Error())
 		panic(err)
 	}
 }
+
+// newRepository creates the repository adapter selected by the given configuration
+func newRepository(cfg repository.Config) (repository.Repository, error) {
+	switch cfg.Kind {
+	case "", repository.KindMemory:
+		return memory.New(), nil
+	case repository.KindSQLite:
+		return sqlite.New(cfg.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unknown rounds repository kind '%s'", cfg.Kind)
+	}
+}
@@ -2,24 +2,207 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	leaguesmodel "github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
 	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	scenarios "github.com/rpatton4/mesbg-league/scenarios/pkg"
+	scenariosmodel "github.com/rpatton4/mesbg-league/scenarios/pkg/model"
+	"strings"
 )
 
-type roundRepository interface {
-	Get(ctx context.Context, id int) (*model.Round, error)
+// leagueSource is the part of the leagues gateway the rounds controller needs, to check that a round's league exists
+type leagueSource interface {
+	GetByID(ctx context.Context, id leagues.LeagueID) (*leaguesmodel.League, error)
+}
+
+// scenarioSource is the part of the scenarios gateway the rounds controller needs, to check the scenario of a round
+type scenarioSource interface {
+	GetByID(ctx context.Context, id scenarios.ScenarioID) (*scenariosmodel.Scenario, error)
 }
 
 // Controller defines the simple controller for round operations.
 type Controller struct {
-	repo roundRepository
+	repo      repository.Repository
+	leagues   leagueSource
+	games     gameSource
+	scenarios scenarioSource
+}
+
+// New creates a new instance of the round controller. The leagues are used to check that the league of a round
+// exists when it is written, and that its points limit keeps rising in an escalation league, those checks are
+// skipped if leagues is nil. The games are used to expand rounds, rounds
+// are returned as they are stored if games is nil. The scenarios are used to check the scenario of a round exists
+// and to fill in its name, both are skipped if scenarios is nil.
+func New(repo repository.Repository, leagues leagueSource, games gameSource, scenarios scenarioSource) *Controller {
+	return &Controller{repo: repo, leagues: leagues, games: games, scenarios: scenarios}
+}
+
+// GetByID returns the round with the given id, or svcerrors.ErrNotFound if no round with that id exists
+func (c *Controller) GetByID(ctx context.Context, id rounds.RoundID) (*model.Round, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+
+	sr, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ShallowToRound(sr), nil
 }
 
-// New creates a new instance of the round controller.
-func New(repo roundRepository) *Controller {
-	return &Controller{repo: repo}
+// Create persists a new round instance to the repository and returns the round with an assigned ID. A
+// svcerrors.ErrModelInvalid is returned if the round's league or scenario does not exist or its points limit falls in
+// an escalation league, and svcerrors.ErrConflict if the league already has a round with the same number.
+func (c *Controller) Create(ctx context.Context, r *model.Round) (*model.Round, error) {
+	if r == nil {
+		return nil, fmt.Errorf("the round to be created cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	} else if err := c.checkLeague(ctx, r); err != nil {
+		return nil, err
+	} else if err := c.checkScenario(ctx, r); err != nil {
+		return nil, err
+	}
+
+	sr, err := c.repo.Create(ctx, DeepToShallow(r))
+	if err != nil {
+		return nil, err
+	}
+	return ShallowToRound(sr), nil
+}
+
+// Replace updates an existing round in the repository with the provided round. A svcerrors.ErrModelInvalid is
+// returned if the round's league or scenario does not exist or its points limit falls in an escalation league, and
+// svcerrors.ErrConflict if another round of the league has the same number.
+func (c *Controller) Replace(ctx context.Context, r *model.Round) (*model.Round, error) {
+	if r == nil {
+		return nil, fmt.Errorf("the round to be replaced cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	} else if err := c.checkLeague(ctx, r); err != nil {
+		return nil, err
+	} else if err := c.checkScenario(ctx, r); err != nil {
+		return nil, err
+	}
+
+	sr, err := c.repo.Replace(ctx, DeepToShallow(r))
+	if err != nil {
+		return nil, err
+	}
+	return ShallowToRound(sr), nil
 }
 
-// Get returns the round with the given id, or svcerrors.NotFound if no round with that id exists
-func (c *Controller) Get(ctx context.Context, id int) (*model.Round, error) {
-	return c.repo.Get(ctx, id)
+// DeleteByID removes the round with the given id from the repository. Returns true if the round was found and
+// deleted, false otherwise. This is an idempotent operation.
+func (c *Controller) DeleteByID(ctx context.Context, id rounds.RoundID) (bool, error) {
+	if id == "" {
+		return false, svcerrors.ErrInvalidID
+	}
+	return c.repo.DeleteByID(ctx, id)
+}
+
+// List returns one page of the rounds matching the filters in the query, in a stable order suitable for paging
+// with the returned cursor. A svcerrors.ErrInvalidQuery is returned if the query cannot be used.
+func (c *Controller) List(ctx context.Context, q model.RoundQuery) (*model.RoundList, error) {
+	if q.Limit < 0 {
+		return nil, fmt.Errorf("the page limit cannot be negative, got %d. Source: %w", q.Limit, svcerrors.ErrInvalidQuery)
+	}
+
+	page, err := c.repo.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &model.RoundList{Rounds: make([]model.Round, 0, len(page.Rounds)), NextCursor: page.NextCursor}
+	for i := range page.Rounds {
+		l.Rounds = append(l.Rounds, *ShallowToRound(&page.Rounds[i]))
+	}
+	return l, nil
+}
+
+// ExpandGames returns the round with each of its games fetched from the games service, so the games are current
+// rather than the copies held with the round. Games which cannot be fetched are listed in the MissingGames of the
+// returned round instead of failing the call.
+func (c *Controller) ExpandGames(ctx context.Context, r *model.Round) (*model.Round, error) {
+	if r == nil {
+		return nil, fmt.Errorf("the round to be expanded cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	} else if c.games == nil {
+		return r, nil
+	}
+
+	expanded, err := ShallowToDeep(ctx, DeepToShallow(r), c.games, DefaultHydrationParallelism)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand the games of round '%s': %w", r.ID, err)
+	}
+	return expanded, nil
+}
+
+// checkLeague makes sure the league the round belongs to exists, and in an escalation league that the round's points
+// limit fits between those of the rounds around it. A round without a league is left for the repository to reject
+// along with anything else wrong with it.
+func (c *Controller) checkLeague(ctx context.Context, r *model.Round) error {
+	if c.leagues == nil || r.LeagueID == "" {
+		return nil
+	}
+
+	l, err := c.leagues.GetByID(ctx, r.LeagueID)
+	if errors.Is(err, svcerrors.ErrNotFound) {
+		return fmt.Errorf("round %w: the league '%s' does not exist", svcerrors.ErrModelInvalid, r.LeagueID)
+	} else if err != nil {
+		return fmt.Errorf("unable to check that league '%s' exists: %w", r.LeagueID, err)
+	}
+	if l.IsEscalation() && r.PointsLimit > 0 {
+		return c.checkEscalation(ctx, r)
+	}
+	return nil
+}
+
+// checkEscalation makes sure the points limit of a round of an escalation league is not lower than the limit of an
+// earlier round of the league, or higher than the limit of a later one. Rounds without a limit of their own are
+// skipped.
+func (c *Controller) checkEscalation(ctx context.Context, r *model.Round) error {
+	problems := []string{}
+	q := model.RoundQuery{LeagueID: r.LeagueID, Limit: model.MaxListLimit}
+	for {
+		page, err := c.repo.List(ctx, q)
+		if err != nil {
+			return fmt.Errorf("unable to read the rounds of league '%s': %w", r.LeagueID, err)
+		}
+		for _, o := range page.Rounds {
+			if o.ID == r.ID || o.PointsLimit == 0 {
+				continue
+			} else if o.Number < r.Number && o.PointsLimit > r.PointsLimit {
+				problems = append(problems, fmt.Sprintf("PointsLimit=%d is lower than the %d of round %d", r.PointsLimit, o.PointsLimit, o.Number))
+			} else if o.Number > r.Number && o.PointsLimit < r.PointsLimit {
+				problems = append(problems, fmt.Sprintf("PointsLimit=%d is higher than the %d of round %d", r.PointsLimit, o.PointsLimit, o.Number))
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("round %w: the limit cannot fall in escalation league '%s', %s", svcerrors.ErrModelInvalid, r.LeagueID, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// checkScenario makes sure the scenario picked for the round is in the catalog, and fills in the round's
+// ScenarioName from it so the two always agree
+func (c *Controller) checkScenario(ctx context.Context, r *model.Round) error {
+	if c.scenarios == nil || r.ScenarioID == "" {
+		return nil
+	}
+
+	s, err := c.scenarios.GetByID(ctx, r.ScenarioID)
+	if errors.Is(err, svcerrors.ErrNotFound) {
+		return fmt.Errorf("round %w: the scenario '%s' does not exist", svcerrors.ErrModelInvalid, r.ScenarioID)
+	} else if err != nil {
+		return fmt.Errorf("unable to check that scenario '%s' exists: %w", r.ScenarioID, err)
+	}
+	r.ScenarioName = s.Name
+	return nil
 }
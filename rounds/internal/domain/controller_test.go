@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	gamesheader "github.com/rpatton4/mesbg-league/games/pkg"
+	games "github.com/rpatton4/mesbg-league/games/pkg/model"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	leaguesmodel "github.com/rpatton4/mesbg-league/leagues/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository/memory"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	scenariosgateway "github.com/rpatton4/mesbg-league/scenarios/pkg/gateway"
+	"slices"
+	"testing"
+)
+
+// Sort of in passing this also tests that the controller meets the SingleController interface spec
+var _ SingleController = (*Controller)(nil)
+
+// stubLeagues is a leagueSource which knows the leagues with the given IDs
+type stubLeagues map[leagues.LeagueID]*leaguesmodel.League
+
+func (s stubLeagues) GetByID(_ context.Context, id leagues.LeagueID) (*leaguesmodel.League, error) {
+	l, ok := s[id]
+	if !ok {
+		return nil, svcerrors.ErrNotFound
+	}
+	found := *l
+	found.ID = id
+	return &found, nil
+}
+
+func TestControllerCreateChecksLeague(t *testing.T) {
+	c := New(memory.New(), stubLeagues{"1": {}}, nil, nil)
+
+	if _, err := c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 1}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := c.Create(context.Background(), &model.Round{LeagueID: "2", Number: 1}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for a round of an unknown league, got %v", err)
+	}
+	if _, err := c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 1}); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a repeated round number, got %v", err)
+	}
+	if _, err := c.Create(context.Background(), nil); !errors.Is(err, svcerrors.ErrModelMissing) {
+		t.Errorf("Expected ErrModelMissing for a nil round, got %v", err)
+	}
+}
+
+func TestControllerChecksScenario(t *testing.T) {
+	scenarios, err := scenariosgateway.NewDefaultInProcessGateway()
+	if err != nil {
+		t.Fatalf("Unable to load the scenarios: %v", err)
+	}
+	c := New(memory.New(), nil, nil, scenarios)
+
+	// The name always comes from the catalog when the scenario is picked by ID
+	r, err := c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 1, ScenarioID: "hold-ground", ScenarioName: "Hold"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if r.ScenarioID != "hold-ground" || r.ScenarioName != "Hold Ground" {
+		t.Errorf("Expected the scenario name to be filled in from the catalog, got %+v", r)
+	}
+
+	if _, err := c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 2, ScenarioID: "no-such-scenario"}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for an unknown scenario, got %v", err)
+	}
+	if _, err := c.Replace(context.Background(), &model.Round{ID: r.ID, LeagueID: "1", Number: 1, ScenarioID: "no-such-scenario"}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid replacing with an unknown scenario, got %v", err)
+	}
+}
+
+func TestControllerReplaceChecksNumber(t *testing.T) {
+	c := New(memory.New(), stubLeagues{"1": {}}, nil, nil)
+
+	first, err := c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 2}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Replacing a round with its own number is fine, taking the number of another round of the league is not
+	if _, err = c.Replace(context.Background(), &model.Round{ID: first.ID, LeagueID: "1", Number: 1, ScenarioName: "Reconnoitre"}); err != nil {
+		t.Errorf("Expected no error keeping the same number, got %v", err)
+	}
+	if _, err = c.Replace(context.Background(), &model.Round{ID: first.ID, LeagueID: "1", Number: 2}); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict taking the number of another round, got %v", err)
+	}
+	if _, err = c.Replace(context.Background(), &model.Round{ID: first.ID, LeagueID: "2", Number: 1}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid moving the round to an unknown league, got %v", err)
+	}
+}
+
+func TestControllerChecksEscalation(t *testing.T) {
+	c := New(memory.New(), stubLeagues{"1": {Format: leaguesmodel.FormatEscalation}, "2": {}}, nil, nil)
+
+	first, err := c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 1, PointsLimit: 300})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 3, PointsLimit: 600}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err = c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 2, PointsLimit: 250}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for a limit lower than an earlier round, got %v", err)
+	}
+	if _, err = c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 2, PointsLimit: 700}); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for a limit higher than a later round, got %v", err)
+	}
+	if _, err = c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 2, PointsLimit: 450}); err != nil {
+		t.Errorf("Expected a limit between the rounds around it to be fine, got %v", err)
+	}
+	if _, err = c.Replace(context.Background(), &model.Round{ID: first.ID, LeagueID: "1", Number: 1, PointsLimit: 350}); err != nil {
+		t.Errorf("Expected a round to be checked against the other rounds only, got %v", err)
+	}
+
+	// Only escalation leagues need the limit to rise
+	if _, err = c.Create(context.Background(), &model.Round{LeagueID: "2", Number: 1, PointsLimit: 600}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = c.Create(context.Background(), &model.Round{LeagueID: "2", Number: 2, PointsLimit: 300}); err != nil {
+		t.Errorf("Expected a falling limit to be fine outside an escalation league, got %v", err)
+	}
+}
+
+func TestControllerStoresGameIDsOnly(t *testing.T) {
+	gs := &stubGames{known: map[gamesheader.GameID]bool{"7": true, "8": true}}
+	c := New(memory.New(), nil, gs, nil)
+
+	// A round written with copies of its games keeps only their IDs
+	r, err := c.Create(context.Background(), &model.Round{LeagueID: "1", Number: 1,
+		Games: []games.Game{{ID: "7", Side1TotalVictoryPoints: 12}}, GameIDs: []gamesheader.GameID{"8"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stored, err := c.GetByID(context.Background(), r.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(stored.Games) != 0 || !slices.Equal(stored.GameIDs, []gamesheader.GameID{"8", "7"}) {
+		t.Errorf("Expected the round to refer to its games by ID only, got %+v", stored)
+	}
+
+	// Expanding fetches the games as they are now, not as they were written
+	expanded, err := c.ExpandGames(context.Background(), stored)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(expanded.Games) != 2 || expanded.Games[1].ID != "7" || expanded.Games[1].Side1TotalVictoryPoints != 0 {
+		t.Errorf("Expected both games fetched from the games service, got %+v", expanded.Games)
+	}
+}
@@ -1,11 +1,28 @@
 package domain
 
 import (
+	"context"
+	"errors"
+	gamesheader "github.com/rpatton4/mesbg-league/games/pkg"
+	games "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
 	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"slices"
+	"sync"
 )
 
+// DefaultHydrationParallelism is the number of games fetched at the same time while hydrating a round
+const DefaultHydrationParallelism = 8
+
+// gameSource is the part of the games gateway needed to hydrate a round
+type gameSource interface {
+	GetByID(ctx context.Context, id gamesheader.GameID) (*games.Game, error)
+}
+
 // DeepToShallow replaces any entities embedded in the Round with their IDs, returning a ShallowRound.
-// The use case is meant to be for persisting a Round without including the data which is not owned by the Round,
+// The use case is meant to be for persisting a Round without including the data which is not owned by the Round.
+// The game IDs of the round come first, followed by any embedded or missing games not already among them, so a
+// round written with either its IDs or its games (or both) keeps all of them.
 func DeepToShallow(r *model.Round) *model.ShallowRound {
 	if r == nil {
 		return nil
@@ -15,12 +32,108 @@ func DeepToShallow(r *model.Round) *model.ShallowRound {
 		ID:           r.ID,
 		LeagueID:     r.LeagueID,
 		Number:       r.Number,
+		ScenarioID:   r.ScenarioID,
 		ScenarioName: r.ScenarioName,
+		PointsLimit:  r.PointsLimit,
+		Date:         r.Date,
 	}
 
+	seen := map[gamesheader.GameID]bool{}
+	add := func(id gamesheader.GameID) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			sr.GameIDs = append(sr.GameIDs, id)
+		}
+	}
+	for _, id := range r.GameIDs {
+		add(id)
+	}
 	for _, g := range r.Games {
-		sr.GameIDs = append(sr.GameIDs, g.ID)
+		add(g.ID)
+	}
+	for _, m := range r.MissingGames {
+		add(m.GameID)
 	}
 
 	return sr
 }
+
+// ShallowToRound converts a ShallowRound to a Round without fetching its games, the Round only refers to its games
+// by ID in the same way as the ShallowRound does
+func ShallowToRound(sr *model.ShallowRound) *model.Round {
+	if sr == nil {
+		return nil
+	}
+
+	return &model.Round{
+		ID:           sr.ID,
+		LeagueID:     sr.LeagueID,
+		Number:       sr.Number,
+		ScenarioID:   sr.ScenarioID,
+		ScenarioName: sr.ScenarioName,
+		PointsLimit:  sr.PointsLimit,
+		Date:         sr.Date,
+		GameIDs:      slices.Clone(sr.GameIDs),
+	}
+}
+
+// ShallowToDeep is the reverse of DeepToShallow, fetching each of the round's games from the games service to
+// return a full Round. Up to parallelism games are fetched at once (DefaultHydrationParallelism if it is not
+// positive). A game which cannot be fetched is reported in the MissingGames of the round rather than failing the
+// whole round, only the context ending before every game has been fetched returns an error.
+func ShallowToDeep(ctx context.Context, sr *model.ShallowRound, gs gameSource, parallelism int) (*model.Round, error) {
+	if sr == nil {
+		return nil, nil
+	}
+	if parallelism <= 0 {
+		parallelism = DefaultHydrationParallelism
+	}
+
+	r := ShallowToRound(sr)
+
+	// Each fetch writes only its own slot, so the games keep the order of the IDs without any locking
+	fetched := make([]*games.Game, len(sr.GameIDs))
+	failed := make([]error, len(sr.GameIDs))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+launch:
+	for i, id := range sr.GameIDs {
+		select {
+		case <-ctx.Done():
+			break launch
+		case sem <- struct{}{}:
+		}
+		// A slot may have been freed by a fetch giving up on the same cancelled context
+		if ctx.Err() != nil {
+			break launch
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fetched[i], failed[i] = gs.GetByID(ctx, id)
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, id := range sr.GameIDs {
+		if failed[i] == nil && fetched[i] != nil {
+			r.Games = append(r.Games, *fetched[i])
+			continue
+		}
+
+		reason := "not found"
+		if failed[i] != nil && !errors.Is(failed[i], svcerrors.ErrNotFound) {
+			reason = failed[i].Error()
+		}
+		r.MissingGames = append(r.MissingGames, model.MissingGame{GameID: id, Reason: reason})
+	}
+
+	return r, nil
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gamesheader "github.com/rpatton4/mesbg-league/games/pkg"
+	games "github.com/rpatton4/mesbg-league/games/pkg/model"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubGames is a gameSource which knows the games with the given IDs, failing with the matching error for any ID
+// in failures. It records the most games fetched at the same time.
+type stubGames struct {
+	known    map[gamesheader.GameID]bool
+	failures map[gamesheader.GameID]error
+	delay    time.Duration
+
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+	calls   atomic.Int32
+}
+
+func (s *stubGames) GetByID(ctx context.Context, id gamesheader.GameID) (*games.Game, error) {
+	s.calls.Add(1)
+	s.mu.Lock()
+	s.active++
+	s.maxSeen = max(s.maxSeen, s.active)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(s.delay):
+	}
+
+	if err := s.failures[id]; err != nil {
+		return nil, err
+	} else if !s.known[id] {
+		return nil, svcerrors.ErrNotFound
+	}
+	return &games.Game{ID: id}, nil
+}
+
+func TestShallowToDeepKeepsGameOrder(t *testing.T) {
+	gs := &stubGames{known: map[gamesheader.GameID]bool{}}
+	sr := &model.ShallowRound{ID: "1", LeagueID: "1", Number: 2, ScenarioName: "Domination", PointsLimit: 450, Date: "2025-09-08"}
+	for i := 0; i < 20; i++ {
+		id := gamesheader.GameID(fmt.Sprint(i))
+		gs.known[id] = true
+		sr.GameIDs = append(sr.GameIDs, id)
+	}
+
+	r, err := ShallowToDeep(context.Background(), sr, gs, 4)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if r.ID != "1" || r.Number != 2 || r.ScenarioName != "Domination" || r.PointsLimit != 450 || r.Date != "2025-09-08" {
+		t.Errorf("Expected the round fields to be copied, got %+v", r)
+	}
+	if len(r.Games) != 20 || len(r.MissingGames) != 0 {
+		t.Fatalf("Expected all 20 games and none missing, got %d and %d", len(r.Games), len(r.MissingGames))
+	}
+	for i, g := range r.Games {
+		if g.ID != sr.GameIDs[i] {
+			t.Errorf("Expected game %d to be '%s', got '%s'", i, sr.GameIDs[i], g.ID)
+		}
+	}
+}
+
+func TestShallowToDeepBoundsParallelism(t *testing.T) {
+	gs := &stubGames{known: map[gamesheader.GameID]bool{}, delay: 5 * time.Millisecond}
+	sr := &model.ShallowRound{ID: "1"}
+	for i := 0; i < 12; i++ {
+		id := gamesheader.GameID(fmt.Sprint(i))
+		gs.known[id] = true
+		sr.GameIDs = append(sr.GameIDs, id)
+	}
+
+	if _, err := ShallowToDeep(context.Background(), sr, gs, 3); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gs.maxSeen > 3 {
+		t.Errorf("Expected at most 3 games fetched at once, saw %d", gs.maxSeen)
+	}
+	if gs.maxSeen < 2 {
+		t.Errorf("Expected the games to be fetched concurrently, saw %d at most", gs.maxSeen)
+	}
+}
+
+func TestShallowToDeepReportsMissingGames(t *testing.T) {
+	gs := &stubGames{
+		known:    map[gamesheader.GameID]bool{"1": true, "3": true},
+		failures: map[gamesheader.GameID]error{"4": errors.New("games service responded 500")},
+	}
+	sr := &model.ShallowRound{ID: "1", GameIDs: []gamesheader.GameID{"1", "2", "3", "4"}}
+
+	r, err := ShallowToDeep(context.Background(), sr, gs, 0)
+	if err != nil {
+		t.Fatalf("Expected a partial result rather than an error, got %v", err)
+	}
+	if len(r.Games) != 2 || r.Games[0].ID != "1" || r.Games[1].ID != "3" {
+		t.Errorf("Expected games 1 and 3, got %+v", r.Games)
+	}
+	if len(r.MissingGames) != 2 || r.MissingGames[0].GameID != "2" || r.MissingGames[0].Reason != "not found" ||
+		r.MissingGames[1].GameID != "4" || r.MissingGames[1].Reason == "" {
+		t.Errorf("Expected games 2 and 4 to be reported missing with reasons, got %+v", r.MissingGames)
+	}
+
+	// The missing games are kept when the round is written back
+	if sr := DeepToShallow(r); len(sr.GameIDs) != 4 {
+		t.Errorf("Expected all 4 game IDs after converting back, got %v", sr.GameIDs)
+	}
+}
+
+func TestShallowToDeepStopsWhenCancelled(t *testing.T) {
+	gs := &stubGames{known: map[gamesheader.GameID]bool{}, delay: time.Second}
+	sr := &model.ShallowRound{ID: "1"}
+	for i := 0; i < 10; i++ {
+		sr.GameIDs = append(sr.GameIDs, gamesheader.GameID(fmt.Sprint(i)))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := ShallowToDeep(ctx, sr, gs, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline error, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected hydration to stop soon after the context ended, took %v", time.Since(start))
+	}
+	if calls := gs.calls.Load(); calls > 2 {
+		t.Errorf("Expected no further games to be fetched after cancelling, got %d calls", calls)
+	}
+}
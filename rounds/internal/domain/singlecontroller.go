@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+)
+
+//go:generate mockgen --destination ./mocks/controller.go github.com/rpatton4/mesbg-league/rounds/internal/domain SingleController
+type SingleController interface {
+	// GetByID returns the round with the given id, or a svcerrors.ErrNotFound if no round with that id exists
+	GetByID(ctx context.Context, id rounds.RoundID) (*model.Round, error)
+
+	// Create persists a new round instance to the repository and returns the round with an assigned ID.
+	// A generic error is returned if the round to create is missing, while specific validation errors are
+	// passed along if the round is invalid in some way.
+	Create(ctx context.Context, r *model.Round) (*model.Round, error)
+
+	// Replace updates an existing round in the repository with the provided round.
+	// A generic error is returned if the round to replace is not present in the data store.
+	Replace(ctx context.Context, r *model.Round) (*model.Round, error)
+
+	// DeleteByID removes the round with the given id from the repository. Returns true if the round was found and
+	// deleted, false otherwise. This is an idempotent operation.
+	DeleteByID(ctx context.Context, id rounds.RoundID) (bool, error)
+
+	// List returns one page of the rounds matching the filters in the query, in a stable order suitable for paging
+	// with the returned cursor. A svcerrors.ErrInvalidQuery is returned if the query cannot be used.
+	List(ctx context.Context, q model.RoundQuery) (*model.RoundList, error)
+
+	// ExpandGames returns the round with each of its games fetched from the games service. Games which cannot be
+	// fetched are listed in the MissingGames of the returned round rather than failing the call.
+	ExpandGames(ctx context.Context, r *model.Round) (*model.Round, error)
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/danielgtaylor/huma/v2"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/rounds/internal/domain"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"log/slog"
+)
+
+// HumaHandler defines the HTTP handler (adapter) for Rounds operations received via HTTP(S).
+type HumaHandler struct {
+	ctrl domain.SingleController
+}
+
+// <editor-fold desc="I/O Struct Definitions">
+
+// Huma requires structs for both the input and output of each function registered as a handler for an HTTP operation.
+// See the games service HumaHandler for the conventions followed here.
+// Please keep the structs organized, with request and then response for any operation together
+
+// GetByIDRequest defines the input for the GetByID operation.
+type GetByIDRequest struct {
+	// ID is the unique identifier for the round to retrieve, taken from the path /rounds/{id}
+	ID rounds.RoundID `path:"id" example:"12" doc:"The unique identifier for the round to retrieve"`
+
+	// Expand set to "games" fetches the current games of the round from the games service
+	Expand string `query:"expand" enum:"games" doc:"Set to games to include the current games of the round, fetched from the games service"`
+}
+
+// GetByIDResponse defines the output for the GetByID operation.
+type GetByIDResponse struct {
+	// Body holds the round with the requested ID, Huma will marshall this to JSON for the HTTP response
+	Body model.Round
+}
+
+// PostRequest defines the input for the Post operation, which creates a new round.
+type PostRequest struct {
+	// Body holds the info for the round to be created
+	Body *model.Round
+}
+
+// PostResponse defines the output for the Post operation.
+type PostResponse struct {
+	// Body holds the newly created round, including its assigned ID
+	Body model.Round
+}
+
+// PutRequest defines the input for the Put operation, which replaces a round.
+type PutRequest struct {
+	// ID is the unique identifier for the round to update, taken from the path /rounds/{id}
+	ID rounds.RoundID `path:"id" example:"12" doc:"The unique identifier for the round to update"`
+
+	// Body holds the Round model to replace the round with the ID from the path
+	Body *model.Round
+}
+
+// PutResponse defines the output for the Put operation.
+type PutResponse struct {
+	// Body holds the updated round
+	Body model.Round
+}
+
+// DeleteRequest defines the input for the Delete operation.
+type DeleteRequest struct {
+	// ID is the unique identifier for the round to delete, taken from the path /rounds/{id}
+	ID rounds.RoundID `path:"id" example:"12" doc:"The unique identifier for the round to delete"`
+}
+
+// ListRequest defines the input for the List operation, all of the filters are optional and are taken from the query
+type ListRequest struct {
+	// LeagueID limits the results to rounds of the given league
+	LeagueID string `query:"leagueId" example:"1" doc:"Only return rounds of this league"`
+
+	// Cursor is the value of nextCursor from a previous page, used to continue the listing
+	Cursor string `query:"cursor" doc:"The nextCursor value from the previous page, omit to start at the first page"`
+
+	// Limit is the maximum number of rounds to return in the page
+	Limit int `query:"limit" default:"50" minimum:"1" maximum:"200" doc:"The maximum number of rounds to return in one page"`
+
+	// Expand set to "games" fetches the current games of each round from the games service
+	Expand string `query:"expand" enum:"games" doc:"Set to games to include the current games of each round, fetched from the games service"`
+}
+
+// ListForLeagueRequest defines the input for the ListForLeague operation, which lists the rounds of one league
+type ListForLeagueRequest struct {
+	// LeagueID is the league whose rounds are listed, taken from the path /leagues/{leagueId}/rounds
+	LeagueID leagues.LeagueID `path:"leagueId" example:"1" doc:"The unique identifier for the league whose rounds are listed"`
+
+	// Cursor is the value of nextCursor from a previous page, used to continue the listing
+	Cursor string `query:"cursor" doc:"The nextCursor value from the previous page, omit to start at the first page"`
+
+	// Limit is the maximum number of rounds to return in the page
+	Limit int `query:"limit" default:"50" minimum:"1" maximum:"200" doc:"The maximum number of rounds to return in one page"`
+
+	// Expand set to "games" fetches the current games of each round from the games service
+	Expand string `query:"expand" enum:"games" doc:"Set to games to include the current games of each round, fetched from the games service"`
+}
+
+// ListResponse defines the output for both of the List operations.
+type ListResponse struct {
+	// Body holds the page of rounds and the cursor for the next page
+	Body model.RoundList
+}
+
+//</editor-fold>
+
+// expandGames is the value of the expand query parameter which fetches the games of the rounds
+const expandGames = "games"
+
+// NewHumaHandler creates a new instance of the HTTP handler for round operations.
+func NewHumaHandler(c domain.SingleController) *HumaHandler {
+	return &HumaHandler{ctrl: c}
+}
+
+// GetByID queries the controller for the round with the ID taken from the path, returns it if found
+// 404 is returned if no such round exists
+// 400 is returned if the round ID is invalid
+func (h *HumaHandler) GetByID(ctx context.Context, req *GetByIDRequest) (*GetByIDResponse, error) {
+	slog.Debug("GetByID called", "roundID", req.ID)
+
+	r, err := h.ctrl.GetByID(ctx, req.ID)
+	if err == nil && req.Expand == expandGames {
+		r, err = h.ctrl.ExpandGames(ctx, r)
+	}
+	if err != nil {
+		return nil, roundError("get", req.ID, err)
+	}
+
+	return &GetByIDResponse{
+		Body: *r,
+	}, nil
+}
+
+// Post reads the round JSON from the HTTP call and sends it on to the controller to create the round
+// 400 is returned if the round is missing or invalid, including when its league does not exist
+// 409 is returned if the league already has a round with the same number
+func (h *HumaHandler) Post(ctx context.Context, req *PostRequest) (*PostResponse, error) {
+	slog.Debug("Post called", "PostRequest Body", req.Body)
+
+	r, err := h.ctrl.Create(ctx, req.Body)
+	if err != nil {
+		return nil, roundError("create", "", err)
+	}
+
+	slog.Debug("Created round", "round", r)
+	return &PostResponse{
+		Body: *r,
+	}, nil
+}
+
+// Put reads the round JSON from the HTTP call and sends it on to the controller to fully update the round with
+// the ID from the path. The ID in the body may be left out, but if it is given it has to match the path.
+// 400 is returned if the round is missing or invalid
+// 404 is returned if no such round exists
+// 409 is returned if another round of the league has the same number
+func (h *HumaHandler) Put(ctx context.Context, req *PutRequest) (*PutResponse, error) {
+	slog.Debug("Put called", "roundID", req.ID, "PutRequest Body", req.Body)
+
+	if req.Body != nil {
+		if req.Body.ID == "" {
+			req.Body.ID = req.ID
+		} else if req.Body.ID != req.ID {
+			return nil, huma.Error400BadRequest("the round ID in the body '" + string(req.Body.ID) + "' does not match the path '" + string(req.ID) + "'")
+		}
+	}
+
+	r, err := h.ctrl.Replace(ctx, req.Body)
+	if err != nil {
+		return nil, roundError("update", req.ID, err)
+	}
+
+	slog.Debug("Updated round", "round", r)
+	return &PutResponse{
+		Body: *r,
+	}, nil
+}
+
+// Delete deletes the round with the ID from the path.
+// 404 is returned if no such round exists
+func (h *HumaHandler) Delete(ctx context.Context, req *DeleteRequest) (*struct{}, error) {
+	slog.Debug("Delete called", "roundID", req.ID)
+
+	if _, err := h.ctrl.DeleteByID(ctx, req.ID); err != nil {
+		if errors.Is(err, svcerrors.ErrInvalidID) {
+			err = svcerrors.ErrNotFound
+		}
+		return nil, roundError("delete", req.ID, err)
+	}
+	return nil, nil
+}
+
+// List queries the controller for a page of rounds matching the filters from the query string
+// 400 is returned if the cursor is invalid
+func (h *HumaHandler) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	slog.Debug("List called", "leagueID", req.LeagueID, "cursor", req.Cursor)
+	return h.list(ctx, model.RoundQuery{LeagueID: leagues.LeagueID(req.LeagueID), Cursor: req.Cursor, Limit: req.Limit}, req.Expand)
+}
+
+// ListForLeague queries the controller for a page of the rounds of the league with the ID from the path
+// 400 is returned if the cursor is invalid
+func (h *HumaHandler) ListForLeague(ctx context.Context, req *ListForLeagueRequest) (*ListResponse, error) {
+	slog.Debug("ListForLeague called", "leagueID", req.LeagueID, "cursor", req.Cursor)
+	return h.list(ctx, model.RoundQuery{LeagueID: req.LeagueID, Cursor: req.Cursor, Limit: req.Limit}, req.Expand)
+}
+
+func (h *HumaHandler) list(ctx context.Context, q model.RoundQuery, expand string) (*ListResponse, error) {
+	l, err := h.ctrl.List(ctx, q)
+	if err != nil {
+		return nil, roundError("list", "", err)
+	}
+
+	if expand == expandGames {
+		for i := range l.Rounds {
+			r, err := h.ctrl.ExpandGames(ctx, &l.Rounds[i])
+			if err != nil {
+				return nil, roundError("list", l.Rounds[i].ID, err)
+			}
+			l.Rounds[i] = *r
+		}
+	}
+
+	return &ListResponse{
+		Body: *l,
+	}, nil
+}
+
+// roundError maps an error from the controller to the HTTP response, so that every operation reports errors with
+// the same status codes
+func roundError(action string, id rounds.RoundID, err error) error {
+	slog.Error("Unable to "+action+" the round", "roundID", id, "error", err)
+	if errors.Is(err, svcerrors.ErrNotFound) {
+		return huma.Error404NotFound("No such round exists")
+	} else if errors.Is(err, svcerrors.ErrConflict) {
+		return huma.Error409Conflict("unable to " + action + " the round: " + err.Error())
+	} else if errors.Is(err, svcerrors.ErrInvalidID) || errors.Is(err, svcerrors.ErrModelMissing) ||
+		errors.Is(err, svcerrors.ErrModelInvalid) || errors.Is(err, svcerrors.ErrInvalidQuery) {
+		return huma.Error400BadRequest("client sent an invalid request to " + action + " the round: " + err.Error())
+	}
+	return huma.Error500InternalServerError("error while trying to " + action + " the round: " + err.Error())
+}
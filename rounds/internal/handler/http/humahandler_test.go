@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	mock_domain "github.com/rpatton4/mesbg-league/rounds/internal/domain/mocks"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"go.uber.org/mock/gomock"
+	nethttp "net/http"
+	"testing"
+)
+
+func TestHumaHandlerMockedGetByID(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_domain.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	mockController.EXPECT().GetByID(gomock.Any(), rounds.RoundID("1")).Return(&model.Round{ID: "1", LeagueID: "1", Number: 1}, nil).Times(1)
+	mockController.EXPECT().GetByID(gomock.Any(), rounds.RoundID("999")).Return(nil, svcerrors.ErrNotFound).Times(1)
+
+	res, err := handler.GetByID(context.Background(), &GetByIDRequest{ID: "1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Body.ID != "1" {
+		t.Errorf("expected round ID '1', got '%s'", res.Body.ID)
+	}
+
+	_, err = handler.GetByID(context.Background(), &GetByIDRequest{ID: "999"})
+	assertStatus(t, err, nethttp.StatusNotFound)
+}
+
+func TestHumaHandlerMockedPost(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_domain.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	valid := &model.Round{LeagueID: "1", Number: 1}
+	invalid := &model.Round{LeagueID: "1"}
+	duplicate := &model.Round{LeagueID: "1", Number: 2}
+	mockController.EXPECT().Create(gomock.Any(), valid).Return(&model.Round{ID: "1", LeagueID: "1", Number: 1}, nil).Times(1)
+	mockController.EXPECT().Create(gomock.Any(), invalid).Return(nil, fmt.Errorf("round %w: Number=0 must be 1 or more", svcerrors.ErrModelInvalid)).Times(1)
+	mockController.EXPECT().Create(gomock.Any(), duplicate).Return(nil, fmt.Errorf("round 2 already exists: %w", svcerrors.ErrConflict)).Times(1)
+
+	res, err := handler.Post(context.Background(), &PostRequest{Body: valid})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Body.ID != "1" {
+		t.Errorf("expected the assigned round ID '1', got '%s'", res.Body.ID)
+	}
+
+	_, err = handler.Post(context.Background(), &PostRequest{Body: invalid})
+	assertStatus(t, err, nethttp.StatusBadRequest)
+
+	_, err = handler.Post(context.Background(), &PostRequest{Body: duplicate})
+	assertStatus(t, err, nethttp.StatusConflict)
+}
+
+func TestHumaHandlerMockedPut(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_domain.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	// The ID from the path is used when the body does not have one
+	mockController.EXPECT().Replace(gomock.Any(), &model.Round{ID: "1", LeagueID: "1", Number: 3}).Return(&model.Round{ID: "1", LeagueID: "1", Number: 3}, nil).Times(1)
+	mockController.EXPECT().Replace(gomock.Any(), &model.Round{ID: "999", LeagueID: "1", Number: 3}).Return(nil, svcerrors.ErrNotFound).Times(1)
+
+	res, err := handler.Put(context.Background(), &PutRequest{ID: "1", Body: &model.Round{LeagueID: "1", Number: 3}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Body.Number != 3 {
+		t.Errorf("expected the updated round, got %+v", res.Body)
+	}
+
+	_, err = handler.Put(context.Background(), &PutRequest{ID: "999", Body: &model.Round{LeagueID: "1", Number: 3}})
+	assertStatus(t, err, nethttp.StatusNotFound)
+
+	// A body for a different round is rejected without reaching the controller
+	_, err = handler.Put(context.Background(), &PutRequest{ID: "1", Body: &model.Round{ID: "2", LeagueID: "1", Number: 3}})
+	assertStatus(t, err, nethttp.StatusBadRequest)
+}
+
+func TestHumaHandlerMockedDelete(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_domain.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	mockController.EXPECT().DeleteByID(gomock.Any(), rounds.RoundID("1")).Return(true, nil).Times(1)
+	mockController.EXPECT().DeleteByID(gomock.Any(), rounds.RoundID("999")).Return(false, svcerrors.ErrNotFound).Times(1)
+
+	if _, err := handler.Delete(context.Background(), &DeleteRequest{ID: "1"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err := handler.Delete(context.Background(), &DeleteRequest{ID: "999"})
+	assertStatus(t, err, nethttp.StatusNotFound)
+}
+
+func TestHumaHandlerMockedList(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_domain.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	mockController.EXPECT().List(gomock.Any(), model.RoundQuery{LeagueID: "1", Limit: 10}).Return(&model.RoundList{Rounds: []model.Round{{ID: "1"}}}, nil).Times(2)
+	mockController.EXPECT().List(gomock.Any(), model.RoundQuery{Cursor: "!!", Limit: 10}).Return(nil, svcerrors.ErrInvalidQuery).Times(1)
+
+	res, err := handler.List(context.Background(), &ListRequest{LeagueID: "1", Limit: 10})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(res.Body.Rounds) != 1 {
+		t.Errorf("expected 1 round, got %d", len(res.Body.Rounds))
+	}
+
+	// Listing through the league path gives the same query as filtering by league
+	res, err = handler.ListForLeague(context.Background(), &ListForLeagueRequest{LeagueID: "1", Limit: 10})
+	if err != nil || len(res.Body.Rounds) != 1 {
+		t.Errorf("expected 1 round for the league, got %v, %v", res, err)
+	}
+
+	_, err = handler.List(context.Background(), &ListRequest{Cursor: "!!", Limit: 10})
+	assertStatus(t, err, nethttp.StatusBadRequest)
+}
+
+func TestHumaHandlerMockedExpandGames(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockController := mock_domain.NewMockSingleController(mockCtrl)
+	handler := NewHumaHandler(mockController)
+
+	stored := &model.Round{ID: "1", LeagueID: "1", Number: 1}
+	expanded := &model.Round{ID: "1", LeagueID: "1", Number: 1, MissingGames: []model.MissingGame{{GameID: "7", Reason: "not found"}}}
+	mockController.EXPECT().GetByID(gomock.Any(), rounds.RoundID("1")).Return(stored, nil).Times(2)
+	mockController.EXPECT().List(gomock.Any(), model.RoundQuery{LeagueID: "1", Limit: 10}).Return(&model.RoundList{Rounds: []model.Round{*stored}}, nil).Times(1)
+	mockController.EXPECT().ExpandGames(gomock.Any(), gomock.Any()).Return(expanded, nil).Times(2)
+
+	// Only asking to expand reaches the games
+	res, err := handler.GetByID(context.Background(), &GetByIDRequest{ID: "1"})
+	if err != nil || len(res.Body.MissingGames) != 0 {
+		t.Errorf("expected the stored round, got %v, %v", res, err)
+	}
+	res, err = handler.GetByID(context.Background(), &GetByIDRequest{ID: "1", Expand: "games"})
+	if err != nil || len(res.Body.MissingGames) != 1 {
+		t.Errorf("expected the expanded round with a missing game, got %v, %v", res, err)
+	}
+
+	list, err := handler.ListForLeague(context.Background(), &ListForLeagueRequest{LeagueID: "1", Limit: 10, Expand: "games"})
+	if err != nil || len(list.Body.Rounds) != 1 || len(list.Body.Rounds[0].MissingGames) != 1 {
+		t.Errorf("expected the expanded rounds, got %v, %v", list, err)
+	}
+}
+
+// assertStatus checks that the error from a handler is a Huma error with the given HTTP status
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var statusError huma.StatusError
+	if err == nil {
+		t.Errorf("expected an error with status %d, got nil", status)
+	} else if !errors.As(err, &statusError) || statusError.GetStatus() != status {
+		t.Errorf("expected an error with status %d, got %v", status, err)
+	}
+}
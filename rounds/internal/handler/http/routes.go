@@ -0,0 +1,16 @@
+package http
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// RegisterRoutes registers every Rounds operation of the handler with the given Huma API, so that the service and
+// anything embedding it (such as tests) expose exactly the same routes.
+func RegisterRoutes(api huma.API, handler *HumaHandler) {
+	huma.Get(api, "/rounds", handler.List)
+	huma.Get(api, "/rounds/{id}", handler.GetByID)
+	huma.Post(api, "/rounds", handler.Post)
+	huma.Put(api, "/rounds/{id}", handler.Put)
+	huma.Delete(api, "/rounds/{id}", handler.Delete)
+	huma.Get(api, "/leagues/{leagueId}/rounds", handler.ListForLeague)
+}
@@ -2,9 +2,12 @@ package memory
 
 import (
 	"context"
+	"fmt"
 	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository"
 	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
 	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"slices"
 	"strconv"
 	"sync"
 )
@@ -12,47 +15,132 @@ import (
 // Counter for Round IDs
 var roundCounter = 1
 
-// Repository defines an in-memory repository for players data
+// Repository defines an in-memory repository for rounds data
 type Repository struct {
 	sync.RWMutex
-	data map[rounds.RoundID]*model.Round
+	data map[rounds.RoundID]*model.ShallowRound
 }
 
 // New creates a new instance of the in-memory round repository.
 func New() *Repository {
-	return &Repository{data: map[rounds.RoundID]*model.Round{}}
+	return &Repository{data: map[rounds.RoundID]*model.ShallowRound{}}
 }
 
-// Get retrieves a round by ID from the in-memory repository, if no round with the given
-// ID exists, it returns svcerrors.NotFound.
-func (repo *Repository) Get(_ context.Context, id int) (*model.Round, error) {
+// GetByID retrieves a round by ID from the in-memory repository, if no round with the given
+// ID exists, it returns svcerrors.ErrNotFound.
+func (repo *Repository) GetByID(_ context.Context, id rounds.RoundID) (*model.ShallowRound, error) {
 	repo.RLock()
 	defer repo.RUnlock()
 
-	round, exists := repo.data[rounds.RoundID(strconv.Itoa(id))]
+	round, exists := repo.data[id]
 	if !exists {
-		return nil, svcerrors.ErrNotFound
+		return nil, fmt.Errorf("the round with the given ID '%s' is not found. Source: %w", id, svcerrors.ErrNotFound)
 	}
-	return round, nil
+	return clone(round), nil
 }
 
-// Add persists a new round instance to the in-memory repository and returns the round with an assigned ID.
-func (repo *Repository) Add(_ context.Context, round *model.Round) (*model.Round, error) {
+// Create persists a new round instance to the in-memory repository and returns the round with an assigned ID.
+func (repo *Repository) Create(_ context.Context, round *model.ShallowRound) (*model.ShallowRound, error) {
 	repo.Lock()
 	defer repo.Unlock()
+
+	if err := repository.ValidateRound(round); err != nil {
+		return nil, err
+	} else if repo.numberTaken(round) {
+		return nil, repository.DuplicateNumberError(round)
+	}
+
 	round.ID = rounds.RoundID(strconv.Itoa(roundCounter))
-	repo.data[rounds.RoundID(strconv.Itoa(roundCounter))] = round
+	repo.data[round.ID] = clone(round)
 	roundCounter++
 
-	return round, svcerrors.ErrNotFound
+	return round, nil
 }
 
-// Update updates an existing round instance in the in-memory repository.
-func (repo *Repository) Update(_ context.Context, r *model.Round) (*model.Round, error) {
+// Replace completely replaces an existing round instance with the provided one, using the ID from the provided
+// round to find which round to replace. This cannot be used to create a new Round, and it is an idempotent
+// operation.
+func (repo *Repository) Replace(_ context.Context, r *model.ShallowRound) (*model.ShallowRound, error) {
 	repo.Lock()
 	defer repo.Unlock()
 
-	repo.data[r.ID] = r
+	if err := repository.ValidateRound(r); err != nil {
+		return nil, err
+	} else if r.ID == "" {
+		return nil, fmt.Errorf("the round data sent with update is missing a round ID. Source: %w", svcerrors.ErrInvalidID)
+	} else if _, exists := repo.data[r.ID]; !exists {
+		return nil, fmt.Errorf("the round with the given ID '%s' is not found. Source: %w", r.ID, svcerrors.ErrNotFound)
+	} else if repo.numberTaken(r) {
+		return nil, repository.DuplicateNumberError(r)
+	}
 
+	repo.data[r.ID] = clone(r)
 	return r, nil
 }
+
+// DeleteByID deletes an existing round instance in the in-memory repository. Returns true if the round was found
+// and deleted, false otherwise. This is an idempotent operation.
+func (repo *Repository) DeleteByID(_ context.Context, id rounds.RoundID) (bool, error) {
+	repo.Lock()
+	defer repo.Unlock()
+
+	if _, exists := repo.data[id]; exists {
+		delete(repo.data, id)
+		return true, nil
+	}
+
+	return false, svcerrors.ErrNotFound
+}
+
+// List returns one page of the rounds matching the query, ordered by ascending ID.
+func (repo *Repository) List(_ context.Context, q model.RoundQuery) (*repository.Page, error) {
+	after, err := repository.DecodeCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.RLock()
+	defer repo.RUnlock()
+
+	matches := []*model.ShallowRound{}
+	for id, r := range repo.data {
+		if (after != "" && repository.CompareRoundIDs(id, after) <= 0) || !q.Matches(r) {
+			continue
+		}
+		matches = append(matches, r)
+	}
+	slices.SortFunc(matches, func(a, b *model.ShallowRound) int {
+		return repository.CompareRoundIDs(a.ID, b.ID)
+	})
+
+	limit := q.PageLimit()
+	result := &repository.Page{Rounds: []model.ShallowRound{}}
+	for i, r := range matches {
+		if i == limit {
+			result.NextCursor = repository.EncodeCursor(result.Rounds[limit-1].ID)
+			break
+		}
+		result.Rounds = append(result.Rounds, *clone(r))
+	}
+
+	return result, nil
+}
+
+// numberTaken returns true if a round other than the given one already has its number in the same league. The
+// caller must hold the lock.
+func (repo *Repository) numberTaken(r *model.ShallowRound) bool {
+	for id, o := range repo.data {
+		if id != r.ID && o.LeagueID == r.LeagueID && o.Number == r.Number {
+			return true
+		}
+	}
+	return false
+}
+
+// clone copies a round on the way in and out of the repository, so callers changing the game IDs of a round they
+// hold cannot change the stored one
+func clone(r *model.ShallowRound) *model.ShallowRound {
+	c := *r
+	c.GameIDs = slices.Clone(r.GameIDs)
+	return &c
+}
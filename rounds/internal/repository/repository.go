@@ -0,0 +1,123 @@
+// Package repository holds the port for storing Rounds, along with the pieces shared by every adapter of it. The
+// adapters themselves are in the sub-packages.
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"os"
+	"strings"
+)
+
+// Repository defines the port for writing Rounds to persistent storage. Rounds are stored in their shallow form,
+// referring to their games by ID only, since the games themselves are owned by the games service.
+type Repository interface {
+	// GetByID retrieves a round by ID from the repository, if no round with the given
+	// ID exists, it returns nil, svcerrors.ErrNotFound.
+	GetByID(ctx context.Context, id rounds.RoundID) (*model.ShallowRound, error)
+
+	// Create persists a new round instance to the repository and returns the round with an assigned ID. A
+	// svcerrors.ErrConflict is returned if the league already has a round with the same number.
+	Create(ctx context.Context, r *model.ShallowRound) (*model.ShallowRound, error)
+
+	// Replace completely replaces an existing round instance with the provided one, using the ID from the provided
+	// round to find which round to replace. This cannot be used to create a new Round, and it is an idempotent
+	// operation. A svcerrors.ErrConflict is returned if another round of the league has the same number.
+	Replace(ctx context.Context, r *model.ShallowRound) (*model.ShallowRound, error)
+
+	// DeleteByID deletes an existing round instance in the repository. Returns true if the round was found and
+	// deleted, false otherwise. This is an idempotent operation.
+	DeleteByID(ctx context.Context, id rounds.RoundID) (bool, error)
+
+	// List returns one page of the rounds matching the filters in the query, ordered by ascending ID so that paging
+	// with the returned cursor is stable. A malformed cursor returns svcerrors.ErrInvalidQuery.
+	List(ctx context.Context, q model.RoundQuery) (*Page, error)
+}
+
+// Page is one page of stored rounds returned from a listing, in a stable order (ascending by ID).
+type Page struct {
+	// Rounds holds the rounds in this page, it is empty rather than nil when nothing matches
+	Rounds []model.ShallowRound
+
+	// NextCursor is set when there are more rounds after this page, pass it back as the cursor to fetch them
+	NextCursor string
+}
+
+const (
+	// KindMemory selects the in-memory adapter, nothing survives a restart
+	KindMemory = "memory"
+
+	// KindSQLite selects the SQLite adapter, storing rounds in the file at Config.SQLitePath
+	KindSQLite = "sqlite"
+)
+
+// Config holds the settings used to choose and set up the repository adapter
+type Config struct {
+	// Kind is one of the KindXYZ constants, empty means KindMemory
+	Kind string
+
+	// SQLitePath is the database file used by the SQLite adapter
+	SQLitePath string
+}
+
+// ConfigFromEnv reads the repository settings from the environment. ROUNDS_REPOSITORY selects the adapter
+// ("memory" or "sqlite") and ROUNDS_SQLITE_PATH sets the database file, defaulting to rounds.db in the working
+// directory.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Kind:       strings.ToLower(os.Getenv("ROUNDS_REPOSITORY")),
+		SQLitePath: os.Getenv("ROUNDS_SQLITE_PATH"),
+	}
+	if cfg.SQLitePath == "" {
+		cfg.SQLitePath = "rounds.db"
+	}
+	return cfg
+}
+
+// ValidateRound checks the round before it is written by any adapter, returning an error wrapping
+// svcerrors.ErrModelInvalid or svcerrors.ErrModelMissing if it cannot be stored.
+func ValidateRound(r *model.ShallowRound) error {
+	v, f, err := r.IsValid()
+	if err != nil {
+		return err
+	} else if !v {
+		return fmt.Errorf("round %w: %s", svcerrors.ErrModelInvalid, strings.Join(f, "; "))
+	}
+	return nil
+}
+
+// DuplicateNumberError is the error adapters return when a league would end up with two rounds with the same number
+func DuplicateNumberError(r *model.ShallowRound) error {
+	return fmt.Errorf("league '%s' already has a round %d. Source: %w", r.LeagueID, r.Number, svcerrors.ErrConflict)
+}
+
+// EncodeCursor turns the ID of the last round in a page into the opaque cursor handed back to clients
+func EncodeCursor(lastID rounds.RoundID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastID))
+}
+
+// DecodeCursor reverses EncodeCursor, returning the ID of the last round on the previous page. An empty cursor
+// decodes to an empty ID, meaning start from the beginning.
+func DecodeCursor(cursor string) (rounds.RoundID, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(b) == 0 {
+		return "", fmt.Errorf("the cursor '%s' is malformed. Source: %w", cursor, svcerrors.ErrInvalidQuery)
+	}
+	return rounds.RoundID(b), nil
+}
+
+// CompareRoundIDs gives the stable sort order used for round listings. IDs are generated from a counter, so
+// shorter IDs sort first and IDs of equal length sort lexically, which is numeric order for the generated IDs.
+func CompareRoundIDs(a, b rounds.RoundID) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(string(a), string(b))
+}
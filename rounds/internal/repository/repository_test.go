@@ -0,0 +1,217 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	gamesheader "github.com/rpatton4/mesbg-league/games/pkg"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository/memory"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository/sqlite"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Sort of in passing this also tests that the adapters meet the Repository interface spec
+var _ repository.Repository = (*memory.Repository)(nil)
+var _ repository.Repository = (*sqlite.Repository)(nil)
+
+func TestMemoryRepository(t *testing.T) {
+	runRepositoryConformance(t, func(t *testing.T) repository.Repository {
+		return memory.New()
+	})
+}
+
+func TestSQLiteRepository(t *testing.T) {
+	runRepositoryConformance(t, func(t *testing.T) repository.Repository {
+		return newTestSQLiteRepository(t, ":memory:")
+	})
+}
+
+func TestSQLiteRepoSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rounds.db")
+
+	r := newTestSQLiteRepository(t, path)
+	sr, err := r.Create(context.Background(), createFakeRound("1", 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatalf("Expected no error closing the repository, got %v", err)
+	}
+
+	// Reopening runs the migrations again, which must leave the existing schema and data alone
+	r = newTestSQLiteRepository(t, path)
+	result, err := r.GetByID(context.Background(), sr.ID)
+	if err != nil {
+		t.Fatalf("Expected the round to survive reopening the database, got %v", err)
+	}
+	if result.Number != sr.Number || result.Date != sr.Date || !slices.Equal(result.GameIDs, sr.GameIDs) {
+		t.Errorf("Expected the reopened round to match the original, got %+v", result)
+	}
+}
+
+// runRepositoryConformance runs the behavior every adapter of the Repository port has to share against the
+// repositories created by newRepo, which is called once for each case so that every case starts empty.
+func runRepositoryConformance(t *testing.T, newRepo func(t *testing.T) repository.Repository) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, r repository.Repository)
+	}{
+		{"CreateAndGet", testRepoCreateAndGet},
+		{"RejectsInvalid", testRepoRejectsInvalid},
+		{"DuplicateNumber", testRepoDuplicateNumber},
+		{"ReplaceAndDelete", testRepoReplaceAndDelete},
+		{"List", testRepoList},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, newRepo(t))
+		})
+	}
+}
+
+func testRepoCreateAndGet(t *testing.T, r repository.Repository) {
+	sr, err := r.Create(context.Background(), createFakeRound("1", 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if sr.ID == "" {
+		t.Fatalf("Expected round ID to be assigned")
+	}
+
+	result, err := r.GetByID(context.Background(), sr.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.LeagueID != "1" || result.Number != 1 || result.ScenarioID != "domination" ||
+		result.ScenarioName != "Domination" || result.PointsLimit != 450 || !slices.Equal(result.GameIDs, []gamesheader.GameID{"3", "1", "2"}) {
+		t.Errorf("Expected the stored round with its games in order, got %+v", result)
+	}
+
+	// Changing the returned round does not change the stored one
+	result.GameIDs[0] = "99"
+	if again, _ := r.GetByID(context.Background(), sr.ID); again.GameIDs[0] != "3" {
+		t.Errorf("Expected the stored game IDs to be unchanged, got %v", again.GameIDs)
+	}
+
+	if _, err = r.GetByID(context.Background(), "9999"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown ID, got %v", err)
+	}
+}
+
+func testRepoRejectsInvalid(t *testing.T, r repository.Repository) {
+	if _, err := r.Create(context.Background(), createFakeRound("", 1)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected ErrModelInvalid for a round without a league, got %v", err)
+	}
+	if _, err := r.Create(context.Background(), nil); !errors.Is(err, svcerrors.ErrModelMissing) {
+		t.Errorf("Expected ErrModelMissing for a nil round, got %v", err)
+	}
+}
+
+func testRepoDuplicateNumber(t *testing.T, r repository.Repository) {
+	first, err := r.Create(context.Background(), createFakeRound("1", 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = r.Create(context.Background(), createFakeRound("1", 1)); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a repeated round number, got %v", err)
+	}
+	second, err := r.Create(context.Background(), createFakeRound("1", 2))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	second.Number = first.Number
+	if _, err = r.Replace(context.Background(), second); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict taking the number of another round, got %v", err)
+	}
+}
+
+func testRepoReplaceAndDelete(t *testing.T, r repository.Repository) {
+	sr, err := r.Create(context.Background(), createFakeRound("1", 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	updated := *sr
+	updated.ScenarioName = "Hold Ground"
+	updated.GameIDs = []gamesheader.GameID{"4"}
+	if _, err = r.Replace(context.Background(), &updated); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result, _ := r.GetByID(context.Background(), sr.ID); result.ScenarioName != "Hold Ground" ||
+		!slices.Equal(result.GameIDs, []gamesheader.GameID{"4"}) {
+		t.Errorf("Expected the round to be replaced, got %+v", result)
+	}
+
+	unknown := updated
+	unknown.ID = "9999"
+	if _, err = r.Replace(context.Background(), &unknown); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound replacing an unknown round, got %v", err)
+	}
+
+	if ok, err := r.DeleteByID(context.Background(), sr.ID); !ok || err != nil {
+		t.Errorf("Expected the round to be deleted, got %v, %v", ok, err)
+	}
+	if _, err = r.GetByID(context.Background(), sr.ID); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if ok, err := r.DeleteByID(context.Background(), sr.ID); ok || !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected a second delete to find nothing, got %v, %v", ok, err)
+	}
+}
+
+func testRepoList(t *testing.T, r repository.Repository) {
+	for i, leagueID := range []string{"1", "2", "1", "1"} {
+		if _, err := r.Create(context.Background(), createFakeRound(leagueID, i+1)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	page, err := r.List(context.Background(), model.RoundQuery{LeagueID: "1", Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(page.Rounds) != 2 || page.NextCursor == "" || len(page.Rounds[0].GameIDs) != 3 {
+		t.Fatalf("Expected a full first page with game IDs and a cursor, got %+v", page)
+	}
+
+	page, err = r.List(context.Background(), model.RoundQuery{LeagueID: "1", Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(page.Rounds) != 1 || page.NextCursor != "" || page.Rounds[0].Number != 4 {
+		t.Errorf("Expected the last round of the league on the second page, got %+v", page)
+	}
+
+	if _, err = r.List(context.Background(), model.RoundQuery{Cursor: "!!"}); !errors.Is(err, svcerrors.ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for a malformed cursor, got %v", err)
+	}
+}
+
+// newTestSQLiteRepository opens a SQLite repository at the given path, closed when the test ends
+func newTestSQLiteRepository(t *testing.T, path string) *sqlite.Repository {
+	r, err := sqlite.New(path)
+	if err != nil {
+		t.Fatalf("Unable to open the SQLite repository: %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func createFakeRound(leagueID string, number int) *model.ShallowRound {
+	return &model.ShallowRound{
+		LeagueID:     leagues.LeagueID(leagueID),
+		Number:       number,
+		ScenarioID:   "domination",
+		ScenarioName: "Domination",
+		PointsLimit:  450,
+		Date:         "2025-09-08",
+		GameIDs:      []gamesheader.GameID{"3", "1", "2"},
+	}
+}
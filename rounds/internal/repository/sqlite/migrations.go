@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// migrations holds the schema changes for the SQLite adapter, in order. The position in the slice (starting at 1)
+// is the schema version, so migrations must only ever be appended, never edited or reordered once released.
+var migrations = []string{
+	// 1: the rounds table, with the game IDs of each round kept in order in round_games
+	`CREATE TABLE rounds (
+		id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		league_id     TEXT    NOT NULL,
+		number        INTEGER NOT NULL,
+		scenario_name TEXT    NOT NULL DEFAULT '',
+		date          TEXT    NOT NULL DEFAULT '',
+		UNIQUE (league_id, number)
+	);
+	CREATE TABLE round_games (
+		round_id INTEGER NOT NULL REFERENCES rounds (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		game_id  TEXT    NOT NULL,
+		PRIMARY KEY (round_id, position)
+	);`,
+
+	// 2: the scenario from the scenario catalog picked for each round
+	`ALTER TABLE rounds ADD COLUMN scenario_id TEXT NOT NULL DEFAULT '';`,
+
+	// 3: the points limit of each round, for escalation leagues
+	`ALTER TABLE rounds ADD COLUMN points_limit INTEGER NOT NULL DEFAULT 0;`,
+}
+
+// migrate brings the schema of the given database up to date, applying each outstanding migration in its own
+// transaction and recording it in the schema_migrations table.
+func migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT    NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("unable to create the schema_migrations table: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("unable to read the current schema version: %w", err)
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("unable to apply schema migration %d: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("unable to record schema migration %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("unable to commit schema migration %d: %w", version, err)
+		}
+		slog.Info("Applied rounds schema migration", "version", version)
+	}
+
+	return nil
+}
@@ -0,0 +1,307 @@
+// Package sqlite holds the SQLite adapter of the rounds repository port, so that rounds survive restarts.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	gamesheader "github.com/rpatton4/mesbg-league/games/pkg"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	scenarios "github.com/rpatton4/mesbg-league/scenarios/pkg"
+	"strconv"
+	"strings"
+
+	// Pure Go SQLite driver, registered as "sqlite", so the service builds without cgo
+	_ "modernc.org/sqlite"
+)
+
+// roundColumns is the column list used when reading rounds, in the order expected by scanRound
+const roundColumns = `id, league_id, number, scenario_id, scenario_name, points_limit, date`
+
+// Repository defines a repository (adapter) for the Rounds service which stores rounds in a SQLite database
+type Repository struct {
+	db *sql.DB
+}
+
+// New opens (creating if needed) the SQLite database at the given path and migrates its schema to the latest
+// version. Use ":memory:" for a database which only lasts as long as the repository.
+func New(path string) (*Repository, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the rounds database '%s': %w", path, err)
+	}
+
+	// SQLite only allows one writer at a time, and every connection to ":memory:" is a separate database, so a
+	// single connection keeps both cases simple
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(context.Background(), db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Repository{db: db}, nil
+}
+
+// Close releases the database held by the repository
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
+
+// GetByID retrieves a round by ID from the database, if no round with the given ID exists, it returns
+// svcerrors.ErrNotFound.
+func (r *Repository) GetByID(ctx context.Context, id rounds.RoundID) (*model.ShallowRound, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+roundColumns+` FROM rounds WHERE id = ?`, string(id))
+	sr, err := scanRound(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("the round with the given ID '%s' is not found. Source: %w", id, svcerrors.ErrNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read round '%s': %w", id, err)
+	}
+
+	if sr.GameIDs, err = gameIDs(ctx, r.db, sr.ID); err != nil {
+		return nil, err
+	}
+	return sr, nil
+}
+
+// Create persists a new round instance to the database and returns the round with an assigned ID.
+func (r *Repository) Create(ctx context.Context, sr *model.ShallowRound) (*model.ShallowRound, error) {
+	if err := repository.ValidateRound(sr); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if taken, err := numberTaken(ctx, tx, sr); err != nil {
+		return nil, err
+	} else if taken {
+		return nil, repository.DuplicateNumberError(sr)
+	}
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO rounds (league_id, number, scenario_id, scenario_name, points_limit, date) VALUES (?, ?, ?, ?, ?, ?)`,
+		string(sr.LeagueID), sr.Number, string(sr.ScenarioID), sr.ScenarioName, sr.PointsLimit, sr.Date)
+	if err != nil {
+		return nil, fmt.Errorf("unable to insert round: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the ID of the new round: %w", err)
+	}
+
+	if err = writeGameIDs(ctx, tx, id, sr.GameIDs); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("unable to commit the new round: %w", err)
+	}
+
+	sr.ID = rounds.RoundID(strconv.FormatInt(id, 10))
+	return sr, nil
+}
+
+// Replace completely replaces an existing round instance with the provided one, using the ID from the provided
+// round to find which round to replace. This cannot be used to create a new Round, and it is an idempotent
+// operation. If the round is missing or invalid, this returns the appropriate svcerror
+func (r *Repository) Replace(ctx context.Context, sr *model.ShallowRound) (*model.ShallowRound, error) {
+	if err := repository.ValidateRound(sr); err != nil {
+		return nil, err
+	} else if sr.ID == "" {
+		return nil, fmt.Errorf("the round data sent with update is missing a round ID. Source: %w", svcerrors.ErrInvalidID)
+	}
+	id, err := strconv.ParseInt(string(sr.ID), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("the round with the given ID '%s' is not found. Source: %w", sr.ID, svcerrors.ErrNotFound)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM rounds WHERE id = ?`, id).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("unable to read round '%s': %w", sr.ID, err)
+	} else if exists == 0 {
+		return nil, fmt.Errorf("the round with the given ID '%s' is not found. Source: %w", sr.ID, svcerrors.ErrNotFound)
+	}
+	if taken, err := numberTaken(ctx, tx, sr); err != nil {
+		return nil, err
+	} else if taken {
+		return nil, repository.DuplicateNumberError(sr)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE rounds SET league_id = ?, number = ?, scenario_id = ?, scenario_name = ?, points_limit = ?, date = ? WHERE id = ?`,
+		string(sr.LeagueID), sr.Number, string(sr.ScenarioID), sr.ScenarioName, sr.PointsLimit, sr.Date, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update round '%s': %w", sr.ID, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM round_games WHERE round_id = ?`, id); err != nil {
+		return nil, fmt.Errorf("unable to clear the games of round '%s': %w", sr.ID, err)
+	}
+	if err = writeGameIDs(ctx, tx, id, sr.GameIDs); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("unable to commit round '%s': %w", sr.ID, err)
+	}
+	return sr, nil
+}
+
+// DeleteByID deletes an existing round instance from the database, along with its game IDs. Returns true if the
+// round was found and deleted, false otherwise. This is an idempotent operation.
+func (r *Repository) DeleteByID(ctx context.Context, id rounds.RoundID) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM rounds WHERE id = ?`, string(id))
+	if err != nil {
+		return false, fmt.Errorf("unable to delete round '%s': %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return false, err
+	} else if n == 0 {
+		return false, svcerrors.ErrNotFound
+	}
+	return true, nil
+}
+
+// List returns one page of the rounds matching the query, ordered by ascending ID.
+func (r *Repository) List(ctx context.Context, q model.RoundQuery) (*repository.Page, error) {
+	after, err := repository.DecodeCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	where := []string{}
+	args := []any{}
+	if after != "" {
+		where = append(where, "id > ?")
+		args = append(args, string(after))
+	}
+	if q.LeagueID != "" {
+		where = append(where, "league_id = ?")
+		args = append(args, string(q.LeagueID))
+	}
+
+	query := `SELECT ` + roundColumns + ` FROM rounds`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	limit := q.PageLimit()
+	query += ` ORDER BY id LIMIT ?`
+	args = append(args, limit+1) // one extra row tells us whether there is another page
+
+	result, err := r.listRounds(ctx, query, args, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// The game IDs are read once the rounds have been, since the single connection is busy until the rows close
+	for i := range result.Rounds {
+		if result.Rounds[i].GameIDs, err = gameIDs(ctx, r.db, result.Rounds[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// listRounds runs a query selecting roundColumns, returning up to limit rounds and the cursor for the next page
+func (r *Repository) listRounds(ctx context.Context, query string, args []any, limit int) (*repository.Page, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list rounds: %w", err)
+	}
+	defer rows.Close()
+
+	result := &repository.Page{Rounds: []model.ShallowRound{}}
+	for rows.Next() {
+		if len(result.Rounds) == limit {
+			result.NextCursor = repository.EncodeCursor(result.Rounds[limit-1].ID)
+			break
+		}
+		sr, err := scanRound(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read listed round: %w", err)
+		}
+		result.Rounds = append(result.Rounds, *sr)
+	}
+	return result, rows.Err()
+}
+
+// querier covers both *sql.DB and *sql.Tx so the same statements can be run inside or outside a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// gameIDs reads the IDs of the games of the round, in the order they were written
+func gameIDs(ctx context.Context, q querier, id rounds.RoundID) ([]gamesheader.GameID, error) {
+	rows, err := q.QueryContext(ctx, `SELECT game_id FROM round_games WHERE round_id = ? ORDER BY position`, string(id))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the games of round '%s': %w", id, err)
+	}
+	defer rows.Close()
+
+	var ids []gamesheader.GameID
+	for rows.Next() {
+		var gameID string
+		if err := rows.Scan(&gameID); err != nil {
+			return nil, fmt.Errorf("unable to read a game of round '%s': %w", id, err)
+		}
+		ids = append(ids, gamesheader.GameID(gameID))
+	}
+	return ids, rows.Err()
+}
+
+// writeGameIDs stores the IDs of the games of the round, keeping their order
+func writeGameIDs(ctx context.Context, q querier, id int64, ids []gamesheader.GameID) error {
+	for i, gameID := range ids {
+		if _, err := q.ExecContext(ctx, `INSERT INTO round_games (round_id, position, game_id) VALUES (?, ?, ?)`, id, i, string(gameID)); err != nil {
+			return fmt.Errorf("unable to store game '%s' of round %d: %w", gameID, id, err)
+		}
+	}
+	return nil
+}
+
+// numberTaken returns true if a round other than the given one already has its number in the same league
+func numberTaken(ctx context.Context, q querier, sr *model.ShallowRound) (bool, error) {
+	var count int
+	if err := q.QueryRowContext(ctx, `SELECT COUNT(*) FROM rounds WHERE league_id = ? AND number = ? AND id != ?`,
+		string(sr.LeagueID), sr.Number, string(sr.ID)).Scan(&count); err != nil {
+		return false, fmt.Errorf("unable to check the number of round '%s': %w", sr.ID, err)
+	}
+	return count > 0, nil
+}
+
+// rowScanner covers both *sql.Row and *sql.Rows so a single function can read rounds from either
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRound reads a round, without its game IDs, from a row selected with roundColumns
+func scanRound(row rowScanner) (*model.ShallowRound, error) {
+	var (
+		sr       model.ShallowRound
+		id       int64
+		leagueID string
+		scenario string
+	)
+	if err := row.Scan(&id, &leagueID, &sr.Number, &scenario, &sr.ScenarioName, &sr.PointsLimit, &sr.Date); err != nil {
+		return nil, err
+	}
+
+	sr.ID = rounds.RoundID(strconv.FormatInt(id, 10))
+	sr.LeagueID = leagues.LeagueID(leagueID)
+	sr.ScenarioID = scenarios.ScenarioID(scenario)
+	return &sr, nil
+}
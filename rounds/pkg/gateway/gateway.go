@@ -0,0 +1,36 @@
+// Package gateway contains clients for interacting with the rounds service from other services.
+// The package is meant to be public, and will commit to being backwards compatible within major versions.
+// The package primarily consists of the RoundsGateway interface, with different implementations
+// of the interface for calling it in-memory or over HTTP.
+package gateway
+
+import (
+	"context"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+)
+
+// RoundsGateway provides a set of methods for interacting with the Rounds service from outside the service.
+type RoundsGateway interface {
+	// GetByID returns the round with the given id, or a svcerrors.ErrNotFound if no round with that id exists
+	GetByID(ctx context.Context, id rounds.RoundID) (*model.Round, error)
+
+	// Create persists a new round instance to the service and returns the round with an assigned ID.
+	// A generic error is returned if the round to create is missing, while specific validation errors are
+	// passed along from the service if the round is invalid in some way. A svcerrors.ErrConflict is returned if
+	// the league already has a round with the same number.
+	Create(ctx context.Context, r *model.Round) (*model.Round, error)
+
+	// Replace updates an existing round in the service with the provided round.
+	// A generic error is returned if the round to replace is not known to the service.
+	// This is an idempotent operation.
+	Replace(ctx context.Context, r *model.Round) (*model.Round, error)
+
+	// DeleteByID removes the round with the given id from the service. Returns true if the round was found and
+	// deleted, false otherwise. This is an idempotent operation.
+	DeleteByID(ctx context.Context, id rounds.RoundID) (bool, error)
+
+	// List returns one page of the rounds matching the filters in the query, ordered by ID. Pass the NextCursor
+	// from the returned list back in the query to fetch the following page.
+	List(ctx context.Context, q model.RoundQuery) (*model.RoundList, error)
+}
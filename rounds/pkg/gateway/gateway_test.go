@@ -0,0 +1,199 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/rounds/internal/domain"
+	handlerhttp "github.com/rpatton4/mesbg-league/rounds/internal/handler/http"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository/memory"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Both gateways have to satisfy the interface
+var _ RoundsGateway = (*InProcessGateway)(nil)
+var _ RoundsGateway = (*HTTPGateway)(nil)
+
+func TestInProcessGatewayContract(t *testing.T) {
+	runGatewayContract(t, func(t *testing.T) RoundsGateway {
+		return NewInProcessGatewayWithController(domain.New(memory.New(), nil, nil, nil))
+	})
+}
+
+func TestHTTPGatewayContract(t *testing.T) {
+	runGatewayContract(t, func(t *testing.T) RoundsGateway {
+		srv := newTestRoundsServer(t)
+		return New(srv.URL, WithHTTPClient(srv.Client()))
+	})
+}
+
+// runGatewayContract runs the same scenarios against any RoundsGateway, so that callers get identical results and
+// errors whichever implementation they are given. newGateway is called for each case so every case starts empty.
+func runGatewayContract(t *testing.T, newGateway func(t *testing.T) RoundsGateway) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, gw RoundsGateway)
+	}{
+		{"CreateAndGet", testGatewayCreateAndGet},
+		{"CreateInvalid", testGatewayCreateInvalid},
+		{"CreateDuplicateNumber", testGatewayCreateDuplicateNumber},
+		{"GetUnknown", testGatewayGetUnknown},
+		{"Replace", testGatewayReplace},
+		{"Delete", testGatewayDelete},
+		{"List", testGatewayList},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, newGateway(t))
+		})
+	}
+}
+
+func testGatewayCreateAndGet(t *testing.T, gw RoundsGateway) {
+	r, err := gw.Create(context.Background(), createFakeRound("1", 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if r.ID == "" {
+		t.Fatalf("Expected round ID to be assigned")
+	}
+
+	result, err := gw.GetByID(context.Background(), r.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.ID != r.ID || result.LeagueID != r.LeagueID || result.Number != r.Number || result.Date != r.Date {
+		t.Errorf("Expected the fetched round to match the created one, got %+v", result)
+	}
+}
+
+func testGatewayCreateInvalid(t *testing.T, gw RoundsGateway) {
+	if _, err := gw.Create(context.Background(), createFakeRound("1", 0)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+		t.Errorf("Expected invalid model error, got %v", err)
+	}
+
+	if _, err := gw.Create(context.Background(), nil); !errors.Is(err, svcerrors.ErrModelMissing) {
+		t.Errorf("Expected missing model error, got %v", err)
+	}
+}
+
+func testGatewayCreateDuplicateNumber(t *testing.T, gw RoundsGateway) {
+	if _, err := gw.Create(context.Background(), createFakeRound("1", 1)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := gw.Create(context.Background(), createFakeRound("1", 1)); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected conflict error for a repeated round number, got %v", err)
+	}
+	if _, err := gw.Create(context.Background(), createFakeRound("2", 1)); err != nil {
+		t.Errorf("Expected the same number to be allowed in another league, got %v", err)
+	}
+}
+
+func testGatewayGetUnknown(t *testing.T, gw RoundsGateway) {
+	if _, err := gw.GetByID(context.Background(), "9999"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if _, err := gw.GetByID(context.Background(), ""); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected invalid ID error, got %v", err)
+	}
+}
+
+func testGatewayReplace(t *testing.T, gw RoundsGateway) {
+	r, err := gw.Create(context.Background(), createFakeRound("1", 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	r.ScenarioName = "Hold Ground"
+	result, err := gw.Replace(context.Background(), r)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.ScenarioName != "Hold Ground" {
+		t.Errorf("Expected the scenario to be updated, got '%s'", result.ScenarioName)
+	}
+
+	unknown := *result
+	unknown.ID = "9999"
+	if _, err = gw.Replace(context.Background(), &unknown); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	missingID := *result
+	missingID.ID = ""
+	if _, err = gw.Replace(context.Background(), &missingID); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected invalid ID error, got %v", err)
+	}
+}
+
+func testGatewayDelete(t *testing.T, gw RoundsGateway) {
+	r, err := gw.Create(context.Background(), createFakeRound("1", 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ok, err := gw.DeleteByID(context.Background(), r.ID)
+	if err != nil || !ok {
+		t.Fatalf("Expected delete to succeed, got %v, %v", ok, err)
+	}
+
+	ok, err = gw.DeleteByID(context.Background(), r.ID)
+	if !errors.Is(err, svcerrors.ErrNotFound) || ok {
+		t.Errorf("Expected not found deleting a second time, got %v, %v", ok, err)
+	}
+}
+
+func testGatewayList(t *testing.T, gw RoundsGateway) {
+	for i, leagueID := range []string{"1", "2", "1"} {
+		if _, err := gw.Create(context.Background(), createFakeRound(leagueID, i+1)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	l, err := gw.List(context.Background(), model.RoundQuery{LeagueID: "1", Limit: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(l.Rounds) != 1 || l.NextCursor == "" {
+		t.Fatalf("Expected one round and a cursor for the next page, got %+v", l)
+	}
+
+	l, err = gw.List(context.Background(), model.RoundQuery{LeagueID: "1", Limit: 1, Cursor: l.NextCursor})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(l.Rounds) != 1 || l.NextCursor != "" || l.Rounds[0].Number != 3 {
+		t.Errorf("Expected the last round of the league and no cursor, got %+v", l)
+	}
+
+	if _, err = gw.List(context.Background(), model.RoundQuery{Cursor: "!!"}); !errors.Is(err, svcerrors.ErrInvalidQuery) {
+		t.Errorf("Expected invalid query error, got %v", err)
+	}
+}
+
+// newTestRoundsServer starts the Rounds service routes over a fresh memory repository, closed when the test ends
+func newTestRoundsServer(t *testing.T) *httptest.Server {
+	router := http.NewServeMux()
+	api := humago.New(router, huma.DefaultConfig("Rounds Service", "1.0.0"))
+	handlerhttp.RegisterRoutes(api, handlerhttp.NewHumaHandler(domain.New(memory.New(), nil, nil, nil)))
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func createFakeRound(leagueID string, number int) *model.Round {
+	return &model.Round{
+		LeagueID:     leagues.LeagueID(leagueID),
+		Number:       number,
+		ScenarioName: "Domination",
+		Date:         "2025-09-08",
+	}
+}
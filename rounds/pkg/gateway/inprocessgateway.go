@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"context"
+	"github.com/rpatton4/mesbg-league/rounds/internal/domain"
+	"github.com/rpatton4/mesbg-league/rounds/internal/repository/memory"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+)
+
+type InProcessGateway struct {
+	ctrl domain.SingleController
+}
+
+// NewInProcessGatewayWithController creates a new InProcessGateway with the provided controller.
+// This is intended primarily for use while testing, to provide a mock or stub controller.
+func NewInProcessGatewayWithController(ctrl domain.SingleController) *InProcessGateway {
+	return &InProcessGateway{ctrl}
+}
+
+// NewDefaultInProcessGateway creates a new InProcessGateway with a default repository and controller. The default
+// controller has no access to the leagues, games or scenarios, so it does not check that the league or scenario of
+// a round exists.
+func NewDefaultInProcessGateway() *InProcessGateway {
+	return NewInProcessGatewayWithController(domain.New(memory.New(), nil, nil, nil))
+}
+
+func (ipg *InProcessGateway) GetByID(ctx context.Context, id rounds.RoundID) (*model.Round, error) {
+	return ipg.ctrl.GetByID(ctx, id)
+}
+func (ipg *InProcessGateway) Create(ctx context.Context, r *model.Round) (*model.Round, error) {
+	return ipg.ctrl.Create(ctx, r)
+}
+func (ipg *InProcessGateway) Replace(ctx context.Context, r *model.Round) (*model.Round, error) {
+	return ipg.ctrl.Replace(ctx, r)
+}
+func (ipg *InProcessGateway) DeleteByID(ctx context.Context, id rounds.RoundID) (bool, error) {
+	return ipg.ctrl.DeleteByID(ctx, id)
+}
+func (ipg *InProcessGateway) List(ctx context.Context, q model.RoundQuery) (*model.RoundList, error) {
+	return ipg.ctrl.List(ctx, q)
+}
@@ -0,0 +1,277 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	rounds "github.com/rpatton4/mesbg-league/rounds/pkg"
+	"github.com/rpatton4/mesbg-league/rounds/pkg/model"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is how long a single attempt at a call may take before it is abandoned
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultRetries is how many times an idempotent call is retried after a failed first attempt
+	DefaultRetries = 2
+
+	// DefaultBackoff is the wait before the first retry, doubling for each retry after that
+	DefaultBackoff = 100 * time.Millisecond
+)
+
+// HTTPGateway is the RoundsGateway implementation for calling the Rounds service over HTTP(S). Errors returned by
+// the service are mapped back to the same svcerrors values the InProcessGateway returns, so callers can use
+// errors.Is without caring which gateway they have.
+type HTTPGateway struct {
+	addr    string
+	client  *http.Client
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+// Option configures an HTTPGateway when it is created
+type Option func(*HTTPGateway)
+
+// WithHTTPClient sets the client used to make the calls, http.DefaultClient is used otherwise
+func WithHTTPClient(c *http.Client) Option {
+	return func(g *HTTPGateway) {
+		g.client = c
+	}
+}
+
+// WithTimeout sets how long a single attempt at a call may take, zero means no timeout beyond the caller's context
+func WithTimeout(d time.Duration) Option {
+	return func(g *HTTPGateway) {
+		g.timeout = d
+	}
+}
+
+// WithRetries sets how many times idempotent calls (everything except Create) are retried after a network error
+// or a response indicating the service is temporarily unavailable, and the wait before the first retry
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(g *HTTPGateway) {
+		g.retries = retries
+		g.backoff = backoff
+	}
+}
+
+// New creates an HTTPGateway for the Rounds service at the given base address, e.g. "http://localhost:8085"
+func New(addr string, opts ...Option) *HTTPGateway {
+	g := &HTTPGateway{
+		addr:    strings.TrimSuffix(addr, "/"),
+		client:  http.DefaultClient,
+		timeout: DefaultTimeout,
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// GetByID returns the round with the given id, or a svcerrors.ErrNotFound if no round with that id exists
+func (g *HTTPGateway) GetByID(ctx context.Context, id rounds.RoundID) (*model.Round, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+
+	var r model.Round
+	if err := g.do(ctx, http.MethodGet, "/rounds/"+url.PathEscape(string(id)), nil, &r, true); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Create persists a new round instance to the service and returns the round with an assigned ID. Creation is not
+// idempotent, so it is never retried.
+func (g *HTTPGateway) Create(ctx context.Context, r *model.Round) (*model.Round, error) {
+	if r == nil {
+		return nil, fmt.Errorf("the round to be created cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	}
+
+	var created model.Round
+	if err := g.do(ctx, http.MethodPost, "/rounds", r, &created, false); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Replace updates an existing round in the service with the provided round.
+func (g *HTTPGateway) Replace(ctx context.Context, r *model.Round) (*model.Round, error) {
+	if r == nil {
+		return nil, fmt.Errorf("the round to be replaced cannot be nil. Source: %w", svcerrors.ErrModelMissing)
+	} else if r.ID == "" {
+		return nil, fmt.Errorf("the round data sent with update is missing a round ID. Source: %w", svcerrors.ErrInvalidID)
+	}
+
+	var replaced model.Round
+	if err := g.do(ctx, http.MethodPut, "/rounds/"+url.PathEscape(string(r.ID)), r, &replaced, true); err != nil {
+		return nil, err
+	}
+	return &replaced, nil
+}
+
+// DeleteByID removes the round with the given id from the service. Returns true if the round was found and
+// deleted, false otherwise.
+func (g *HTTPGateway) DeleteByID(ctx context.Context, id rounds.RoundID) (bool, error) {
+	if id == "" {
+		return false, svcerrors.ErrInvalidID
+	}
+
+	if err := g.do(ctx, http.MethodDelete, "/rounds/"+url.PathEscape(string(id)), nil, nil, true); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// List returns one page of the rounds matching the filters in the query, ordered by ID.
+func (g *HTTPGateway) List(ctx context.Context, q model.RoundQuery) (*model.RoundList, error) {
+	if q.Limit < 0 {
+		return nil, fmt.Errorf("the page limit cannot be negative, got %d. Source: %w", q.Limit, svcerrors.ErrInvalidQuery)
+	}
+
+	v := url.Values{}
+	if q.LeagueID != "" {
+		v.Set("leagueId", string(q.LeagueID))
+	}
+	if q.Cursor != "" {
+		v.Set("cursor", q.Cursor)
+	}
+	v.Set("limit", strconv.Itoa(q.PageLimit()))
+
+	var list model.RoundList
+	if err := g.do(ctx, http.MethodGet, "/rounds?"+v.Encode(), nil, &list, true); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// do makes the call to the service, retrying idempotent calls which fail in a way that may succeed on another try.
+// The response body is decoded into out when it is not nil.
+func (g *HTTPGateway) do(ctx context.Context, method, path string, in any, out any, idempotent bool) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to encode the request body: %w", err)
+		}
+	}
+
+	attempts := 1
+	if idempotent {
+		attempts += g.retries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			wait := g.backoff << (attempt - 1)
+			slog.Debug("Retrying call to the rounds service", "method", method, "path", path, "attempt", attempt+1, "wait", wait, "error", lastErr)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		retry, err := g.attempt(ctx, method, path, body, out)
+		if err == nil || !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// attempt makes a single call to the service, reporting whether a failure is worth retrying
+func (g *HTTPGateway) attempt(ctx context.Context, method, path string, body []byte, out any) (bool, error) {
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, g.addr+path, reader)
+	if err != nil {
+		return false, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		// The caller giving up is final, anything else at the network level may be temporary
+		return ctx.Err() == nil || errors.Is(ctx.Err(), context.DeadlineExceeded), err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout
+		return retry, errorFromResponse(resp)
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, fmt.Errorf("failed to decode the rounds service response: %w", err)
+		}
+	}
+	return false, nil
+}
+
+// detailSentinels are the errors which can be recognised from the detail of a 4xx response, in the order they are
+// checked. The service includes the underlying error text in its responses, which always contains the sentinel.
+var detailSentinels = []error{
+	svcerrors.ErrInvalidQuery,
+	svcerrors.ErrInvalidID,
+	svcerrors.ErrModelMissing,
+	svcerrors.ErrNotFound,
+	svcerrors.ErrModelInvalid,
+}
+
+// errorFromResponse turns an error response from the service back into the svcerrors value which caused it
+func errorFromResponse(resp *http.Response) error {
+	var problem huma.ErrorModel
+	b, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(b, &problem); err != nil || problem.Detail == "" {
+		problem.Detail = strings.TrimSpace(string(b))
+	}
+
+	var sentinel error
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		sentinel = svcerrors.ErrNotFound
+	case resp.StatusCode == http.StatusConflict:
+		sentinel = svcerrors.ErrConflict
+	case resp.StatusCode/100 == 4:
+		sentinel = svcerrors.ErrModelInvalid
+		for _, s := range detailSentinels {
+			if strings.Contains(problem.Detail, s.Error()) {
+				sentinel = s
+				break
+			}
+		}
+	default:
+		return fmt.Errorf("rounds service responded %d: %s", resp.StatusCode, problem.Detail)
+	}
+	return fmt.Errorf("rounds service responded %d: %s. Source: %w", resp.StatusCode, problem.Detail, sentinel)
+}
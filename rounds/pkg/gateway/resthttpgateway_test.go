@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPGatewayRetriesIdempotentCalls(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","leagueId":"1","number":1}`))
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithRetries(2, time.Millisecond))
+	r, err := gw.GetByID(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after retrying, got %v", err)
+	}
+	if r.ID != "1" || calls.Load() != 3 {
+		t.Errorf("Expected round 1 after 3 calls, got round %s after %d calls", r.ID, calls.Load())
+	}
+}
+
+func TestHTTPGatewayDoesNotRetryCreate(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "try again later", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithRetries(2, time.Millisecond))
+	if _, err := gw.Create(context.Background(), createFakeRound("1", 1)); err == nil {
+		t.Fatalf("Expected the create to fail")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single call for a create, got %d", calls.Load())
+	}
+}
+
+func TestHTTPGatewayMapsConflict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "league already has a round 1", http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()))
+	if _, err := gw.Create(context.Background(), createFakeRound("1", 1)); !errors.Is(err, svcerrors.ErrConflict) {
+		t.Errorf("Expected a conflict error, got %v", err)
+	}
+}
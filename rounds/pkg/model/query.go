@@ -0,0 +1,54 @@
+package model
+
+import (
+	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+)
+
+// DefaultListLimit is the number of rounds returned in one page of a listing when the caller does not ask for a
+// specific page size
+const DefaultListLimit = 50
+
+// MaxListLimit is the largest page size which will be honoured when listing rounds, larger requests are capped
+const MaxListLimit = 200
+
+// RoundQuery holds the filters and paging information used when listing rounds. Any filter left at its zero value
+// is not applied, so an empty RoundQuery returns the first page of all rounds.
+type RoundQuery struct {
+	// LeagueID limits the results to rounds of the given league
+	LeagueID leagues.LeagueID
+
+	// Cursor is the opaque value returned as NextCursor by a previous page, used to fetch the page after it.
+	// Leave empty to start from the first page.
+	Cursor string
+
+	// Limit is the maximum number of rounds to return in the page, see DefaultListLimit and MaxListLimit
+	Limit int
+}
+
+// PageLimit returns the page size to use for the query, applying the default and the maximum
+func (q RoundQuery) PageLimit() int {
+	if q.Limit <= 0 {
+		return DefaultListLimit
+	} else if q.Limit > MaxListLimit {
+		return MaxListLimit
+	}
+	return q.Limit
+}
+
+// Matches returns true if the given stored round passes all the filters set on the query. Paging fields are not
+// considered.
+func (q RoundQuery) Matches(r *ShallowRound) bool {
+	if r == nil {
+		return false
+	}
+	return q.LeagueID == "" || r.LeagueID == q.LeagueID
+}
+
+// RoundList is one page of rounds returned from a listing, in a stable order (ascending by ID).
+type RoundList struct {
+	// Rounds holds the rounds in this page, it is empty rather than nil when nothing matches
+	Rounds []Round `json:"rounds" doc:"The rounds in this page of results, ordered by ID"`
+
+	// NextCursor is set when there are more rounds after this page, pass it back as the cursor to fetch them
+	NextCursor string `json:"nextCursor,omitempty" example:"MTI" doc:"Opaque cursor for the next page, absent when this is the last page"`
+}
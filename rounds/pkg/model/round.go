@@ -1,31 +1,67 @@
 package model
 
 import (
+	"fmt"
 	gamesheader "github.com/rpatton4/mesbg-league/games/pkg"
 	games "github.com/rpatton4/mesbg-league/games/pkg/model"
 	leagues "github.com/rpatton4/mesbg-league/leagues/pkg"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
 	"github.com/rpatton4/mesbg-league/rounds/pkg"
+	scenarios "github.com/rpatton4/mesbg-league/scenarios/pkg"
+	"log/slog"
+	"time"
 )
 
 // Round models one round of games in a league, linking the games scheduled and played for that round to the league
 type Round struct {
 	// ID is the unique identifier for the round
-	ID pkg.RoundID `json:"id,omitempty"`
+	ID pkg.RoundID `json:"id,omitempty" example:"12" doc:"The unique identifier for the round, generated by the service"`
 
 	// LeagueID is the key to the league this round belongs to
-	LeagueID leagues.LeagueID `json:"leagueId"`
+	LeagueID leagues.LeagueID `json:"leagueId" example:"1" doc:"The unique identifier for the league the round belongs to"`
 
 	// Number indicates which round this is in the league (1, 2, 3, etc)
-	Number int `json:"number"`
+	Number int `json:"number" example:"2" doc:"Which round this is in the league, starting at 1 and unique within the league"`
+
+	// ScenarioID is the key to the scenario from the scenario catalog to be played in this round, if one has been
+	// picked. When it is set the results of the round's games are checked against the scenario's victory points.
+	ScenarioID scenarios.ScenarioID `json:"scenarioId,omitempty" example:"domination" doc:"The unique identifier for the scenario from the scenario catalog to be played in the round"`
 
 	// ScenarioName is the name of the scenario expected to be played in this round, from the MSBG
-	// rule book or the matched play guide
-	ScenarioName string `json:"scenarioName"`
+	// rule book or the matched play guide. It is filled in from the catalog when the ScenarioID is set.
+	ScenarioName string `json:"scenarioName" example:"Domination" doc:"The name of the scenario to be played in the round, filled in from the catalog when scenarioId is set"`
 
-	// Games is the slice of games scheduled/played in this round
-	Games []games.Game `json:"games,omitempty"`
+	// PointsLimit is the most points the army lists used in this round's games may cost, zero means the round has
+	// no limit of its own. In an escalation league the limit rises from one round to the next.
+	PointsLimit int `json:"pointsLimit,omitempty" example:"450" doc:"The most points the army lists used in the round's games may cost, zero or absent for no limit"`
+
+	// Date is the date the round is expected to be played in YYYY-MM-DD format (ISO 8601), if it has been scheduled
+	Date string `json:"date,omitempty" example:"2025-09-08" doc:"The date the round is expected to be played, in YYYY-MM-DD format"`
+
+	// GameIDs is the slice of IDs for games scheduled/played in this round, it is always filled in when a round is
+	// returned by the service
+	GameIDs []gamesheader.GameID `json:"gameIds,omitempty" example:"[\"7\"]" doc:"The unique identifiers of the games scheduled or played in the round"`
+
+	// Games is the slice of games scheduled/played in this round, only filled in when the round is expanded since
+	// the games themselves are owned by the games service
+	Games []games.Game `json:"games,omitempty" doc:"The games scheduled or played in the round, only included when the round is expanded"`
+
+	// MissingGames lists the games of the round which could not be fetched from the games service when the round
+	// was expanded, the rest of the round is still returned when some of its games are missing
+	MissingGames []MissingGame `json:"missingGames,omitempty" doc:"The games of the round which could not be fetched when it was expanded"`
 }
 
+// MissingGame reports a game referred to by a round which could not be fetched when the round was expanded
+type MissingGame struct {
+	// GameID is the ID of the game which could not be fetched
+	GameID gamesheader.GameID `json:"gameId" example:"7" doc:"The unique identifier of the game which could not be fetched"`
+
+	// Reason describes why the game could not be fetched, e.g. because it no longer exists
+	Reason string `json:"reason" example:"not found" doc:"Why the game could not be fetched"`
+}
+
+// ShallowRound is the form of a Round which only refers to its games by ID, for storing a round without the data
+// owned by the games service
 type ShallowRound struct {
 	// ID is the unique identifier for the round
 	ID pkg.RoundID `json:"id,omitempty"`
@@ -36,10 +72,62 @@ type ShallowRound struct {
 	// Number indicates which round this is in the league (1, 2, 3, etc)
 	Number int `json:"number"`
 
+	// ScenarioID is the key to the scenario from the scenario catalog to be played in this round
+	ScenarioID scenarios.ScenarioID `json:"scenarioId,omitempty"`
+
 	// ScenarioName is the name of the scenario expected to be played in this round, from the MSBG
 	// rule book or the matched play guide
 	ScenarioName string `json:"scenarioName"`
 
+	// PointsLimit is the most points the army lists used in this round's games may cost, zero for no limit
+	PointsLimit int `json:"pointsLimit,omitempty"`
+
+	// Date is the date the round is expected to be played in YYYY-MM-DD format (ISO 8601), if it has been scheduled
+	Date string `json:"date,omitempty"`
+
 	// GameIDs is the slice of IDs for games scheduled/played in this round
 	GameIDs []gamesheader.GameID `json:"gameIDs,omitempty"`
 }
+
+// IsValid checks if the round instance has all required fields set and returns a boolean indicating validity. A
+// slice of strings is returned containing information about any invalid fields, one entry per field, and an error
+// is returned if validity cannot be determined, for example if the round instance is nil. Whether the league exists
+// and the number is unique within it cannot be checked from the round alone, those are left to the service.
+func (r *Round) IsValid() (bool, []string, error) {
+	if r == nil {
+		return false, []string{}, svcerrors.ErrModelMissing
+	}
+	return validityOf(r.ID, r.LeagueID, r.Number, r.PointsLimit, r.Date)
+}
+
+// IsValid checks the shallow round in the same way as Round.IsValid, so the same rules apply whichever form of the
+// round is being written
+func (r *ShallowRound) IsValid() (bool, []string, error) {
+	if r == nil {
+		return false, []string{}, svcerrors.ErrModelMissing
+	}
+	return validityOf(r.ID, r.LeagueID, r.Number, r.PointsLimit, r.Date)
+}
+
+// validityOf holds the checks shared by both forms of a round
+func validityOf(id pkg.RoundID, leagueID leagues.LeagueID, number, pointsLimit int, date string) (bool, []string, error) {
+	invalidFields := []string{}
+	if leagueID == "" {
+		invalidFields = append(invalidFields, "LeagueID is required")
+	}
+	if number < 1 {
+		invalidFields = append(invalidFields, fmt.Sprintf("Number=%d must be 1 or more", number))
+	}
+	if pointsLimit < 0 {
+		invalidFields = append(invalidFields, fmt.Sprintf("PointsLimit=%d must be 0 or more", pointsLimit))
+	}
+	if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+		invalidFields = append(invalidFields, "Date='"+date+"' is not in YYYY-MM-DD format")
+	}
+
+	if len(invalidFields) > 0 {
+		slog.Warn("Round is missing required fields or has invalid values", "roundID", id, "invalid", invalidFields)
+		return false, invalidFields, nil
+	}
+	return true, invalidFields, nil
+}
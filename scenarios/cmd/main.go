@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	"github.com/rpatton4/mesbg-league/scenarios/internal/catalog"
+	handlerhttp "github.com/rpatton4/mesbg-league/scenarios/internal/handler/http"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+var port = "8086"
+
+func main() {
+	logHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
+	slog.SetDefault(slog.New(logHandler))
+
+	slog.Info("Starting the Scenarios service on port " + port)
+	c, err := catalog.Default()
+	if err != nil {
+		slog.Error("Failed to load the scenario catalog", "error", err.Error())
+		panic(err)
+	}
+	handler := handlerhttp.NewHumaHandler(c)
+
+	router := http.NewServeMux()
+
+	api := humago.New(router, huma.DefaultConfig("Scenarios Service", "1.0.0"))
+
+	handlerhttp.RegisterRoutes(api, handler)
+
+	if err := http.ListenAndServe(":"+port, router); err != nil {
+		slog.Error("Failed to start HTTP server", "error", err.Error())
+		panic(err)
+	}
+}
@@ -0,0 +1,91 @@
+// Package catalog holds the scenarios known to the service. The scenarios come from the Matched Play Guide and
+// never change while the service is running, so they are seeded from a data file embedded in the binary rather than
+// kept in a repository.
+package catalog
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/scenarios/pkg"
+	"github.com/rpatton4/mesbg-league/scenarios/pkg/model"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// seed is the data file holding the scenarios of the Matched Play Guide, as a JSON array of model.Scenario
+//
+//go:embed scenarios.json
+var seed []byte
+
+// Catalog is the read only set of scenarios, safe for use by any number of goroutines
+type Catalog struct {
+	scenarios []model.Scenario
+	byID      map[pkg.ScenarioID]int
+}
+
+// defaultCatalog is loaded from the embedded seed the first time it is asked for
+var defaultCatalog = sync.OnceValues(func() (*Catalog, error) {
+	return Load(seed)
+})
+
+// Default returns the catalog of the scenarios in the Matched Play Guide, loaded from the embedded data file
+func Default() (*Catalog, error) {
+	return defaultCatalog()
+}
+
+// Load creates a catalog from a JSON array of scenarios, keeping them in the order given. Every scenario has to be
+// valid and have a unique ID, otherwise an error wrapping svcerrors.ErrModelInvalid is returned.
+func Load(data []byte) (*Catalog, error) {
+	var scenarios []model.Scenario
+	if err := json.Unmarshal(data, &scenarios); err != nil {
+		return nil, fmt.Errorf("unable to read the scenarios: %w", err)
+	}
+
+	c := &Catalog{scenarios: scenarios, byID: make(map[pkg.ScenarioID]int, len(scenarios))}
+	for i := range scenarios {
+		s := &scenarios[i]
+		if ok, invalid, err := s.IsValid(); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("scenario %d ('%s') is %w: %s", i+1, s.ID, svcerrors.ErrModelInvalid, strings.Join(invalid, ", "))
+		}
+		if _, dup := c.byID[s.ID]; dup {
+			return nil, fmt.Errorf("scenario %d is %w: the ID '%s' is used more than once", i+1, svcerrors.ErrModelInvalid, s.ID)
+		}
+		c.byID[s.ID] = i
+	}
+	return c, nil
+}
+
+// GetByID returns the scenario with the given id, or svcerrors.ErrNotFound if the catalog has no such scenario
+func (c *Catalog) GetByID(ctx context.Context, id pkg.ScenarioID) (*model.Scenario, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+
+	i, ok := c.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("no scenario with ID '%s'. Source: %w", id, svcerrors.ErrNotFound)
+	}
+	return clone(&c.scenarios[i]), nil
+}
+
+// List returns every scenario in the catalog, in the order they were loaded
+func (c *Catalog) List(ctx context.Context) (*model.ScenarioList, error) {
+	l := &model.ScenarioList{Scenarios: make([]model.Scenario, 0, len(c.scenarios))}
+	for i := range c.scenarios {
+		l.Scenarios = append(l.Scenarios, *clone(&c.scenarios[i]))
+	}
+	return l, nil
+}
+
+// clone copies the scenario so that callers cannot change the catalog through what it returns
+func clone(s *model.Scenario) *model.Scenario {
+	cp := *s
+	cp.VictoryPointSources = slices.Clone(s.VictoryPointSources)
+	return &cp
+}
@@ -0,0 +1,72 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"testing"
+)
+
+func TestDefaultCatalogLoads(t *testing.T) {
+	c, err := Default()
+	if err != nil {
+		t.Fatalf("Expected the embedded scenarios to load, got %v", err)
+	}
+
+	l, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error listing the scenarios, got %v", err)
+	}
+	if len(l.Scenarios) != 12 {
+		t.Errorf("Expected the 12 scenarios of the Matched Play Guide, got %d", len(l.Scenarios))
+	}
+	if l.Scenarios[0].ID != "domination" {
+		t.Errorf("Expected the scenarios in the order of the data file, got %s first", l.Scenarios[0].ID)
+	}
+}
+
+func TestCatalogGetByID(t *testing.T) {
+	c, err := Default()
+	if err != nil {
+		t.Fatalf("Expected the embedded scenarios to load, got %v", err)
+	}
+
+	s, err := c.GetByID(context.Background(), "hold-ground")
+	if err != nil {
+		t.Fatalf("Expected to find hold-ground, got %v", err)
+	}
+	if s.Name != "Hold Ground" || s.MaxVictoryPoints != 12 {
+		t.Errorf("Expected Hold Ground with a maximum of 12 VPs, got %+v", s)
+	}
+
+	// Changing what is returned must not change the catalog
+	s.VictoryPointSources[0].MaxVictoryPoints = 99
+	again, _ := c.GetByID(context.Background(), "hold-ground")
+	if again.VictoryPointSources[0].MaxVictoryPoints == 99 {
+		t.Errorf("Expected the catalog to be unchanged by a change to a returned scenario")
+	}
+
+	if _, err := c.GetByID(context.Background(), "no-such-scenario"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found for an unknown scenario, got %v", err)
+	}
+	if _, err := c.GetByID(context.Background(), ""); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected an invalid ID for an empty ID, got %v", err)
+	}
+}
+
+func TestLoadRejectsInvalidScenarios(t *testing.T) {
+	cases := map[string]string{
+		"max not the total": `[{"id":"a","name":"A","victoryPointSources":[{"id":"x","maxVictoryPoints":3}],"maxVictoryPoints":4}]`,
+		"no sources":        `[{"id":"a","name":"A","victoryPointSources":[],"maxVictoryPoints":0}]`,
+		"duplicate ID": `[{"id":"a","name":"A","victoryPointSources":[{"id":"x","maxVictoryPoints":3}],"maxVictoryPoints":3},
+			{"id":"a","name":"B","victoryPointSources":[{"id":"x","maxVictoryPoints":3}],"maxVictoryPoints":3}]`,
+	}
+
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load([]byte(data)); !errors.Is(err, svcerrors.ErrModelInvalid) {
+				t.Errorf("Expected the scenarios to be rejected as invalid, got %v", err)
+			}
+		})
+	}
+}
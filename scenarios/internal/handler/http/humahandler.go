@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/scenarios/internal/catalog"
+	scenarios "github.com/rpatton4/mesbg-league/scenarios/pkg"
+	"github.com/rpatton4/mesbg-league/scenarios/pkg/model"
+	"log/slog"
+)
+
+// HumaHandler defines the HTTP handler (adapter) for Scenario operations received via HTTP(S). The scenarios are
+// read only, so there are no operations to write them.
+type HumaHandler struct {
+	catalog *catalog.Catalog
+}
+
+// <editor-fold desc="I/O Struct Definitions">
+
+// Huma requires structs for both the input and output of each function registered as a handler for an HTTP operation.
+// See the games service HumaHandler for the conventions followed here.
+// Please keep the structs organized, with request and then response for any operation together
+
+// GetByIDRequest defines the input for the GetByID operation.
+type GetByIDRequest struct {
+	// ID is the unique identifier for the scenario to retrieve, taken from the path /scenarios/{id}
+	ID scenarios.ScenarioID `path:"id" example:"domination" doc:"The unique identifier for the scenario to retrieve"`
+}
+
+// GetByIDResponse defines the output for the GetByID operation.
+type GetByIDResponse struct {
+	// Body holds the scenario with the requested ID, Huma will marshall this to JSON for the HTTP response
+	Body model.Scenario
+}
+
+// ListResponse defines the output for the List operation, the list takes no input.
+type ListResponse struct {
+	// Body holds every scenario in the catalog
+	Body model.ScenarioList
+}
+
+//</editor-fold>
+
+// NewHumaHandler creates a new instance of the HTTP handler for scenario operations.
+func NewHumaHandler(c *catalog.Catalog) *HumaHandler {
+	return &HumaHandler{catalog: c}
+}
+
+// GetByID looks up the scenario with the ID taken from the path, returns it if found
+// 404 is returned if no such scenario exists
+func (h *HumaHandler) GetByID(ctx context.Context, req *GetByIDRequest) (*GetByIDResponse, error) {
+	slog.Debug("GetByID called", "scenarioID", req.ID)
+
+	s, err := h.catalog.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, scenarioError("get", req.ID, err)
+	}
+
+	return &GetByIDResponse{
+		Body: *s,
+	}, nil
+}
+
+// List returns every scenario in the catalog
+func (h *HumaHandler) List(ctx context.Context, _ *struct{}) (*ListResponse, error) {
+	slog.Debug("List called")
+
+	l, err := h.catalog.List(ctx)
+	if err != nil {
+		return nil, scenarioError("list", "", err)
+	}
+
+	return &ListResponse{
+		Body: *l,
+	}, nil
+}
+
+// scenarioError maps an error from the catalog to the HTTP response, so that every operation reports errors with
+// the same status codes
+func scenarioError(action string, id scenarios.ScenarioID, err error) error {
+	slog.Error("Unable to "+action+" the scenario", "scenarioID", id, "error", err)
+	if errors.Is(err, svcerrors.ErrNotFound) || errors.Is(err, svcerrors.ErrInvalidID) {
+		return huma.Error404NotFound("No such scenario exists")
+	}
+	return huma.Error500InternalServerError("error while trying to " + action + " the scenario: " + err.Error())
+}
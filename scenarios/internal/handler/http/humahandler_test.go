@@ -0,0 +1,58 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rpatton4/mesbg-league/scenarios/internal/catalog"
+	nethttp "net/http"
+	"testing"
+)
+
+func TestHumaHandlerGetByID(t *testing.T) {
+	handler := newTestHandler(t)
+
+	res, err := handler.GetByID(context.Background(), &GetByIDRequest{ID: "a"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Body.Name != "Alpha" {
+		t.Errorf("expected scenario Alpha, got %+v", res.Body)
+	}
+
+	_, err = handler.GetByID(context.Background(), &GetByIDRequest{ID: "zzz"})
+	assertStatus(t, err, nethttp.StatusNotFound)
+}
+
+func TestHumaHandlerList(t *testing.T) {
+	handler := newTestHandler(t)
+
+	res, err := handler.List(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(res.Body.Scenarios) != 2 || res.Body.Scenarios[1].ID != "b" {
+		t.Errorf("expected both scenarios in order, got %+v", res.Body.Scenarios)
+	}
+}
+
+func newTestHandler(t *testing.T) *HumaHandler {
+	c, err := catalog.Load([]byte(`[
+		{"id":"a","name":"Alpha","victoryPointSources":[{"id":"x","maxVictoryPoints":3}],"maxVictoryPoints":3},
+		{"id":"b","name":"Beta","victoryPointSources":[{"id":"x","maxVictoryPoints":5}],"maxVictoryPoints":5}
+	]`))
+	if err != nil {
+		t.Fatalf("unable to load the test scenarios: %v", err)
+	}
+	return NewHumaHandler(c)
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var statusError huma.StatusError
+	if err == nil {
+		t.Errorf("expected an error with status %d, got nil", status)
+	} else if !errors.As(err, &statusError) || statusError.GetStatus() != status {
+		t.Errorf("expected an error with status %d, got %v", status, err)
+	}
+}
@@ -0,0 +1,12 @@
+package http
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// RegisterRoutes registers every Scenarios operation of the handler with the given Huma API, so that the service and
+// anything embedding it (such as tests) expose exactly the same routes.
+func RegisterRoutes(api huma.API, handler *HumaHandler) {
+	huma.Get(api, "/scenarios", handler.List)
+	huma.Get(api, "/scenarios/{id}", handler.GetByID)
+}
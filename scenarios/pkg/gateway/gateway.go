@@ -0,0 +1,20 @@
+// Package gateway contains clients for interacting with the scenarios service from other services.
+// The package is meant to be public, and will commit to being backwards compatible within major versions.
+// The package primarily consists of the ScenariosGateway interface, with different implementations
+// of the interface for calling it in-memory or over HTTP.
+package gateway
+
+import (
+	"context"
+	scenarios "github.com/rpatton4/mesbg-league/scenarios/pkg"
+	"github.com/rpatton4/mesbg-league/scenarios/pkg/model"
+)
+
+// ScenariosGateway provides a set of methods for reading the scenario catalog from outside the service.
+type ScenariosGateway interface {
+	// GetByID returns the scenario with the given id, or a svcerrors.ErrNotFound if no scenario with that id exists
+	GetByID(ctx context.Context, id scenarios.ScenarioID) (*model.Scenario, error)
+
+	// List returns every scenario in the catalog, in the order they appear in the Matched Play Guide
+	List(ctx context.Context) (*model.ScenarioList, error)
+}
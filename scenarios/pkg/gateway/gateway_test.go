@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/scenarios/internal/catalog"
+	handlerhttp "github.com/rpatton4/mesbg-league/scenarios/internal/handler/http"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Both gateways have to satisfy the interface
+var _ ScenariosGateway = (*InProcessGateway)(nil)
+var _ ScenariosGateway = (*HTTPGateway)(nil)
+
+func TestInProcessGatewayContract(t *testing.T) {
+	runGatewayContract(t, func(t *testing.T) ScenariosGateway {
+		gw, err := NewDefaultInProcessGateway()
+		if err != nil {
+			t.Fatalf("unable to load the scenarios: %v", err)
+		}
+		return gw
+	})
+}
+
+func TestHTTPGatewayContract(t *testing.T) {
+	runGatewayContract(t, func(t *testing.T) ScenariosGateway {
+		srv := newTestScenariosServer(t)
+		return New(srv.URL, WithHTTPClient(srv.Client()))
+	})
+}
+
+// runGatewayContract runs the same scenarios against any ScenariosGateway, so that callers get identical results
+// and errors whichever implementation they are given
+func runGatewayContract(t *testing.T, newGateway func(t *testing.T) ScenariosGateway) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, gw ScenariosGateway)
+	}{
+		{"Get", testGatewayGet},
+		{"GetUnknown", testGatewayGetUnknown},
+		{"List", testGatewayList},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, newGateway(t))
+		})
+	}
+}
+
+func testGatewayGet(t *testing.T, gw ScenariosGateway) {
+	s, err := gw.GetByID(context.Background(), "domination")
+	if err != nil {
+		t.Fatalf("Expected to get the scenario, got %v", err)
+	}
+	if s.Name != "Domination" || s.MaxVictoryPoints != 15 || len(s.VictoryPointSources) != 3 {
+		t.Errorf("Expected Domination with 3 sources and a maximum of 15 VPs, got %+v", s)
+	}
+}
+
+func testGatewayGetUnknown(t *testing.T, gw ScenariosGateway) {
+	if _, err := gw.GetByID(context.Background(), "no-such-scenario"); !errors.Is(err, svcerrors.ErrNotFound) {
+		t.Errorf("Expected not found for an unknown scenario, got %v", err)
+	}
+	if _, err := gw.GetByID(context.Background(), ""); !errors.Is(err, svcerrors.ErrInvalidID) {
+		t.Errorf("Expected an invalid ID for an empty ID, got %v", err)
+	}
+}
+
+func testGatewayList(t *testing.T, gw ScenariosGateway) {
+	l, err := gw.List(context.Background())
+	if err != nil {
+		t.Fatalf("Expected to list the scenarios, got %v", err)
+	}
+	if len(l.Scenarios) != 12 {
+		t.Errorf("Expected 12 scenarios, got %d", len(l.Scenarios))
+	}
+}
+
+// newTestScenariosServer starts the Scenarios service routes over the default catalog, closed when the test ends
+func newTestScenariosServer(t *testing.T) *httptest.Server {
+	c, err := catalog.Default()
+	if err != nil {
+		t.Fatalf("unable to load the scenarios: %v", err)
+	}
+
+	router := http.NewServeMux()
+	api := humago.New(router, huma.DefaultConfig("Scenarios Service", "1.0.0"))
+	handlerhttp.RegisterRoutes(api, handlerhttp.NewHumaHandler(c))
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
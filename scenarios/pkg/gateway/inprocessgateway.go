@@ -0,0 +1,35 @@
+package gateway
+
+import (
+	"context"
+	"github.com/rpatton4/mesbg-league/scenarios/internal/catalog"
+	scenarios "github.com/rpatton4/mesbg-league/scenarios/pkg"
+	"github.com/rpatton4/mesbg-league/scenarios/pkg/model"
+)
+
+type InProcessGateway struct {
+	catalog *catalog.Catalog
+}
+
+// NewInProcessGatewayWithCatalog creates a new InProcessGateway reading the provided catalog.
+// This is intended primarily for use while testing, to provide a catalog of test scenarios.
+func NewInProcessGatewayWithCatalog(c *catalog.Catalog) *InProcessGateway {
+	return &InProcessGateway{c}
+}
+
+// NewDefaultInProcessGateway creates a new InProcessGateway reading the scenarios of the Matched Play Guide, an error
+// is only returned if the embedded data file of the scenarios cannot be loaded
+func NewDefaultInProcessGateway() (*InProcessGateway, error) {
+	c, err := catalog.Default()
+	if err != nil {
+		return nil, err
+	}
+	return NewInProcessGatewayWithCatalog(c), nil
+}
+
+func (ipg *InProcessGateway) GetByID(ctx context.Context, id scenarios.ScenarioID) (*model.Scenario, error) {
+	return ipg.catalog.GetByID(ctx, id)
+}
+func (ipg *InProcessGateway) List(ctx context.Context) (*model.ScenarioList, error) {
+	return ipg.catalog.List(ctx)
+}
@@ -0,0 +1,174 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	scenarios "github.com/rpatton4/mesbg-league/scenarios/pkg"
+	"github.com/rpatton4/mesbg-league/scenarios/pkg/model"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is how long a single attempt at a call may take before it is abandoned
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultRetries is how many times a call is retried after a failed first attempt
+	DefaultRetries = 2
+
+	// DefaultBackoff is the wait before the first retry, doubling for each retry after that
+	DefaultBackoff = 100 * time.Millisecond
+)
+
+// HTTPGateway is the ScenariosGateway implementation for calling the Scenarios service over HTTP(S). Errors returned
+// by the service are mapped back to the same svcerrors values the InProcessGateway returns, so callers can use
+// errors.Is without caring which gateway they have.
+type HTTPGateway struct {
+	addr    string
+	client  *http.Client
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+// Option configures an HTTPGateway when it is created
+type Option func(*HTTPGateway)
+
+// WithHTTPClient sets the client used to make the calls, http.DefaultClient is used otherwise
+func WithHTTPClient(c *http.Client) Option {
+	return func(g *HTTPGateway) {
+		g.client = c
+	}
+}
+
+// WithTimeout sets how long a single attempt at a call may take, zero means no timeout beyond the caller's context
+func WithTimeout(d time.Duration) Option {
+	return func(g *HTTPGateway) {
+		g.timeout = d
+	}
+}
+
+// WithRetries sets how many times calls are retried after a network error or a response indicating the service is
+// temporarily unavailable, and the wait before the first retry. Every call only reads, so all of them are retried.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(g *HTTPGateway) {
+		g.retries = retries
+		g.backoff = backoff
+	}
+}
+
+// New creates an HTTPGateway for the Scenarios service at the given base address, e.g. "http://localhost:8086"
+func New(addr string, opts ...Option) *HTTPGateway {
+	g := &HTTPGateway{
+		addr:    strings.TrimSuffix(addr, "/"),
+		client:  http.DefaultClient,
+		timeout: DefaultTimeout,
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// GetByID returns the scenario with the given id, or a svcerrors.ErrNotFound if no scenario with that id exists
+func (g *HTTPGateway) GetByID(ctx context.Context, id scenarios.ScenarioID) (*model.Scenario, error) {
+	if id == "" {
+		return nil, svcerrors.ErrInvalidID
+	}
+
+	var s model.Scenario
+	if err := g.get(ctx, "/scenarios/"+url.PathEscape(string(id)), &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List returns every scenario in the catalog, in the order they appear in the Matched Play Guide
+func (g *HTTPGateway) List(ctx context.Context) (*model.ScenarioList, error) {
+	var l model.ScenarioList
+	if err := g.get(ctx, "/scenarios", &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// get makes the call to the service, retrying calls which fail in a way that may succeed on another try. The
+// response body is decoded into out.
+func (g *HTTPGateway) get(ctx context.Context, path string, out any) error {
+	var lastErr error
+	for attempt := 0; attempt <= g.retries; attempt++ {
+		if attempt > 0 {
+			wait := g.backoff << (attempt - 1)
+			slog.Debug("Retrying call to the scenarios service", "path", path, "attempt", attempt+1, "wait", wait, "error", lastErr)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		retry, err := g.attempt(ctx, path, out)
+		if err == nil || !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// attempt makes a single call to the service, reporting whether a failure is worth retrying
+func (g *HTTPGateway) attempt(ctx context.Context, path string, out any) (bool, error) {
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.addr+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		// The caller giving up is final, anything else at the network level may be temporary
+		return ctx.Err() == nil || errors.Is(ctx.Err(), context.DeadlineExceeded), err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout
+		return retry, errorFromResponse(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode the scenarios service response: %w", err)
+	}
+	return false, nil
+}
+
+// errorFromResponse turns an error response from the service back into the svcerrors value which caused it
+func errorFromResponse(resp *http.Response) error {
+	var problem huma.ErrorModel
+	b, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(b, &problem); err != nil || problem.Detail == "" {
+		problem.Detail = strings.TrimSpace(string(b))
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("scenarios service responded %d: %s. Source: %w", resp.StatusCode, problem.Detail, svcerrors.ErrNotFound)
+	}
+	return fmt.Errorf("scenarios service responded %d: %s", resp.StatusCode, problem.Detail)
+}
@@ -0,0 +1,32 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPGatewayRetriesCalls(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"domination","name":"Domination","maxVictoryPoints":15}`))
+	}))
+	defer srv.Close()
+
+	gw := New(srv.URL, WithHTTPClient(srv.Client()), WithRetries(2, time.Millisecond))
+	s, err := gw.GetByID(context.Background(), "domination")
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after retrying, got %v", err)
+	}
+	if s.ID != "domination" || calls.Load() != 3 {
+		t.Errorf("Expected domination after 3 calls, got %s after %d calls", s.ID, calls.Load())
+	}
+}
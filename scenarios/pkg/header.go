@@ -0,0 +1,3 @@
+package pkg
+
+type ScenarioID string
@@ -0,0 +1,98 @@
+// Package model contains the models for the scenarios service, most notably the Scenario struct describing one of
+// the scenarios from the Matched Play Guide
+package model
+
+import (
+	"fmt"
+	"github.com/rpatton4/mesbg-league/pkg/svcerrors"
+	"github.com/rpatton4/mesbg-league/scenarios/pkg"
+	"log/slog"
+)
+
+// Scenario describes one of the scenarios from the Matched Play Guide, with how victory points are scored in it
+type Scenario struct {
+	// ID is the unique identifier for the scenario, a short readable name such as "domination"
+	ID pkg.ScenarioID `json:"id" example:"domination" doc:"The unique identifier for the scenario"`
+
+	// Name is the name of the scenario as printed in the Matched Play Guide
+	Name string `json:"name" example:"Domination" doc:"The name of the scenario as printed in the Matched Play Guide"`
+
+	// Objective describes what the players are trying to achieve in the scenario
+	Objective string `json:"objective" doc:"What the players are trying to achieve in the scenario"`
+
+	// VictoryPointSources lists every way victory points can be scored in the scenario
+	VictoryPointSources []VictoryPointSource `json:"victoryPointSources" doc:"Every way victory points can be scored in the scenario"`
+
+	// MaxVictoryPoints is the most victory points one side can score in the scenario, the total of its sources
+	MaxVictoryPoints int `json:"maxVictoryPoints" example:"15" doc:"The most victory points one side can score in the scenario"`
+}
+
+// VictoryPointSource is one way of scoring victory points in a scenario
+type VictoryPointSource struct {
+	// ID identifies the source within its scenario
+	ID string `json:"id" example:"objectives" doc:"Identifies the source within its scenario"`
+
+	// Description says how the victory points are scored
+	Description string `json:"description" example:"2 VPs for each objective controlled at the end of the game" doc:"How the victory points are scored"`
+
+	// MaxVictoryPoints is the most victory points one side can score from this source
+	MaxVictoryPoints int `json:"maxVictoryPoints" example:"10" doc:"The most victory points one side can score from this source"`
+}
+
+// ScenarioList holds every scenario in the catalog
+type ScenarioList struct {
+	// Scenarios holds the scenarios in the order they appear in the Matched Play Guide
+	Scenarios []Scenario `json:"scenarios" doc:"The scenarios in the order they appear in the Matched Play Guide"`
+}
+
+// IsValid checks if the scenario instance has all required fields set and returns a boolean indicating validity. A
+// slice of strings is returned containing information about any invalid fields, one entry per field, and an error
+// is returned if validity cannot be determined, for example if the scenario instance is nil.
+func (s *Scenario) IsValid() (bool, []string, error) {
+	if s == nil {
+		return false, []string{}, svcerrors.ErrModelMissing
+	}
+
+	invalidFields := []string{}
+	if s.ID == "" {
+		invalidFields = append(invalidFields, "ID is required")
+	}
+	if s.Name == "" {
+		invalidFields = append(invalidFields, "Name is required")
+	}
+	if len(s.VictoryPointSources) == 0 {
+		invalidFields = append(invalidFields, "VictoryPointSources must have at least one source")
+	}
+
+	total := 0
+	seen := map[string]bool{}
+	for _, src := range s.VictoryPointSources {
+		if src.ID == "" || seen[src.ID] {
+			invalidFields = append(invalidFields, "VictoryPointSources ID='"+src.ID+"' must be set and unique")
+		}
+		if src.MaxVictoryPoints < 1 {
+			invalidFields = append(invalidFields, fmt.Sprintf("VictoryPointSources ID='%s' MaxVictoryPoints=%d must be 1 or more", src.ID, src.MaxVictoryPoints))
+		}
+		seen[src.ID] = true
+		total += src.MaxVictoryPoints
+	}
+	if s.MaxVictoryPoints != total {
+		invalidFields = append(invalidFields, fmt.Sprintf("MaxVictoryPoints=%d must be the total of the sources, %d", s.MaxVictoryPoints, total))
+	}
+
+	if len(invalidFields) > 0 {
+		slog.Warn("Scenario is missing required fields or has invalid values", "scenarioID", s.ID, "invalid", invalidFields)
+		return false, invalidFields, nil
+	}
+	return true, invalidFields, nil
+}
+
+// CheckVictoryPoints returns a svcerrors.ErrModelInvalid if the given number of victory points could not have been
+// scored by one side in the scenario
+func (s *Scenario) CheckVictoryPoints(vp int) error {
+	if vp < 0 || vp > s.MaxVictoryPoints {
+		return fmt.Errorf("%w: %d victory points cannot be scored in %s, which allows 0 to %d",
+			svcerrors.ErrModelInvalid, vp, s.Name, s.MaxVictoryPoints)
+	}
+	return nil
+}